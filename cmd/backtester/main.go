@@ -1,9 +1,11 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
@@ -13,6 +15,7 @@ import (
 	"github.com/ridopark/JonBuhTrader/pkg/backtester"
 	"github.com/ridopark/JonBuhTrader/pkg/feed"
 	"github.com/ridopark/JonBuhTrader/pkg/logging"
+	"github.com/ridopark/JonBuhTrader/pkg/reporting"
 	"github.com/ridopark/JonBuhTrader/pkg/strategy"
 	"github.com/ridopark/JonBuhTrader/pkg/strategy/examples"
 )
@@ -29,6 +32,34 @@ func main() {
 		endDate        = flag.String("end", "2024-12-31", "End date (YYYY-MM-DD)")
 		initialCapital = flag.Float64("capital", 10000.0, "Initial capital")
 		timeframe      = flag.String("timeframe", "1m", "Timeframe (1m, 5m, 15m, 1h, 1d)")
+		baseCurrency   = flag.String("base-currency", backtester.DefaultBaseCurrency, "Base currency for capital and results (e.g., USD)")
+		continuousFlag = flag.String("continuous", "", "Futures roots to trade as continuous contracts (comma-separated, e.g., ES,NQ)")
+		rollMethod     = flag.String("roll-method", string(feed.RollByCalendar), "Continuous contract roll method (calendar, volume)")
+		rollDays       = flag.Int("roll-days", 5, "Days before expiration to roll when using calendar rolls")
+		rollAdjustment = flag.String("roll-adjust", string(feed.AdjustDifference), "Continuous contract back-adjustment (none, difference, ratio)")
+		sessionTZ      = flag.String("session-tz", "UTC", "Time zone used for daily session boundaries (e.g., America/New_York)")
+		maxLookback    = flag.Int("max-lookback", backtester.DefaultMaxLookback, "Number of bars of history kept per symbol for strategies and indicators")
+		maxDailyLoss   = flag.Float64("max-daily-loss", 0, "Halt trading when the session loss reaches this amount in the base currency (0 = disabled)")
+		maxDrawdown    = flag.Float64("max-drawdown", 0, "Halt trading when drawdown from peak reaches this percentage (0 = disabled)")
+		maxLosses      = flag.Int("max-consecutive-losses", 0, "Halt trading after this many consecutive losing trades (0 = disabled)")
+		maxDailyTrades = flag.Int("max-trades-per-day", 0, "Halt trading after this many trades in a session (0 = disabled)")
+		breakerAction  = flag.String("breaker-action", string(backtester.CircuitBreakerFreeze), "Circuit breaker action (freeze, flatten)")
+		breakerScope   = flag.String("breaker-scope", string(backtester.CircuitBreakerSession), "How long a circuit breaker halts trading (session, run)")
+		exportFlag     = flag.String("export", "", "Result formats to export (comma-separated: json, csv, parquet; empty = none)")
+		outputDir      = flag.String("output-dir", "results", "Directory in which each exported run gets its own subdirectory")
+		riskFreeRate   = flag.Float64("risk-free-rate", 0, "Annual risk-free rate in percent for Sharpe, Sortino and alpha")
+		periodsPerYear = flag.Int("periods-per-year", 0, "Daily periods per year for annualized metrics (0 = 365 for crypto-only runs, 252 otherwise)")
+		sharpeTrials   = flag.Int("trials", 1, "Strategy configurations tried before this run, for the deflated Sharpe ratio")
+		trialVariance  = flag.Float64("trial-sharpe-variance", 0, "Variance of the daily Sharpe ratios across the -trials configurations (0 = this run's estimate variance)")
+		topDrawdowns   = flag.Int("top-drawdowns", backtester.DefaultTopDrawdowns, "Number of deepest drawdown periods to report")
+		rollingFlag    = flag.String("rolling-windows", "63", "Rolling metric windows in trading days (comma-separated, e.g., 21,63,252)")
+		benchmarkFlag  = flag.String("benchmark", "", "Benchmark to compare results with: a symbol (e.g., SPY) or \"universe\" for buy-and-hold of the traded symbols")
+		monteCarlo     = flag.Int("monte-carlo", 0, "Monte Carlo paths per method for robustness analysis (0 = disabled)")
+		mcMethods      = flag.String("mc-methods", "reshuffle,bootstrap,skip", "Monte Carlo methods (comma-separated: reshuffle, bootstrap, skip)")
+		mcSkip         = flag.Float64("mc-skip", backtester.DefaultSkipProbability*100, "Percentage of round trips dropped in each skip path")
+		ruinDrawdown   = flag.Float64("ruin-drawdown", backtester.DefaultRuinDrawdown*100, "Drawdown percentage counted as ruin in Monte Carlo paths")
+		mcSeed         = flag.Uint64("mc-seed", 0, "Monte Carlo random seed (0 = time-based)")
+		htmlReport     = flag.Bool("report", false, "Write a self-contained HTML tear sheet to the run's output directory")
 	)
 	flag.Parse()
 
@@ -73,6 +104,26 @@ func main() {
 	}
 	end = end.Add(24 * time.Hour) // Add one day to include all data for the end date
 
+	// Parse export formats before running so a typo does not waste a backtest
+	exportFormats, err := backtester.ParseExportFormats(*exportFlag)
+	if err != nil {
+		logger.Fatal().Err(err).Str("export", *exportFlag).Msg("Invalid export formats")
+	}
+
+	monteCarloMethods, err := backtester.ParseMonteCarloMethods(*mcMethods)
+	if err != nil {
+		logger.Fatal().Err(err).Str("mc_methods", *mcMethods).Msg("Invalid Monte Carlo methods")
+	}
+
+	circuitBreakerAction, err := backtester.ParseCircuitBreakerAction(*breakerAction)
+	if err != nil {
+		logger.Fatal().Err(err).Str("breaker_action", *breakerAction).Msg("Invalid circuit breaker action")
+	}
+	circuitBreakerScope, err := backtester.ParseCircuitBreakerScope(*breakerScope)
+	if err != nil {
+		logger.Fatal().Err(err).Str("breaker_scope", *breakerScope).Msg("Invalid circuit breaker scope")
+	}
+
 	// Parse symbols from comma-delimited string
 	symbolsInput := strings.TrimSpace(*symbolsFlag)
 	symbols := strings.Split(symbolsInput, ",")
@@ -108,8 +159,43 @@ func main() {
 	}
 	defer provider.Close()
 
+	// Serve configured futures roots as continuous contracts
+	var historicalProvider feed.HistoricalDataProvider = provider
+	continuousInstruments := make(map[string]strategy.Instrument)
+	if roots := strings.TrimSpace(*continuousFlag); roots != "" {
+		continuousProvider := feed.NewContinuousContractProvider(provider, provider)
+		for _, root := range strings.Split(roots, ",") {
+			root = strings.TrimSpace(root)
+			contracts, err := provider.GetFuturesContracts(root)
+			if err != nil || len(contracts) == 0 {
+				logger.Fatal().Err(err).Str("root", root).Msg("No futures contracts found for continuous contract")
+			}
+
+			continuousProvider.AddContinuousContract(feed.ContinuousContractConfig{
+				Root:                 root,
+				RollMethod:           feed.RollMethod(*rollMethod),
+				RollDaysBeforeExpiry: *rollDays,
+				Adjustment:           feed.AdjustmentMethod(*rollAdjustment),
+			})
+
+			// The continuous series trades with the contract specification but never expires
+			instrument := contracts[0]
+			instrument.Symbol = root
+			instrument.Expiration = time.Time{}
+			continuousInstruments[root] = instrument
+
+			logger.Info().
+				Str("root", root).
+				Int("contracts", len(contracts)).
+				Str("roll_method", *rollMethod).
+				Str("adjustment", *rollAdjustment).
+				Msg("Configured continuous contract")
+		}
+		historicalProvider = continuousProvider
+	}
+
 	// Create data feed
-	dataFeed := feed.NewHistoricalFeed(provider, symbols, *timeframe, start, end)
+	dataFeed := feed.NewHistoricalFeed(historicalProvider, symbols, *timeframe, start, end)
 
 	// Create strategy
 	var strategyInstance strategy.Strategy
@@ -154,6 +240,97 @@ func main() {
 		Msg("Running backtest")
 
 	engine := backtester.NewEngineWithConfig(strategyInstance, dataFeed, *initialCapital, commissionType, commissionRate, slippageRate, maxSlippage)
+	engine.SetBaseCurrency(*baseCurrency)
+
+	sessionLocation, err := time.LoadLocation(*sessionTZ)
+	if err != nil {
+		logger.Fatal().Err(err).Str("session_tz", *sessionTZ).Msg("Invalid session time zone")
+	}
+	engine.SetSessionLocation(sessionLocation)
+	engine.SetMaxLookback(*maxLookback)
+	engine.SetRiskFreeRate(*riskFreeRate / 100)
+	engine.SetPeriodsPerYear(*periodsPerYear)
+	engine.SetTopDrawdowns(*topDrawdowns)
+	engine.SetSharpeTrials(*sharpeTrials)
+	engine.SetSharpeTrialVariance(*trialVariance)
+
+	var rollingWindows []int
+	for _, value := range strings.Split(*rollingFlag, ",") {
+		if value = strings.TrimSpace(value); value == "" {
+			continue
+		}
+		window, err := strconv.Atoi(value)
+		if err != nil {
+			logger.Fatal().Err(err).Str("rolling_windows", *rollingFlag).Msg("Invalid rolling window")
+		}
+		rollingWindows = append(rollingWindows, window)
+	}
+	if err := engine.SetRollingWindows(rollingWindows); err != nil {
+		logger.Fatal().Err(err).Str("rolling_windows", *rollingFlag).Msg("Invalid rolling windows")
+	}
+
+	engine.SetRiskLimits(backtester.RiskLimits{
+		MaxDailyLoss:         *maxDailyLoss,
+		MaxDrawdown:          *maxDrawdown / 100,
+		MaxConsecutiveLosses: *maxLosses,
+		MaxTradesPerDay:      *maxDailyTrades,
+		Action:               circuitBreakerAction,
+		Scope:                circuitBreakerScope,
+	})
+
+	// Load instrument currencies and the FX rates needed to convert them into the base currency
+	instruments := make([]strategy.Instrument, 0, len(symbols))
+	fxRates := backtester.NewFXRateTable(*baseCurrency)
+	loadedCurrencies := make(map[string]bool)
+	for _, symbol := range symbols {
+		instrument, err := provider.GetInstrument(symbol)
+		if continuous, exists := continuousInstruments[symbol]; exists {
+			instrument, err = &continuous, nil
+		}
+		if errors.Is(err, data.ErrInstrumentNotFound) {
+			logger.Warn().Err(err).Str("symbol", symbol).Msg("No instrument metadata, assuming base currency")
+			continue
+		}
+		if err != nil {
+			logger.Fatal().Err(err).Str("symbol", symbol).Msg("Failed to load instrument metadata (older databases need docker/db-manager.sh migrate)")
+		}
+		instruments = append(instruments, *instrument)
+
+		currency := instrument.Currency
+		if currency == *baseCurrency || loadedCurrencies[currency] {
+			continue
+		}
+
+		// Start a week early so the first bars have an as-of rate
+		rates, err := provider.GetFXRates(currency, *baseCurrency, start.AddDate(0, 0, -7), end)
+		if err != nil {
+			logger.Fatal().Err(err).Str("currency", currency).Msg("Failed to load FX rates")
+		}
+		if err := fxRates.AddRates(currency, rates); err != nil {
+			logger.Fatal().Err(err).Str("currency", currency).Msg("Invalid FX rates")
+		}
+		loadedCurrencies[currency] = true
+
+		logger.Info().
+			Str("currency", currency).
+			Str("base_currency", *baseCurrency).
+			Int("rates", len(rates)).
+			Msg("Loaded FX rates")
+	}
+	engine.SetInstruments(instruments)
+	engine.SetFXRates(fxRates)
+
+	if benchmark := strings.TrimSpace(*benchmarkFlag); benchmark != "" {
+		var benchmarkBars []strategy.BarData
+		if benchmark != backtester.BenchmarkUniverse {
+			benchmarkBars, err = historicalProvider.GetBars(benchmark, *timeframe, start, end)
+			if err != nil || len(benchmarkBars) == 0 {
+				logger.Fatal().Err(err).Str("benchmark", benchmark).Msg("Failed to load benchmark bars")
+			}
+		}
+		engine.SetBenchmark(benchmark, benchmarkBars)
+		logger.Info().Str("benchmark", benchmark).Int("bars", len(benchmarkBars)).Msg("Configured benchmark")
+	}
 
 	err = engine.Run()
 	if err != nil {
@@ -166,11 +343,54 @@ func main() {
 	// Calculate detailed metrics
 	results.CalculateMetrics()
 
+	if *monteCarlo > 0 {
+		skipProbability := *mcSkip / 100
+		analysis, err := backtester.RunMonteCarlo(results, backtester.MonteCarloConfig{
+			Simulations:     *monteCarlo,
+			Methods:         monteCarloMethods,
+			SkipProbability: &skipProbability,
+			RuinDrawdown:    *ruinDrawdown / 100,
+			Seed:            *mcSeed,
+		})
+		if err != nil {
+			logger.Warn().Err(err).Msg("Monte Carlo analysis skipped")
+		} else {
+			for _, skipped := range analysis.Skipped {
+				logger.Warn().Str("method", string(skipped.Method)).Str("reason", skipped.Reason).Msg("Monte Carlo method skipped")
+			}
+			results.MonteCarlo = analysis
+		}
+	}
+
 	// Print results
 	logger.Info().Msg("\n" + results.Summary())
 
 	// Optionally save results to file
-	// TODO: Add JSON export functionality
+	if len(exportFormats) > 0 || *htmlReport {
+		settings := make(map[string]string)
+		flag.VisitAll(func(f *flag.Flag) {
+			settings[f.Name] = f.Value.String()
+		})
+
+		config := backtester.RunConfig{
+			Strategy:   *strategyFlag,
+			Parameters: strategyInstance.GetParameters(),
+			Settings:   settings,
+		}
+		runDir, err := backtester.Export(*outputDir, config, results, exportFormats)
+		if err != nil {
+			logger.Fatal().Err(err).Str("output_dir", *outputDir).Msg("Failed to export results")
+		}
+		logger.Info().Str("dir", runDir).Str("formats", *exportFlag).Msg("Exported results")
+
+		if *htmlReport {
+			reportPath := filepath.Join(runDir, "report.html")
+			if err := reporting.WriteHTML(reportPath, results); err != nil {
+				logger.Fatal().Err(err).Str("path", reportPath).Msg("Failed to write HTML report")
+			}
+			logger.Info().Str("path", reportPath).Msg("Wrote HTML report")
+		}
+	}
 }
 
 // Helper function to get environment variable with default
@@ -38,6 +38,14 @@ func (m *mockContext) GetPosition(symbol string) *strategy.Position {
 	return m.positions[symbol]
 }
 
+func (m *mockContext) GetInstrument(symbol string) *strategy.Instrument {
+	return nil
+}
+
+func (m *mockContext) GetDailySnapshots() []strategy.DailySnapshot {
+	return nil
+}
+
 func (m *mockContext) SMA(symbol string, period int) (float64, error) {
 	return 0.0, nil // Mock implementation
 }
@@ -66,6 +74,122 @@ func (m *mockContext) ParbolicSAR(symbol string, step, max float64) (float64, er
 	return 0.0, nil // Mock implementation
 }
 
+func (m *mockContext) ATR(symbol string, period int) (float64, error) {
+	return 0.0, nil // Mock implementation
+}
+
+func (m *mockContext) StdDev(symbol string, period int) (float64, error) {
+	return 0.0, nil // Mock implementation
+}
+
+func (m *mockContext) BollingerBands(symbol string, period int, multiplier float64) (float64, float64, float64, float64, float64, error) {
+	return 0.0, 0.0, 0.0, 0.0, 0.0, nil // Mock implementation
+}
+
+func (m *mockContext) KeltnerChannels(symbol string, emaPeriod, atrPeriod int, multiplier float64) (float64, float64, float64, error) {
+	return 0.0, 0.0, 0.0, nil // Mock implementation
+}
+
+func (m *mockContext) DonchianChannels(symbol string, period int) (float64, float64, float64, error) {
+	return 0.0, 0.0, 0.0, nil // Mock implementation
+}
+
+func (m *mockContext) VWAP(symbol string) (float64, error) {
+	return 0.0, nil // Mock implementation
+}
+
+func (m *mockContext) RollingVWAP(symbol string, period int) (float64, error) {
+	return 0.0, nil // Mock implementation
+}
+
+func (m *mockContext) OBV(symbol string) (float64, error) {
+	return 0.0, nil // Mock implementation
+}
+
+func (m *mockContext) MFI(symbol string, period int) (float64, error) {
+	return 0.0, nil // Mock implementation
+}
+
+func (m *mockContext) AccumulationDistribution(symbol string) (float64, error) {
+	return 0.0, nil // Mock implementation
+}
+
+func (m *mockContext) ChaikinMoneyFlow(symbol string, period int) (float64, error) {
+	return 0.0, nil // Mock implementation
+}
+
+func (m *mockContext) VolumeProfile(symbol string, period, bins int, valueArea float64) (float64, float64, float64, error) {
+	return 0.0, 0.0, 0.0, nil // Mock implementation
+}
+
+func (m *mockContext) Stochastic(symbol string, kPeriod, kSmoothing, dPeriod int) (float64, float64, error) {
+	return 0.0, 0.0, nil // Mock implementation
+}
+
+func (m *mockContext) StochasticRSI(symbol string, rsiPeriod, stochPeriod, kSmoothing, dPeriod int) (float64, float64, error) {
+	return 0.0, 0.0, nil // Mock implementation
+}
+
+func (m *mockContext) WilliamsR(symbol string, period int) (float64, error) {
+	return 0.0, nil // Mock implementation
+}
+
+func (m *mockContext) CCI(symbol string, period int) (float64, error) {
+	return 0.0, nil // Mock implementation
+}
+
+func (m *mockContext) ROC(symbol string, period int) (float64, error) {
+	return 0.0, nil // Mock implementation
+}
+
+func (m *mockContext) Aroon(symbol string, period int) (float64, float64, float64, error) {
+	return 0.0, 0.0, 0.0, nil // Mock implementation
+}
+
+func (m *mockContext) Ichimoku(symbol string, tenkanPeriod, kijunPeriod, senkouBPeriod, displacement int) (float64, float64, float64, float64, float64, error) {
+	return 0.0, 0.0, 0.0, 0.0, 0.0, nil // Mock implementation
+}
+
+func (m *mockContext) Correlation(symbol, other string, period int) (float64, error) {
+	return 0.0, nil // Mock implementation
+}
+
+func (m *mockContext) Beta(symbol, benchmark string, period int) (float64, error) {
+	return 0.0, nil // Mock implementation
+}
+
+func (m *mockContext) HedgeRatio(symbol, other string, period int) (float64, float64, error) {
+	return 0.0, 0.0, nil // Mock implementation
+}
+
+func (m *mockContext) SpreadZScore(symbol, other string, period int) (float64, error) {
+	return 0.0, nil // Mock implementation
+}
+
+func (m *mockContext) Cointegration(symbol, other string, period int) (float64, error) {
+	return 0.0, nil // Mock implementation
+}
+
+func (m *mockContext) CrossSectionalRank(symbol string, spec strategy.IndicatorSpec) (int, float64, error) {
+	return 0, 0.0, nil // Mock implementation
+}
+
+func (m *mockContext) CandlestickPatterns(symbol string) ([]strategy.PatternSignal, error) {
+	return nil, nil // Mock implementation
+}
+
+func (m *mockContext) GetBars(symbol string, count int) ([]strategy.BarData, error) {
+	return nil, nil // Mock implementation
+}
+
+func (m *mockContext) Indicator(symbol string, spec strategy.IndicatorSpec) (float64, error) {
+	return 0.0, nil // Mock implementation
+}
+
+func (m *mockContext) IndicatorHistory(symbol string, spec strategy.IndicatorSpec, count int) ([]float64, error) {
+	return nil, nil // Mock implementation
+}
+
 func (m *mockContext) Log(level string, message string, data map[string]interface{}) {
 	logEntry := map[string]interface{}{
 		"level":   level,
@@ -89,11 +213,11 @@ func main() {
 	fmt.Println("===========================================================")
 
 	// Create strategy
-	strategy := examples.NewSupportResistanceStrategy()
+	srStrategy := examples.NewSupportResistanceStrategy()
 
 	// Set multiple symbols to test allocation
 	symbols := []string{"AAPL", "MSFT", "GOOGL"}
-	strategy.SetSymbols(symbols)
+	srStrategy.SetSymbols(symbols)
 
 	// Create mock context with initial cash
 	ctx := &mockContext{
@@ -102,7 +226,7 @@ func main() {
 	}
 
 	// Initialize strategy
-	err := strategy.Initialize(ctx)
+	err := srStrategy.Initialize(ctx)
 	if err != nil {
 		log.Fatalf("Failed to initialize strategy: %v", err)
 	}
@@ -139,7 +263,7 @@ func main() {
 			},
 		}
 
-		orders, err := strategy.OnDataPoint(ctx, dataPoint)
+		orders, err := srStrategy.OnDataPoint(ctx, dataPoint)
 		if err != nil {
 			log.Fatalf("Strategy failed: %v", err)
 		}
@@ -181,7 +305,7 @@ func main() {
 		},
 	}
 
-	orders, err := strategy.OnDataPoint(ctx, testDataPoint)
+	orders, err := srStrategy.OnDataPoint(ctx, testDataPoint)
 	if err != nil {
 		log.Fatalf("Strategy failed: %v", err)
 	}
@@ -243,9 +367,9 @@ func main() {
 	}
 
 	// Add TSLA to symbols for this test
-	strategy.SetSymbols(append(symbols, "TSLA"))
+	srStrategy.SetSymbols(append(symbols, "TSLA"))
 
-	orders2, err := strategy.OnDataPoint(ctx, testDataPoint2)
+	orders2, err := srStrategy.OnDataPoint(ctx, testDataPoint2)
 	if err != nil {
 		log.Fatalf("Strategy failed on second test: %v", err)
 	}
@@ -100,6 +100,17 @@ backup_db() {
     print_success "Backup created: $DOCKER_DIR/$BACKUP_FILE"
 }
 
+# Apply schema migrations to an existing database
+migrate_db() {
+    print_info "Applying migrations..."
+    source "$DOCKER_DIR/.env"
+    for migration in "$DOCKER_DIR"/migrations/*.sql; do
+        print_info "Applying $(basename "$migration")"
+        docker exec -i jonbuh_timescaledb psql -v ON_ERROR_STOP=1 -U "$POSTGRES_USER" -d "$POSTGRES_DB" < "$migration" || exit 1
+    done
+    print_success "Migrations applied"
+}
+
 # Reset database (WARNING: This will delete all data!)
 reset_db() {
     print_warning "This will completely reset the database and delete ALL data!"
@@ -133,6 +144,7 @@ Commands:
     logs        Show logs (optionally specify service: timescaledb or pgadmin)
     connect     Connect to the database via psql
     backup      Create a database backup
+    migrate     Apply docker/migrations to an existing database
     reset       Reset the database (WARNING: Deletes all data!)
     help        Show this help message
 
@@ -170,6 +182,9 @@ case "${1:-}" in
     "backup")
         backup_db
         ;;
+    "migrate")
+        migrate_db
+        ;;
     "reset")
         reset_db
         ;;
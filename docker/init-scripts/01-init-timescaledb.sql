@@ -38,21 +38,51 @@ CREATE TABLE IF NOT EXISTS symbols (
     name VARCHAR(100),
     exchange VARCHAR(50),
     asset_type VARCHAR(20), -- 'stock', 'crypto', 'forex', etc.
+    currency VARCHAR(3) NOT NULL DEFAULT 'USD', -- settlement/quote currency
+    -- Trading constraints (NULL means unconstrained)
+    tick_size DECIMAL(20, 8), -- minimum price increment
+    min_quantity DECIMAL(20, 8), -- minimum order quantity
+    quantity_step DECIMAL(20, 8), -- order quantity increment (1 = whole shares only)
+    min_notional DECIMAL(20, 8), -- minimum order value
+    -- Futures contract specification (NULL for non-futures)
+    root_symbol VARCHAR(20), -- e.g., 'ES' for 'ESZ4'
+    multiplier DECIMAL(20, 8), -- currency value of one point per contract
+    tick_value DECIMAL(20, 8),
+    initial_margin DECIMAL(20, 8),
+    maintenance_margin DECIMAL(20, 8),
+    expiration DATE, -- last trading day
     active BOOLEAN DEFAULT TRUE,
     created_at TIMESTAMPTZ DEFAULT NOW(),
     updated_at TIMESTAMPTZ DEFAULT NOW()
 );
 
 -- Insert some common symbols
-INSERT INTO symbols (symbol, name, exchange, asset_type) VALUES 
-    ('AAPL', 'Apple Inc.', 'NASDAQ', 'stock'),
-    ('GOOGL', 'Alphabet Inc.', 'NASDAQ', 'stock'),
-    ('TSLA', 'Tesla Inc.', 'NASDAQ', 'stock'),
-    ('SPY', 'SPDR S&P 500 ETF', 'NYSE', 'etf'),
-    ('BTCUSD', 'Bitcoin USD', 'CRYPTO', 'crypto'),
-    ('ETHUSD', 'Ethereum USD', 'CRYPTO', 'crypto')
+INSERT INTO symbols (symbol, name, exchange, asset_type, currency, tick_size, min_quantity, quantity_step, min_notional) VALUES 
+    ('AAPL', 'Apple Inc.', 'NASDAQ', 'stock', 'USD', 0.01, 1, 1, NULL),
+    ('GOOGL', 'Alphabet Inc.', 'NASDAQ', 'stock', 'USD', 0.01, 1, 1, NULL),
+    ('TSLA', 'Tesla Inc.', 'NASDAQ', 'stock', 'USD', 0.01, 1, 1, NULL),
+    ('SPY', 'SPDR S&P 500 ETF', 'NYSE', 'etf', 'USD', 0.01, 1, 1, NULL),
+    ('BTCUSD', 'Bitcoin USD', 'CRYPTO', 'crypto', 'USD', 0.01, 0.0001, 0.00000001, 10),
+    ('ETHUSD', 'Ethereum USD', 'CRYPTO', 'crypto', 'USD', 0.01, 0.001, 0.00000001, 10)
 ON CONFLICT (symbol) DO NOTHING;
 
+CREATE INDEX IF NOT EXISTS idx_symbols_root ON symbols (root_symbol, expiration);
+
+-- Create FX rates table (price of one unit of base_currency in quote_currency)
+CREATE TABLE IF NOT EXISTS fx_rates (
+    base_currency VARCHAR(3) NOT NULL,
+    quote_currency VARCHAR(3) NOT NULL,
+    timestamp TIMESTAMPTZ NOT NULL,
+    rate DECIMAL(20, 10) NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (base_currency, quote_currency, timestamp)
+);
+
+-- Convert fx_rates table to hypertable
+SELECT create_hypertable('fx_rates', 'timestamp', 
+    chunk_time_interval => INTERVAL '1 month',
+    if_not_exists => TRUE);
+
 -- Create table for trade executions
 CREATE TABLE IF NOT EXISTS trades (
     id BIGSERIAL,
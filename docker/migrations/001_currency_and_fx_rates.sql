@@ -0,0 +1,19 @@
+-- Brings databases created before multi-currency support up to date.
+-- Fresh databases get the same schema from init-scripts; this file is safe to re-run.
+
+ALTER TABLE symbols
+    ADD COLUMN IF NOT EXISTS currency VARCHAR(3) NOT NULL DEFAULT 'USD'; -- settlement/quote currency
+
+-- FX rates table (price of one unit of base_currency in quote_currency)
+CREATE TABLE IF NOT EXISTS fx_rates (
+    base_currency VARCHAR(3) NOT NULL,
+    quote_currency VARCHAR(3) NOT NULL,
+    timestamp TIMESTAMPTZ NOT NULL,
+    rate DECIMAL(20, 10) NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (base_currency, quote_currency, timestamp)
+);
+
+SELECT create_hypertable('fx_rates', 'timestamp',
+    chunk_time_interval => INTERVAL '1 month',
+    if_not_exists => TRUE);
@@ -0,0 +1,14 @@
+-- Brings databases created before futures contract specifications up to date.
+-- Fresh databases get the same schema from init-scripts; this file is safe to re-run.
+
+ALTER TABLE symbols
+    -- Futures contract specification (NULL for non-futures)
+    ADD COLUMN IF NOT EXISTS root_symbol VARCHAR(20), -- e.g., 'ES' for 'ESZ4'
+    ADD COLUMN IF NOT EXISTS multiplier DECIMAL(20, 8), -- currency value of one point per contract
+    ADD COLUMN IF NOT EXISTS tick_size DECIMAL(20, 8),
+    ADD COLUMN IF NOT EXISTS tick_value DECIMAL(20, 8),
+    ADD COLUMN IF NOT EXISTS initial_margin DECIMAL(20, 8),
+    ADD COLUMN IF NOT EXISTS maintenance_margin DECIMAL(20, 8),
+    ADD COLUMN IF NOT EXISTS expiration DATE; -- last trading day
+
+CREATE INDEX IF NOT EXISTS idx_symbols_root ON symbols (root_symbol, expiration);
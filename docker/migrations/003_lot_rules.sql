@@ -0,0 +1,18 @@
+-- Brings databases created before per-instrument lot rules up to date.
+-- Fresh databases get the same schema from init-scripts; this file is safe to re-run.
+
+ALTER TABLE symbols
+    -- Trading constraints (NULL means unconstrained); tick_size comes from 002
+    ADD COLUMN IF NOT EXISTS min_quantity DECIMAL(20, 8), -- minimum order quantity
+    ADD COLUMN IF NOT EXISTS quantity_step DECIMAL(20, 8), -- order quantity increment (1 = whole shares only)
+    ADD COLUMN IF NOT EXISTS min_notional DECIMAL(20, 8); -- minimum order value
+
+-- Whole-share and crypto lot rules for the default symbols
+UPDATE symbols SET tick_size = 0.01, min_quantity = 1, quantity_step = 1
+WHERE symbol IN ('AAPL', 'GOOGL', 'TSLA', 'SPY') AND min_quantity IS NULL;
+
+UPDATE symbols SET tick_size = 0.01, min_quantity = 0.0001, quantity_step = 0.00000001, min_notional = 10
+WHERE symbol = 'BTCUSD' AND min_quantity IS NULL;
+
+UPDATE symbols SET tick_size = 0.01, min_quantity = 0.001, quantity_step = 0.00000001, min_notional = 10
+WHERE symbol = 'ETHUSD' AND min_quantity IS NULL;
@@ -2,6 +2,7 @@ package data
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 
@@ -188,6 +189,149 @@ func (p *TimescaleDBProvider) GetBarsLimit(symbol string, timeframe string, limi
 	return bars, nil
 }
 
+// ErrInstrumentNotFound is returned by GetInstrument when the symbols table has no row for the symbol
+var ErrInstrumentNotFound = errors.New("instrument not found")
+
+// GetInstrument retrieves reference data for a symbol from the symbols table
+func (p *TimescaleDBProvider) GetInstrument(symbol string) (*strategy.Instrument, error) {
+	query := `
+		SELECT symbol, COALESCE(name, ''), COALESCE(exchange, ''), COALESCE(asset_type, ''), currency,
+			COALESCE(min_quantity, 0), COALESCE(quantity_step, 0), COALESCE(min_notional, 0),
+			COALESCE(root_symbol, ''), COALESCE(multiplier, 0), COALESCE(tick_size, 0), COALESCE(tick_value, 0),
+			COALESCE(initial_margin, 0), COALESCE(maintenance_margin, 0), expiration
+		FROM symbols
+		WHERE symbol = $1
+	`
+
+	row := p.db.QueryRow(query, symbol)
+
+	var instrument strategy.Instrument
+	var expiration sql.NullTime
+	err := row.Scan(
+		&instrument.Symbol,
+		&instrument.Name,
+		&instrument.Exchange,
+		&instrument.AssetType,
+		&instrument.Currency,
+		&instrument.MinQuantity,
+		&instrument.QuantityStep,
+		&instrument.MinNotional,
+		&instrument.Root,
+		&instrument.Multiplier,
+		&instrument.TickSize,
+		&instrument.TickValue,
+		&instrument.InitialMargin,
+		&instrument.MaintenanceMargin,
+		&expiration,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w: %s", ErrInstrumentNotFound, symbol)
+		}
+		return nil, fmt.Errorf("failed to get instrument: %w", err)
+	}
+
+	if expiration.Valid {
+		instrument.Expiration = expiration.Time
+	}
+
+	return &instrument, nil
+}
+
+// GetFuturesContracts retrieves all contracts for a futures root symbol ordered by expiration
+func (p *TimescaleDBProvider) GetFuturesContracts(root string) ([]strategy.Instrument, error) {
+	query := `
+		SELECT symbol
+		FROM symbols
+		WHERE root_symbol = $1 AND asset_type = 'future' AND expiration IS NOT NULL
+		ORDER BY expiration ASC
+	`
+
+	rows, err := p.db.Query(query, root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query futures contracts: %w", err)
+	}
+	defer rows.Close()
+
+	var symbols []string
+	for rows.Next() {
+		var symbol string
+		if err := rows.Scan(&symbol); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		symbols = append(symbols, symbol)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	contracts := make([]strategy.Instrument, 0, len(symbols))
+	for _, symbol := range symbols {
+		instrument, err := p.GetInstrument(symbol)
+		if err != nil {
+			return nil, err
+		}
+		contracts = append(contracts, *instrument)
+	}
+
+	p.logger.Debug().
+		Str("root", root).
+		Int("contracts", len(contracts)).
+		Msg("Fetched futures contracts")
+
+	return contracts, nil
+}
+
+// GetFXRates retrieves the FX rate series for a currency pair
+func (p *TimescaleDBProvider) GetFXRates(base string, quote string, start time.Time, end time.Time) ([]feed.FXRate, error) {
+	p.logger.Debug().
+		Str("base", base).
+		Str("quote", quote).
+		Time("start", start).
+		Time("end", end).
+		Msg("Fetching FX rates from database")
+
+	query := `
+		SELECT base_currency, quote_currency, timestamp, rate
+		FROM fx_rates
+		WHERE base_currency = $1 AND quote_currency = $2 AND timestamp >= $3 AND timestamp <= $4
+		ORDER BY timestamp ASC
+	`
+
+	rows, err := p.db.Query(query, base, quote, start, end)
+	if err != nil {
+		p.logger.Error().Err(err).
+			Str("base", base).
+			Str("quote", quote).
+			Msg("Failed to query fx_rates")
+		return nil, fmt.Errorf("failed to query fx_rates: %w", err)
+	}
+	defer rows.Close()
+
+	var rates []feed.FXRate
+	for rows.Next() {
+		var rate feed.FXRate
+		if err := rows.Scan(&rate.Base, &rate.Quote, &rate.Timestamp, &rate.Rate); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		rates = append(rates, rate)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	p.logger.Info().
+		Str("base", base).
+		Str("quote", quote).
+		Int("rates_count", len(rates)).
+		Msg("Successfully fetched FX rates from database")
+
+	return rates, nil
+}
+
 // Close closes the database connection
 func (p *TimescaleDBProvider) Close() error {
 	p.logger.Info().Msg("Closing TimescaleDB connection")
@@ -196,3 +340,5 @@ func (p *TimescaleDBProvider) Close() error {
 
 // Verify that TimescaleDBProvider implements the HistoricalDataProvider interface
 var _ feed.HistoricalDataProvider = (*TimescaleDBProvider)(nil)
+var _ feed.InstrumentProvider = (*TimescaleDBProvider)(nil)
+var _ feed.FXRateProvider = (*TimescaleDBProvider)(nil)
@@ -0,0 +1,121 @@
+package backtester
+
+import (
+	"sort"
+	"time"
+)
+
+// Attribution is the share of a run's performance from one symbol, signal reason or
+// strategy, measured on its round trips. P&L and fees are in the base currency, so rows
+// for symbols trading in different currencies are comparable.
+type Attribution struct {
+	RoundTrips   int     `json:"round_trips"`
+	GrossPL      float64 `json:"gross_pl"`
+	Fees         float64 `json:"fees"`
+	NetPL        float64 `json:"net_pl"`
+	WinRate      float64 `json:"win_rate"`     // Percentage of round trips with positive net P&L
+	Exposure     float64 `json:"exposure"`     // Percentage of the run with a position open
+	Contribution float64 `json:"contribution"` // Net P&L as percentage points of the initial capital
+}
+
+// CostBreakdown splits trading costs by type, in the base currency at each fill's FX rate.
+// Drag is the total as a percentage of the initial capital.
+type CostBreakdown struct {
+	Commission float64 `json:"commission"`
+	SecFee     float64 `json:"sec_fee"`
+	FinraTaf   float64 `json:"finra_taf"`
+	Slippage   float64 `json:"slippage"`
+	Total      float64 `json:"total"`
+	Drag       float64 `json:"drag"`
+}
+
+// calculateAttribution breaks the round trips down by symbol, entry reason and strategy,
+// and totals the costs of every fill
+func (r *Results) calculateAttribution() {
+	r.Metrics.BySymbol = r.attribute(func(rt RoundTrip) string { return rt.Symbol })
+	r.Metrics.ByReason = r.attribute(func(rt RoundTrip) string { return attributionKey(rt.EntryReason) })
+	r.Metrics.ByStrategy = r.attribute(func(rt RoundTrip) string { return attributionKey(rt.Strategy) })
+
+	costs := CostBreakdown{}
+	for _, trade := range r.Trades {
+		rate := fxRate(trade)
+		costs.Commission += trade.Commission * rate
+		costs.SecFee += trade.SecFee * rate
+		costs.FinraTaf += trade.FinraTaf * rate
+		costs.Slippage += trade.Slippage * rate
+	}
+	costs.Total = costs.Commission + costs.SecFee + costs.FinraTaf + costs.Slippage
+	if r.InitialCapital > 0 {
+		costs.Drag = costs.Total / r.InitialCapital * 100
+	}
+	r.Metrics.Costs = costs
+}
+
+// attribute groups the round trips by key
+func (r *Results) attribute(key func(RoundTrip) string) map[string]Attribution {
+	groups := make(map[string][]RoundTrip)
+	for _, rt := range r.RoundTrips {
+		k := key(rt)
+		groups[k] = append(groups[k], rt)
+	}
+
+	var span time.Duration
+	if len(r.EquityCurve) > 1 {
+		span = r.EquityCurve[len(r.EquityCurve)-1].Timestamp.Sub(r.EquityCurve[0].Timestamp)
+	}
+
+	attribution := make(map[string]Attribution, len(groups))
+	for k, group := range groups {
+		a := Attribution{RoundTrips: len(group)}
+		wins := 0
+		for _, rt := range group {
+			a.GrossPL += rt.BaseNetPL + rt.BaseFees
+			a.Fees += rt.BaseFees
+			a.NetPL += rt.BaseNetPL
+			if rt.NetPL > 0 {
+				wins++
+			}
+		}
+		a.WinRate = float64(wins) / float64(len(group)) * 100
+		if span > 0 {
+			a.Exposure = float64(exposedTime(group)) / float64(span) * 100
+		}
+		if r.InitialCapital > 0 {
+			a.Contribution = a.NetPL / r.InitialCapital * 100
+		}
+		attribution[k] = a
+	}
+	return attribution
+}
+
+// exposedTime returns the total time covered by the round trips' holding periods,
+// counting overlapping periods once
+func exposedTime(roundTrips []RoundTrip) time.Duration {
+	intervals := make([]RoundTrip, len(roundTrips))
+	copy(intervals, roundTrips)
+	sort.Slice(intervals, func(i, j int) bool {
+		return intervals[i].EntryTime.Before(intervals[j].EntryTime)
+	})
+
+	var total time.Duration
+	var start, end time.Time
+	for i, rt := range intervals {
+		if i == 0 || rt.EntryTime.After(end) {
+			total += end.Sub(start)
+			start, end = rt.EntryTime, rt.ExitTime
+			continue
+		}
+		if rt.ExitTime.After(end) {
+			end = rt.ExitTime
+		}
+	}
+	return total + end.Sub(start)
+}
+
+// attributionKey groups round trips without an entry reason or strategy together
+func attributionKey(key string) string {
+	if key == "" {
+		return unspecifiedKey
+	}
+	return key
+}
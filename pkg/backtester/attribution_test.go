@@ -0,0 +1,82 @@
+package backtester
+
+import (
+	"testing"
+
+	"github.com/ridopark/JonBuhTrader/pkg/strategy"
+)
+
+func TestAttributionConvertsIntoBaseCurrency(t *testing.T) {
+	// AAPL trades in USD, the base currency; SAP in EUR, bought at 1.10 and sold at 1.20
+	trade := func(index int, symbol string, side strategy.OrderSide, price, commission, rate float64) strategy.TradeEvent {
+		trade := fill(index, side, 10, price, commission, "breakout")
+		trade.Symbol, trade.FXRate = symbol, rate
+		return trade
+	}
+	trades := []strategy.TradeEvent{
+		trade(0, "AAPL", strategy.OrderSideBuy, 100, 1, 1),
+		trade(1, "SAP", strategy.OrderSideBuy, 200, 2, 1.10),
+		trade(2, "AAPL", strategy.OrderSideSell, 110, 1, 1),
+		trade(3, "SAP", strategy.OrderSideSell, 220, 2, 1.20),
+	}
+	r := &Results{
+		InitialCapital: 10000,
+		Trades:         trades,
+		RoundTrips:     RoundTrips(trades, nil, nil),
+		EquityCurve:    valueCurve(10000, 10333.2),
+		Metrics:        &PerformanceMetrics{},
+	}
+
+	// SAP nets 196 EUR, converted at the exit rate of 1.20
+	sap := r.RoundTrips[1]
+	assertClose(t, "SAP net P&L", sap.NetPL, 196)
+	assertClose(t, "SAP base net P&L", sap.BaseNetPL, 196*1.2)
+	assertClose(t, "SAP base fees", sap.BaseFees, 4*1.2)
+	assertClose(t, "AAPL base net P&L", r.RoundTrips[0].BaseNetPL, 98)
+
+	r.calculateAttribution()
+	bySymbol := r.Metrics.BySymbol
+	assertClose(t, "SAP net P&L", bySymbol["SAP"].NetPL, 235.2)
+	assertClose(t, "SAP gross P&L", bySymbol["SAP"].GrossPL, 240)
+	assertClose(t, "SAP fees", bySymbol["SAP"].Fees, 4.8)
+	assertClose(t, "SAP contribution", bySymbol["SAP"].Contribution, 2.352)
+	assertClose(t, "AAPL contribution", bySymbol["AAPL"].Contribution, 0.98)
+	// Groups spanning both currencies add base-currency amounts
+	assertClose(t, "strategy net P&L", r.Metrics.ByStrategy["scripted"].NetPL, 333.2)
+	assertClose(t, "reason fees", r.Metrics.ByReason["breakout"].Fees, 2+4.8)
+
+	// Each fill's costs convert at its own rate
+	costs := r.Metrics.Costs
+	assertClose(t, "commission", costs.Commission, 1+1+2*1.1+2*1.2)
+	assertClose(t, "total costs", costs.Total, 6.6)
+	assertClose(t, "cost drag", costs.Drag, 0.066)
+
+	stats := SummarizeRoundTrips(r.RoundTrips)
+	assertClose(t, "round-trip net P&L", stats.NetPL, 333.2)
+	assertClose(t, "expectancy", stats.Expectancy, 166.6)
+}
+
+func TestAttributionGroupsUnnamedStrategiesAndReasons(t *testing.T) {
+	r := &Results{
+		InitialCapital: 1000,
+		RoundTrips: []RoundTrip{
+			{Strategy: "breakout", EntryReason: "signal", NetPL: 10, BaseNetPL: 10},
+			{NetPL: -4, BaseNetPL: -4},
+			{NetPL: 6, BaseNetPL: 6},
+		},
+		Metrics: &PerformanceMetrics{},
+	}
+	r.calculateAttribution()
+
+	for name, groups := range map[string]map[string]Attribution{"strategy": r.Metrics.ByStrategy, "reason": r.Metrics.ByReason} {
+		if _, ok := groups[""]; ok || len(groups) != 2 {
+			t.Errorf("by %s = %+v, want a named group and %q", name, groups, unspecifiedKey)
+			continue
+		}
+		unspecified := groups[unspecifiedKey]
+		if unspecified.RoundTrips != 2 {
+			t.Errorf("by %s: %q has %d round trips, want 2", name, unspecifiedKey, unspecified.RoundTrips)
+		}
+		assertClose(t, name+" unspecified net P&L", unspecified.NetPL, 2)
+	}
+}
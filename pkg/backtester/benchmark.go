@@ -0,0 +1,162 @@
+package backtester
+
+import (
+	"math"
+	"sort"
+
+	"github.com/ridopark/JonBuhTrader/pkg/strategy"
+)
+
+// BenchmarkUniverse selects an equal-weight buy-and-hold of the traded symbols as the benchmark
+const BenchmarkUniverse = "universe"
+
+// BenchmarkMetrics compares the strategy's daily returns with a benchmark's. Figures are
+// annualized with the results' periods per year; alpha is net of the risk-free rate.
+type BenchmarkMetrics struct {
+	Benchmark        string  `json:"benchmark"`         // Benchmark symbol or BenchmarkUniverse
+	BenchmarkReturn  float64 `json:"benchmark_return"`  // Total benchmark return as a percentage
+	ExcessReturn     float64 `json:"excess_return"`     // Strategy minus benchmark total return, in percentage points
+	Alpha            float64 `json:"alpha"`             // Annualized Jensen's alpha as a decimal
+	Beta             float64 `json:"beta"`              // Sensitivity of strategy returns to benchmark returns
+	Correlation      float64 `json:"correlation"`       // Correlation of daily returns
+	TrackingError    float64 `json:"tracking_error"`    // Annualized standard deviation of excess returns
+	InformationRatio float64 `json:"information_ratio"` // Annualized mean excess return over tracking error
+	UpCapture        float64 `json:"up_capture"`        // Strategy return relative to benchmark on up days (1.0 = 100%)
+	DownCapture      float64 `json:"down_capture"`      // Strategy return relative to benchmark on down days (1.0 = 100%)
+	Periods          int     `json:"periods"`           // Number of daily returns compared
+}
+
+// SetBenchmark sets the benchmark the results are compared with: a symbol with its bars
+// (e.g., SPY over the backtest period), or BenchmarkUniverse with no bars for an
+// equal-weight buy-and-hold of the traded symbols
+func (e *Engine) SetBenchmark(symbol string, bars []strategy.BarData) {
+	e.benchmarkSymbol = symbol
+	e.benchmarkBars = bars
+}
+
+// BuyAndHoldCurve values capital split equally across the symbols at their first close
+// and held, sampled at each timestamp of curve. Capital allocated to a symbol stays in
+// cash until its first bar.
+func BuyAndHoldCurve(bars map[string][]strategy.BarData, curve []EquityPoint, capital float64) []EquityPoint {
+	if len(bars) == 0 || len(curve) == 0 {
+		return nil
+	}
+
+	symbols := make([]string, 0, len(bars))
+	for symbol := range bars {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+
+	type holding struct {
+		bars  []strategy.BarData
+		next  int
+		units float64
+		last  float64
+	}
+	allocation := capital / float64(len(symbols))
+	holdings := make([]*holding, 0, len(symbols))
+	for _, symbol := range symbols {
+		if series := bars[symbol]; len(series) > 0 && series[0].Close > 0 {
+			holdings = append(holdings, &holding{bars: series, units: allocation / series[0].Close})
+		}
+	}
+	idle := allocation * float64(len(symbols)-len(holdings))
+
+	result := make([]EquityPoint, 0, len(curve))
+	for _, point := range curve {
+		value := idle
+		for _, h := range holdings {
+			for h.next < len(h.bars) && !h.bars[h.next].Timestamp.After(point.Timestamp) {
+				h.last = h.bars[h.next].Close
+				h.next++
+			}
+			if h.last == 0 {
+				value += allocation
+			} else {
+				value += h.units * h.last
+			}
+		}
+		result = append(result, EquityPoint{Timestamp: point.Timestamp, Value: value})
+	}
+	return result
+}
+
+// calculateBenchmarkMetrics compares the equity curve with the benchmark curve and sets
+// the excess-return curve
+func (r *Results) calculateBenchmarkMetrics() {
+	r.ExcessReturnCurve = nil
+	if len(r.BenchmarkCurve) == 0 || len(r.BenchmarkCurve) != len(r.EquityCurve) {
+		return
+	}
+
+	metrics := &BenchmarkMetrics{Benchmark: r.BenchmarkSymbol}
+	r.Metrics.Benchmark = metrics
+
+	last := r.BenchmarkCurve[len(r.BenchmarkCurve)-1].Value
+	metrics.BenchmarkReturn = (last/r.InitialCapital - 1) * 100
+	metrics.ExcessReturn = r.TotalReturn - metrics.BenchmarkReturn
+
+	// Equity of holding the strategy against a short benchmark position
+	r.ExcessReturnCurve = make([]EquityPoint, len(r.EquityCurve))
+	for i, point := range r.EquityCurve {
+		relative := 0.0
+		if benchmark := r.BenchmarkCurve[i].Value; benchmark > 0 {
+			relative = r.InitialCapital * point.Value / benchmark
+		}
+		r.ExcessReturnCurve[i] = EquityPoint{Timestamp: point.Timestamp, Value: relative}
+	}
+
+	strategyReturns := periodReturns(r.dailyCloses(r.EquityCurve), r.InitialCapital)
+	benchmarkReturns := periodReturns(r.dailyCloses(r.BenchmarkCurve), r.InitialCapital)
+	n := len(strategyReturns)
+	metrics.Periods = n
+	if n < 2 {
+		return
+	}
+
+	strategyMean, benchmarkMean := mean(strategyReturns), mean(benchmarkReturns)
+	var covariance, strategyVariance, benchmarkVariance float64
+	excess := make([]float64, n)
+	for i := range strategyReturns {
+		ds, db := strategyReturns[i]-strategyMean, benchmarkReturns[i]-benchmarkMean
+		covariance += ds * db
+		strategyVariance += ds * ds
+		benchmarkVariance += db * db
+		excess[i] = strategyReturns[i] - benchmarkReturns[i]
+	}
+
+	if benchmarkVariance > 0 {
+		metrics.Beta = covariance / benchmarkVariance
+	}
+	if strategyVariance > 0 && benchmarkVariance > 0 {
+		metrics.Correlation = covariance / math.Sqrt(strategyVariance*benchmarkVariance)
+	}
+	periods := float64(r.periodsPerYear())
+	riskFree := r.RiskFreeRate / periods
+	metrics.Alpha = (strategyMean - riskFree - metrics.Beta*(benchmarkMean-riskFree)) * periods
+
+	metrics.TrackingError = stdDev(excess) * math.Sqrt(periods)
+	if metrics.TrackingError > 0 {
+		metrics.InformationRatio = mean(excess) * periods / metrics.TrackingError
+	}
+
+	metrics.UpCapture = captureRatio(strategyReturns, benchmarkReturns, func(r float64) bool { return r > 0 })
+	metrics.DownCapture = captureRatio(strategyReturns, benchmarkReturns, func(r float64) bool { return r < 0 })
+}
+
+// captureRatio divides the strategy's mean return by the benchmark's over the periods
+// whose benchmark return satisfies include
+func captureRatio(strategyReturns, benchmarkReturns []float64, include func(float64) bool) float64 {
+	var strategySum, benchmarkSum float64
+	for i, r := range benchmarkReturns {
+		if include(r) {
+			strategySum += strategyReturns[i]
+			benchmarkSum += r
+		}
+	}
+	if benchmarkSum == 0 {
+		return 0
+	}
+	return strategySum / benchmarkSum
+}
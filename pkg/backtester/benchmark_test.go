@@ -0,0 +1,128 @@
+package backtester
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/ridopark/JonBuhTrader/pkg/strategy"
+)
+
+// dailyCurve compounds start by each return, one equity point per day at 21:00 UTC
+func dailyCurve(start float64, returns []float64) []EquityPoint {
+	first := time.Date(2024, 1, 2, 21, 0, 0, 0, time.UTC)
+	curve := make([]EquityPoint, len(returns))
+	value := start
+	for i, r := range returns {
+		value *= 1 + r
+		curve[i] = EquityPoint{Timestamp: first.AddDate(0, 0, i), Value: value}
+	}
+	return curve
+}
+
+func TestBuyAndHoldCurve(t *testing.T) {
+	t0 := time.Date(2024, 1, 2, 21, 0, 0, 0, time.UTC)
+	t1, t2 := t0.AddDate(0, 0, 1), t0.AddDate(0, 0, 2)
+	bars := map[string][]strategy.BarData{
+		"A": {{Timestamp: t0, Close: 10}, {Timestamp: t1, Close: 12}, {Timestamp: t1.Add(time.Hour), Close: 14}, {Timestamp: t2, Close: 15}},
+		"B": {{Timestamp: t1, Close: 50}, {Timestamp: t2, Close: 25}},
+		"C": nil, // No bars: its third stays in cash
+	}
+	curve := []EquityPoint{{Timestamp: t0.Add(-time.Hour)}, {Timestamp: t0}, {Timestamp: t1}, {Timestamp: t2}}
+
+	// 300 per symbol buys 30 A at 10 and 6 B at 50; each third is cash until its first bar
+	got := BuyAndHoldCurve(bars, curve, 900)
+	want := []float64{900, 900, 30*12 + 300 + 300, 30*15 + 6*25 + 300}
+	if len(got) != len(want) {
+		t.Fatalf("got %d points, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if !got[i].Timestamp.Equal(curve[i].Timestamp) {
+			t.Errorf("point %d timestamp = %v, want %v", i, got[i].Timestamp, curve[i].Timestamp)
+		}
+		assertClose(t, "buy-and-hold value", got[i].Value, want[i])
+	}
+
+	if got := BuyAndHoldCurve(nil, curve, 900); got != nil {
+		t.Errorf("BuyAndHoldCurve without bars = %v, want nil", got)
+	}
+	if got := BuyAndHoldCurve(bars, nil, 900); got != nil {
+		t.Errorf("BuyAndHoldCurve without equity points = %v, want nil", got)
+	}
+}
+
+func TestBenchmarkMetrics(t *testing.T) {
+	// The strategy earns twice the benchmark's daily return plus 0.1%
+	benchmarkReturns := []float64{0.01, -0.02, 0.03, -0.01}
+	strategyReturns := make([]float64, len(benchmarkReturns))
+	excess := make([]float64, len(benchmarkReturns))
+	for i, b := range benchmarkReturns {
+		strategyReturns[i] = 2*b + 0.001
+		excess[i] = strategyReturns[i] - b
+	}
+	equity, benchmark := dailyCurve(100, strategyReturns), dailyCurve(100, benchmarkReturns)
+	// An intraday point on the first day is not a session close
+	intraday := equity[0].Timestamp.Add(-6 * time.Hour)
+	equity = append([]EquityPoint{{Timestamp: intraday, Value: 150}}, equity...)
+	benchmark = append([]EquityPoint{{Timestamp: intraday, Value: 50}}, benchmark...)
+
+	final := equity[len(equity)-1].Value
+	r := &Results{
+		BenchmarkSymbol: "SPY",
+		InitialCapital:  100,
+		TotalReturn:     (final/100 - 1) * 100,
+		RiskFreeRate:    0.0252,
+		EquityCurve:     equity,
+		BenchmarkCurve:  benchmark,
+		Metrics:         &PerformanceMetrics{},
+	}
+	r.calculateBenchmarkMetrics()
+
+	b := r.Metrics.Benchmark
+	if b == nil {
+		t.Fatal("benchmark metrics not set")
+	}
+	if b.Benchmark != "SPY" || b.Periods != 4 {
+		t.Errorf("benchmark = %q over %d periods, want SPY over 4", b.Benchmark, b.Periods)
+	}
+	benchmarkReturn := (benchmark[len(benchmark)-1].Value/100 - 1) * 100
+	assertClose(t, "benchmark return", b.BenchmarkReturn, benchmarkReturn)
+	assertClose(t, "excess return", b.ExcessReturn, r.TotalReturn-benchmarkReturn)
+	assertClose(t, "beta", b.Beta, 2)
+	assertClose(t, "correlation", b.Correlation, 1)
+	// Daily alpha is the 0.1% plus the risk-free rate the extra beta finances: (0.001 + 0.0001) * 252
+	assertClose(t, "alpha", b.Alpha, 0.2772)
+	trackingError := stdDev(excess) * math.Sqrt(252)
+	assertClose(t, "tracking error", b.TrackingError, trackingError)
+	assertClose(t, "information ratio", b.InformationRatio, mean(excess)*252/trackingError)
+	// Up days: (0.021 + 0.061) / (0.01 + 0.03); down days: (-0.039 - 0.019) / (-0.02 - 0.01)
+	assertClose(t, "up capture", b.UpCapture, 2.05)
+	assertClose(t, "down capture", b.DownCapture, 0.058/0.03)
+
+	if len(r.ExcessReturnCurve) != len(equity) {
+		t.Fatalf("excess return curve has %d points, want %d", len(r.ExcessReturnCurve), len(equity))
+	}
+	for i, point := range r.ExcessReturnCurve {
+		assertClose(t, "excess return curve", point.Value, 100*equity[i].Value/benchmark[i].Value)
+	}
+}
+
+func TestBenchmarkMetricsNeedMatchingCurves(t *testing.T) {
+	r := &Results{
+		InitialCapital: 100,
+		EquityCurve:    dailyCurve(100, []float64{0.01, 0.02}),
+		BenchmarkCurve: dailyCurve(100, []float64{0.01}),
+		Metrics:        &PerformanceMetrics{},
+	}
+	r.calculateBenchmarkMetrics()
+	if r.Metrics.Benchmark != nil || r.ExcessReturnCurve != nil {
+		t.Errorf("benchmark metrics = %+v for curves of different lengths, want none", r.Metrics.Benchmark)
+	}
+}
+
+func TestCaptureRatio(t *testing.T) {
+	up := func(r float64) bool { return r > 0 }
+	assertClose(t, "capture", captureRatio([]float64{0.02, 0.5, 0.01}, []float64{0.01, -0.1, 0.01}, up), 1.5)
+	// No benchmark day qualifies
+	assertClose(t, "capture without up days", captureRatio([]float64{0.02}, []float64{-0.01}, up), 0)
+}
@@ -46,8 +46,9 @@ func (cc *CommissionConfig) CalculateCommission(tradeValue float64) float64 {
 // Broker simulates order execution for backtesting
 type Broker struct {
 	commissionConfig *CommissionConfig
-	slippage         float64 // Base slippage as a percentage
-	maxSlippage      float64 // Maximum randomized slippage as a percentage
+	slippage         float64                         // Base slippage as a percentage
+	maxSlippage      float64                         // Maximum randomized slippage as a percentage
+	instruments      map[string]*strategy.Instrument // symbol -> instrument reference data
 }
 
 // NewBroker creates a new simulated broker
@@ -56,9 +57,41 @@ func NewBroker(commissionConfig *CommissionConfig, slippage float64, maxSlippage
 		commissionConfig: commissionConfig,
 		slippage:         slippage,
 		maxSlippage:      maxSlippage,
+		instruments:      make(map[string]*strategy.Instrument),
 	}
 }
 
+// SetInstrument registers reference data for a symbol
+func (b *Broker) SetInstrument(instrument strategy.Instrument) {
+	b.instruments[instrument.Symbol] = &instrument
+}
+
+// PrepareOrder rounds limit and stop prices to the instrument's tick size and
+// validates quantity and notional constraints. Orders for symbols without
+// registered reference data are returned unchanged.
+func (b *Broker) PrepareOrder(order strategy.Order, currentBar strategy.BarData) (strategy.Order, error) {
+	instrument, exists := b.instruments[order.Symbol]
+	if !exists {
+		return order, nil
+	}
+
+	price := currentBar.Close
+	switch order.Type {
+	case strategy.OrderTypeLimit:
+		order.Price = instrument.RoundPrice(order.Price, order.Side, order.Type)
+		price = order.Price
+	case strategy.OrderTypeStop:
+		order.StopPrice = instrument.RoundPrice(order.StopPrice, order.Side, order.Type)
+		price = order.StopPrice
+	}
+
+	if err := instrument.ValidateOrder(order, price); err != nil {
+		return order, err
+	}
+
+	return order, nil
+}
+
 // calculateRandomizedSlippage calculates randomized slippage using noise model
 func (b *Broker) calculateRandomizedSlippage() float64 {
 	// Base slippage + randomized component
@@ -119,7 +152,14 @@ func (b *Broker) ExecuteOrder(order strategy.Order, currentBar strategy.BarData)
 	}
 
 	// Calculate fees and costs
-	tradeValue := order.Quantity * fillPrice
+	multiplier := 1.0
+	isFuture := false
+	if instrument, exists := b.instruments[order.Symbol]; exists {
+		multiplier = instrument.GetMultiplier()
+		isFuture = instrument.IsFuture()
+	}
+
+	tradeValue := order.Quantity * fillPrice * multiplier
 	commission := b.commissionConfig.CalculateCommission(tradeValue)
 	slippageCost := 0.0
 
@@ -128,16 +168,20 @@ func (b *Broker) ExecuteOrder(order strategy.Order, currentBar strategy.BarData)
 	if order.Type == strategy.OrderTypeLimit {
 		expectedPrice = order.Price
 	}
-	slippageCost = math.Abs(fillPrice-expectedPrice) * order.Quantity
+	slippageCost = math.Abs(fillPrice-expectedPrice) * order.Quantity * multiplier
 
-	// Calculate SEC fee (only on sells, $0.0000278 per dollar of sale proceeds)
+	// SEC fee and FINRA TAF apply to securities, not futures contracts
 	secFee := 0.0
-	if order.Side == strategy.OrderSideSell {
-		secFee = tradeValue * 0.0000278
-	}
+	finraTaf := 0.0
+	if !isFuture {
+		// Calculate SEC fee (only on sells, $0.0000278 per dollar of sale proceeds)
+		if order.Side == strategy.OrderSideSell {
+			secFee = tradeValue * 0.0000278
+		}
 
-	// Calculate FINRA TAF (Trading Activity Fee: $0.000145 per share, max $7.27)
-	finraTaf := math.Min(order.Quantity*0.000145, 7.27)
+		// Calculate FINRA TAF (Trading Activity Fee: $0.000145 per share, max $7.27)
+		finraTaf = math.Min(order.Quantity*0.000145, 7.27)
+	}
 
 	// Create trade event
 	trade := &strategy.TradeEvent{
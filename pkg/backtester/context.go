@@ -2,60 +2,43 @@ package backtester
 
 import (
 	"fmt"
-	"math"
+	"sort"
 
+	"github.com/ridopark/JonBuhTrader/pkg/indicators"
 	"github.com/ridopark/JonBuhTrader/pkg/logging"
+	"github.com/ridopark/JonBuhTrader/pkg/patterns"
 	"github.com/ridopark/JonBuhTrader/pkg/strategy"
 	"github.com/rs/zerolog"
 )
 
-// ADXData stores data for ADX calculation
-type ADXData struct {
-	TrueRanges []float64 // True Range values
-	DMPlus     []float64 // Directional Movement Plus
-	DMMinus    []float64 // Directional Movement Minus
-	PrevHigh   float64   // Previous high
-	PrevLow    float64   // Previous low
-	PrevClose  float64   // Previous close
-}
+// DefaultMaxLookback is the default number of bars kept per symbol for history and indicator calculations
+const DefaultMaxLookback = 200
 
-// IndicatorData stores data for technical indicators
+// IndicatorData stores the recent bars used to calculate technical indicators for a symbol
 type IndicatorData struct {
-	PriceHistory []float64       // Historical close prices
-	HighHistory  []float64       // Historical high prices
-	LowHistory   []float64       // Historical low prices
-	EMAValues    map[int]float64 // EMA values by period
-	RSIData      *RSIData        // RSI calculation data
-	MACDData     *MACDData       // MACD calculation data
-	ADXData      *ADXData        // ADX calculation data
-}
-
-// RSIData stores data for RSI calculation
-type RSIData struct {
-	Gains  []float64 // Price gains
-	Losses []float64 // Price losses
-}
-
-// MACDData stores data for MACD calculation
-type MACDData struct {
-	FastEMA   float64 // Fast EMA value
-	SlowEMA   float64 // Slow EMA value
-	SignalEMA float64 // Signal line EMA value
+	Bars []strategy.BarData // Recent bars, oldest first
 }
 
 // StrategyContext implements the strategy.Context interface for backtesting
 type StrategyContext struct {
-	engine     *Engine
-	logger     zerolog.Logger
-	indicators map[string]*IndicatorData // symbol -> indicator data
+	engine      *Engine
+	logger      zerolog.Logger
+	indicators  map[string]*IndicatorData // symbol -> indicator data
+	maxLookback int                       // Bars kept per symbol
+	symbols     []string                  // Symbols in the current data point, sorted
+	registry    *indicatorRegistry        // Persistent indicators updated once per bar
+	ranks       map[string][]float64      // Sorted indicator values of the current symbols by spec
 }
 
 // NewStrategyContext creates a new strategy context
 func NewStrategyContext(engine *Engine) *StrategyContext {
 	return &StrategyContext{
-		engine:     engine,
-		logger:     logging.GetLogger("strategy"),
-		indicators: make(map[string]*IndicatorData),
+		engine:      engine,
+		logger:      logging.GetLogger("strategy"),
+		indicators:  make(map[string]*IndicatorData),
+		maxLookback: DefaultMaxLookback,
+		registry:    newIndicatorRegistry(),
+		ranks:       make(map[string][]float64),
 	}
 }
 
@@ -74,344 +57,672 @@ func (sc *StrategyContext) GetCash() float64 {
 	return sc.engine.portfolio.GetCash()
 }
 
+// GetDailySnapshots returns the end-of-session portfolio snapshots recorded so far
+func (sc *StrategyContext) GetDailySnapshots() []strategy.DailySnapshot {
+	return sc.engine.portfolio.GetDailySnapshots()
+}
+
+// GetInstrument returns the reference data registered for a symbol
+func (sc *StrategyContext) GetInstrument(symbol string) *strategy.Instrument {
+	return sc.engine.portfolio.GetInstrument(symbol)
+}
+
 // UpdatePriceHistory updates the price history for technical indicators
 func (sc *StrategyContext) UpdatePriceHistory(dataPoint strategy.DataPoint) {
+	sc.symbols = sc.symbols[:0]
 	for symbol, bar := range dataPoint.Bars {
-		if sc.indicators[symbol] == nil {
-			sc.indicators[symbol] = &IndicatorData{
-				PriceHistory: make([]float64, 0),
-				HighHistory:  make([]float64, 0),
-				LowHistory:   make([]float64, 0),
-				EMAValues:    make(map[int]float64),
-				RSIData:      &RSIData{Gains: make([]float64, 0), Losses: make([]float64, 0)},
-				MACDData:     &MACDData{},
-				ADXData:      &ADXData{TrueRanges: make([]float64, 0), DMPlus: make([]float64, 0), DMMinus: make([]float64, 0)},
-			}
-		}
+		sc.symbols = append(sc.symbols, symbol)
 
 		data := sc.indicators[symbol]
-		data.PriceHistory = append(data.PriceHistory, bar.Close)
-		data.HighHistory = append(data.HighHistory, bar.High)
-		data.LowHistory = append(data.LowHistory, bar.Low)
-
-		// Keep only last 200 prices to avoid memory issues
-		if len(data.PriceHistory) > 200 {
-			data.PriceHistory = data.PriceHistory[1:]
-			data.HighHistory = data.HighHistory[1:]
-			data.LowHistory = data.LowHistory[1:]
-			// Also trim RSI data
-			if len(data.RSIData.Gains) > 200 {
-				data.RSIData.Gains = data.RSIData.Gains[1:]
-			}
-			if len(data.RSIData.Losses) > 200 {
-				data.RSIData.Losses = data.RSIData.Losses[1:]
-			}
-			// Trim ADX data
-			if len(data.ADXData.TrueRanges) > 200 {
-				data.ADXData.TrueRanges = data.ADXData.TrueRanges[1:]
-			}
-			if len(data.ADXData.DMPlus) > 200 {
-				data.ADXData.DMPlus = data.ADXData.DMPlus[1:]
-			}
-			if len(data.ADXData.DMMinus) > 200 {
-				data.ADXData.DMMinus = data.ADXData.DMMinus[1:]
-			}
+		if data == nil {
+			data = &IndicatorData{Bars: make([]strategy.BarData, 0, sc.maxLookback)}
+			sc.indicators[symbol] = data
 		}
 
-		// Update RSI data if we have previous price
-		if len(data.PriceHistory) > 1 {
-			prevPrice := data.PriceHistory[len(data.PriceHistory)-2]
-			currentPrice := data.PriceHistory[len(data.PriceHistory)-1]
-			change := currentPrice - prevPrice
-
-			if change > 0 {
-				data.RSIData.Gains = append(data.RSIData.Gains, change)
-				data.RSIData.Losses = append(data.RSIData.Losses, 0)
-			} else {
-				data.RSIData.Gains = append(data.RSIData.Gains, 0)
-				data.RSIData.Losses = append(data.RSIData.Losses, -change)
-			}
+		data.Bars = append(data.Bars, bar)
+		sc.registry.update(symbol, bar)
+
+		// Keep only the most recent bars to avoid memory issues
+		if len(data.Bars) > sc.maxLookback {
+			data.Bars = data.Bars[len(data.Bars)-sc.maxLookback:]
 		}
+	}
+	sc.registry.updatePairs(dataPoint.Bars)
+	sort.Strings(sc.symbols)
+	clear(sc.ranks)
+}
+
+// indicator returns the registered indicator described by spec for a symbol, creating it
+// and seeding it from the stored history on first use. Registered indicators are updated
+// once per bar, so repeated requests within a bar are served from their current state,
+// recursive values (EMAs, ratcheting bands) are not cut off by the lookback limit, and
+// each parameter set keeps its own state.
+func (sc *StrategyContext) indicator(symbol string, name string, spec strategy.IndicatorSpec) (indicators.Indicator, error) {
+	tracked, err := sc.trackedIndicator(symbol, spec)
+	if err != nil {
+		return nil, err
+	}
 
-		// Update ADX data
-		sc.updateADXData(data, bar.High, bar.Low, bar.Close)
+	if !tracked.indicator.Ready() {
+		return nil, fmt.Errorf("insufficient data for %s: need %d periods, have %d", name, tracked.indicator.WarmupPeriod(), tracked.bars)
 	}
+	return tracked.indicator, nil
 }
 
-// SMA calculates Simple Moving Average
-func (sc *StrategyContext) SMA(symbol string, period int) (float64, error) {
+// trackedIndicator returns the registry entry for the indicator described by spec for a symbol
+func (sc *StrategyContext) trackedIndicator(symbol string, spec strategy.IndicatorSpec) (*trackedIndicator, error) {
 	data, exists := sc.indicators[symbol]
-	if !exists || data.PriceHistory == nil {
-		return 0, fmt.Errorf("no price history available for symbol %s", symbol)
+	if !exists || len(data.Bars) == 0 {
+		return nil, fmt.Errorf("no price history available for symbol %s", symbol)
 	}
 
-	prices := data.PriceHistory
-	if len(prices) < period {
-		return 0, fmt.Errorf("insufficient data: need %d periods, have %d", period, len(prices))
+	key := indicatorKey{
+		symbol:    symbol,
+		timeframe: data.Bars[len(data.Bars)-1].Timeframe,
+		spec:      registrySpec(spec),
 	}
+	return sc.registry.get(key, sc.maxLookback, data.Bars, func() (indicators.Indicator, error) {
+		return indicators.New(spec, sc.engine.sessionLocation)
+	})
+}
 
-	// Calculate SMA using the last 'period' prices
-	sum := 0.0
-	start := len(prices) - period
-	for i := start; i < len(prices); i++ {
-		sum += prices[i]
+// validatePeriod returns an error if an indicator period is not positive
+func validatePeriod(name string, period int) error {
+	if period <= 0 {
+		return fmt.Errorf("invalid %s period %d: must be positive", name, period)
 	}
+	return nil
+}
 
-	return sum / float64(period), nil
+// SMA calculates Simple Moving Average
+func (sc *StrategyContext) SMA(symbol string, period int) (float64, error) {
+	if err := validatePeriod("SMA", period); err != nil {
+		return 0, err
+	}
+
+	indicator, err := sc.indicator(symbol, "SMA", strategy.NewIndicatorSpec("sma", float64(period)))
+	if err != nil {
+		return 0, err
+	}
+	return indicator.Value(), nil
 }
 
 // EMA calculates Exponential Moving Average
 func (sc *StrategyContext) EMA(symbol string, period int) (float64, error) {
-	data, exists := sc.indicators[symbol]
-	if !exists || data.PriceHistory == nil {
-		return 0, fmt.Errorf("no price history available for symbol %s", symbol)
+	if err := validatePeriod("EMA", period); err != nil {
+		return 0, err
+	}
+
+	indicator, err := sc.indicator(symbol, "EMA", strategy.NewIndicatorSpec("ema", float64(period)))
+	if err != nil {
+		return 0, err
 	}
+	return indicator.Value(), nil
+}
 
-	prices := data.PriceHistory
-	if len(prices) < period {
-		return 0, fmt.Errorf("insufficient data: need %d periods, have %d", period, len(prices))
+// RSI calculates Relative Strength Index
+func (sc *StrategyContext) RSI(symbol string, period int) (float64, error) {
+	if err := validatePeriod("RSI", period); err != nil {
+		return 0, err
 	}
 
-	// Check if we already have a calculated EMA for this period
-	if emaValue, exists := data.EMAValues[period]; exists {
-		// Update existing EMA with new price
-		multiplier := 2.0 / (float64(period) + 1.0)
-		currentPrice := prices[len(prices)-1]
-		newEMA := (currentPrice * multiplier) + (emaValue * (1.0 - multiplier))
-		data.EMAValues[period] = newEMA
-		return newEMA, nil
+	indicator, err := sc.indicator(symbol, "RSI", strategy.NewIndicatorSpec("rsi", float64(period)))
+	if err != nil {
+		return 0, err
 	}
+	return indicator.Value(), nil
+}
 
-	// Calculate initial EMA using SMA as seed
-	if len(prices) < period {
-		return 0, fmt.Errorf("insufficient data for initial EMA calculation")
+// MACD calculates Moving Average Convergence Divergence, returning the MACD line, signal line and histogram
+func (sc *StrategyContext) MACD(symbol string, fastPeriod, slowPeriod, signalPeriod int) (float64, float64, float64, error) {
+	for _, period := range []int{fastPeriod, slowPeriod, signalPeriod} {
+		if err := validatePeriod("MACD", period); err != nil {
+			return 0, 0, 0, err
+		}
 	}
 
-	// Calculate SMA for the first 'period' values as initial EMA
-	sum := 0.0
-	for i := 0; i < period; i++ {
-		sum += prices[i]
+	spec := strategy.NewIndicatorSpec("macd", float64(fastPeriod), float64(slowPeriod), float64(signalPeriod))
+	indicator, err := sc.indicator(symbol, "MACD", spec)
+	if err != nil {
+		return 0, 0, 0, err
 	}
-	ema := sum / float64(period)
 
-	// Calculate EMA for remaining values
-	multiplier := 2.0 / (float64(period) + 1.0)
-	for i := period; i < len(prices); i++ {
-		ema = (prices[i] * multiplier) + (ema * (1.0 - multiplier))
+	macd := indicator.(*indicators.MACD)
+	return macd.Value(), macd.Signal(), macd.Histogram(), nil
+}
+
+// ADX calculates Average Directional Index
+func (sc *StrategyContext) ADX(symbol string, period int) (float64, error) {
+	if err := validatePeriod("ADX", period); err != nil {
+		return 0, err
 	}
 
-	// Store the calculated EMA
-	data.EMAValues[period] = ema
-	return ema, nil
+	indicator, err := sc.indicator(symbol, "ADX", strategy.NewIndicatorSpec("adx", float64(period)))
+	if err != nil {
+		return 0, err
+	}
+	return indicator.Value(), nil
 }
 
-// RSI calculates Relative Strength Index
-func (sc *StrategyContext) RSI(symbol string, period int) (float64, error) {
-	data, exists := sc.indicators[symbol]
-	if !exists || data.RSIData == nil {
-		return 0, fmt.Errorf("no RSI data available for symbol %s", symbol)
+// SuperTrend calculates SuperTrend indicator
+func (sc *StrategyContext) SuperTrend(symbol string, period int, multiplier float64) (float64, error) {
+	if err := validatePeriod("SuperTrend", period); err != nil {
+		return 0, err
+	}
+	if multiplier <= 0 {
+		return 0, fmt.Errorf("invalid SuperTrend multiplier %f: must be positive", multiplier)
 	}
 
-	gains := data.RSIData.Gains
-	losses := data.RSIData.Losses
+	spec := strategy.NewIndicatorSpec("supertrend", float64(period), multiplier)
+	indicator, err := sc.indicator(symbol, "SuperTrend", spec)
+	if err != nil {
+		return 0, err
+	}
+	return indicator.Value(), nil
+}
 
-	if len(gains) < period || len(losses) < period {
-		return 0, fmt.Errorf("insufficient data for RSI: need %d periods, have %d", period, len(gains))
+// ParbolicSAR calculates Parabolic SAR
+func (sc *StrategyContext) ParbolicSAR(symbol string, step, max float64) (float64, error) {
+	if step <= 0 || max < step {
+		return 0, fmt.Errorf("invalid Parabolic SAR parameters: step %f, max %f", step, max)
 	}
 
-	// Calculate average gain and average loss for the period
-	avgGain := 0.0
-	avgLoss := 0.0
+	indicator, err := sc.indicator(symbol, "Parabolic SAR", strategy.NewIndicatorSpec("psar", step, max))
+	if err != nil {
+		return 0, err
+	}
+	return indicator.Value(), nil
+}
 
-	// Use the last 'period' values
-	start := len(gains) - period
-	for i := start; i < len(gains); i++ {
-		avgGain += gains[i]
-		avgLoss += losses[i]
+// ATR calculates Average True Range
+func (sc *StrategyContext) ATR(symbol string, period int) (float64, error) {
+	if err := validatePeriod("ATR", period); err != nil {
+		return 0, err
 	}
 
-	avgGain /= float64(period)
-	avgLoss /= float64(period)
+	indicator, err := sc.indicator(symbol, "ATR", strategy.NewIndicatorSpec("atr", float64(period)))
+	if err != nil {
+		return 0, err
+	}
+	return indicator.Value(), nil
+}
 
-	// Avoid division by zero
-	if avgLoss == 0 {
-		return 100, nil // RSI = 100 when there are no losses
+// StdDev calculates the population standard deviation of closing prices
+func (sc *StrategyContext) StdDev(symbol string, period int) (float64, error) {
+	if err := validatePeriod("StdDev", period); err != nil {
+		return 0, err
 	}
 
-	// Calculate RSI
-	rs := avgGain / avgLoss
-	rsi := 100 - (100 / (1 + rs))
+	indicator, err := sc.indicator(symbol, "StdDev", strategy.NewIndicatorSpec("stddev", float64(period)))
+	if err != nil {
+		return 0, err
+	}
+	return indicator.Value(), nil
+}
+
+// BollingerBands calculates Bollinger Bands, returning the upper, middle and lower bands, %B and bandwidth
+func (sc *StrategyContext) BollingerBands(symbol string, period int, multiplier float64) (float64, float64, float64, float64, float64, error) {
+	if err := validatePeriod("Bollinger Bands", period); err != nil {
+		return 0, 0, 0, 0, 0, err
+	}
 
-	return rsi, nil
+	indicator, err := sc.indicator(symbol, "Bollinger Bands", strategy.NewIndicatorSpec("bollinger", float64(period), multiplier))
+	if err != nil {
+		return 0, 0, 0, 0, 0, err
+	}
+
+	bands := indicator.(*indicators.BollingerBands)
+	return bands.Upper(), bands.Value(), bands.Lower(), bands.PercentB(), bands.Bandwidth(), nil
 }
 
-// MACD calculates Moving Average Convergence Divergence
-func (sc *StrategyContext) MACD(symbol string, fastPeriod, slowPeriod, signalPeriod int) (float64, float64, float64, error) {
-	// Get fast and slow EMAs
-	fastEMA, err := sc.EMA(symbol, fastPeriod)
+// KeltnerChannels calculates Keltner Channels, returning the upper, middle and lower lines
+func (sc *StrategyContext) KeltnerChannels(symbol string, emaPeriod, atrPeriod int, multiplier float64) (float64, float64, float64, error) {
+	for _, period := range []int{emaPeriod, atrPeriod} {
+		if err := validatePeriod("Keltner Channels", period); err != nil {
+			return 0, 0, 0, err
+		}
+	}
+
+	indicator, err := sc.indicator(symbol, "Keltner Channels", strategy.NewIndicatorSpec("keltner", float64(emaPeriod), float64(atrPeriod), multiplier))
 	if err != nil {
-		return 0, 0, 0, fmt.Errorf("error calculating fast EMA: %v", err)
+		return 0, 0, 0, err
 	}
 
-	slowEMA, err := sc.EMA(symbol, slowPeriod)
+	channels := indicator.(*indicators.KeltnerChannels)
+	return channels.Upper(), channels.Value(), channels.Lower(), nil
+}
+
+// DonchianChannels calculates Donchian Channels, returning the upper, middle and lower lines
+func (sc *StrategyContext) DonchianChannels(symbol string, period int) (float64, float64, float64, error) {
+	if err := validatePeriod("Donchian Channels", period); err != nil {
+		return 0, 0, 0, err
+	}
+
+	indicator, err := sc.indicator(symbol, "Donchian Channels", strategy.NewIndicatorSpec("donchian", float64(period)))
 	if err != nil {
-		return 0, 0, 0, fmt.Errorf("error calculating slow EMA: %v", err)
+		return 0, 0, 0, err
 	}
 
-	// MACD line = Fast EMA - Slow EMA
-	macdLine := fastEMA - slowEMA
+	channels := indicator.(*indicators.DonchianChannels)
+	return channels.Upper(), channels.Value(), channels.Lower(), nil
+}
 
-	data, exists := sc.indicators[symbol]
-	if !exists {
-		return 0, 0, 0, fmt.Errorf("no indicator data available for symbol %s", symbol)
+// VWAP calculates the volume-weighted average price since the start of the current session
+func (sc *StrategyContext) VWAP(symbol string) (float64, error) {
+	indicator, err := sc.indicator(symbol, "VWAP", strategy.NewIndicatorSpec("vwap"))
+	if err != nil {
+		return 0, err
 	}
+	return indicator.Value(), nil
+}
 
-	// Update MACD data
-	data.MACDData.FastEMA = fastEMA
-	data.MACDData.SlowEMA = slowEMA
+// RollingVWAP calculates the volume-weighted average price over the last period bars
+func (sc *StrategyContext) RollingVWAP(symbol string, period int) (float64, error) {
+	if err := validatePeriod("VWAP", period); err != nil {
+		return 0, err
+	}
 
-	// Calculate Signal line (EMA of MACD line)
-	// For simplicity, we'll use a basic signal calculation
-	// In a full implementation, we'd maintain a history of MACD values
-	// and calculate the EMA of those values
-	signalLine := data.MACDData.SignalEMA
-	if signalLine == 0 {
-		// Initialize signal line with first MACD value
-		signalLine = macdLine
-	} else {
-		// Update signal line using EMA formula
-		multiplier := 2.0 / (float64(signalPeriod) + 1.0)
-		signalLine = (macdLine * multiplier) + (signalLine * (1.0 - multiplier))
+	indicator, err := sc.indicator(symbol, "VWAP", strategy.NewIndicatorSpec("rolling_vwap", float64(period)))
+	if err != nil {
+		return 0, err
+	}
+	return indicator.Value(), nil
+}
+
+// OBV calculates On-Balance Volume over the available history
+func (sc *StrategyContext) OBV(symbol string) (float64, error) {
+	indicator, err := sc.indicator(symbol, "OBV", strategy.NewIndicatorSpec("obv"))
+	if err != nil {
+		return 0, err
 	}
-	data.MACDData.SignalEMA = signalLine
+	return indicator.Value(), nil
+}
 
-	// Histogram = MACD Line - Signal Line
-	histogram := macdLine - signalLine
+// MFI calculates Money Flow Index
+func (sc *StrategyContext) MFI(symbol string, period int) (float64, error) {
+	if err := validatePeriod("MFI", period); err != nil {
+		return 0, err
+	}
 
-	return macdLine, signalLine, histogram, nil
+	indicator, err := sc.indicator(symbol, "MFI", strategy.NewIndicatorSpec("mfi", float64(period)))
+	if err != nil {
+		return 0, err
+	}
+	return indicator.Value(), nil
 }
 
-// ADX calculates Average Directional Index
-func (sc *StrategyContext) ADX(symbol string, period int) (float64, error) {
-	data, exists := sc.indicators[symbol]
-	if !exists || data.ADXData == nil {
-		return 0, fmt.Errorf("no ADX data available for symbol %s", symbol)
+// AccumulationDistribution calculates the Accumulation/Distribution line over the available history
+func (sc *StrategyContext) AccumulationDistribution(symbol string) (float64, error) {
+	indicator, err := sc.indicator(symbol, "Accumulation/Distribution", strategy.NewIndicatorSpec("ad"))
+	if err != nil {
+		return 0, err
 	}
+	return indicator.Value(), nil
+}
 
-	trueRanges := data.ADXData.TrueRanges
-	dmPlus := data.ADXData.DMPlus
-	dmMinus := data.ADXData.DMMinus
+// ChaikinMoneyFlow calculates Chaikin Money Flow
+func (sc *StrategyContext) ChaikinMoneyFlow(symbol string, period int) (float64, error) {
+	if err := validatePeriod("Chaikin Money Flow", period); err != nil {
+		return 0, err
+	}
 
-	if len(trueRanges) < period || len(dmPlus) < period || len(dmMinus) < period {
-		return 0, fmt.Errorf("insufficient data for ADX: need %d periods, have %d", period, len(trueRanges))
+	indicator, err := sc.indicator(symbol, "Chaikin Money Flow", strategy.NewIndicatorSpec("cmf", float64(period)))
+	if err != nil {
+		return 0, err
 	}
+	return indicator.Value(), nil
+}
 
-	// Calculate ATR (Average True Range)
-	atr := 0.0
-	start := len(trueRanges) - period
-	for i := start; i < len(trueRanges); i++ {
-		atr += trueRanges[i]
+// VolumeProfile calculates a volume-at-price profile over the last period bars, returning
+// the point of control and the top and bottom of the value area
+func (sc *StrategyContext) VolumeProfile(symbol string, period, bins int, valueArea float64) (float64, float64, float64, error) {
+	if err := validatePeriod("volume profile", period); err != nil {
+		return 0, 0, 0, err
+	}
+	if bins <= 0 || valueArea <= 0 || valueArea > 1 {
+		return 0, 0, 0, fmt.Errorf("invalid volume profile parameters: bins %d, value area %f", bins, valueArea)
 	}
-	atr /= float64(period)
 
-	if atr == 0 {
-		return 0, nil // Avoid division by zero
+	indicator, err := sc.indicator(symbol, "volume profile", strategy.NewIndicatorSpec("volume_profile", float64(period), float64(bins), valueArea))
+	if err != nil {
+		return 0, 0, 0, err
 	}
 
-	// Calculate DI+ and DI-
-	diPlus := 0.0
-	diMinus := 0.0
-	for i := start; i < len(dmPlus); i++ {
-		diPlus += dmPlus[i]
-		diMinus += dmMinus[i]
+	profile := indicator.(*indicators.VolumeProfile)
+	return profile.Value(), profile.ValueAreaHigh(), profile.ValueAreaLow(), nil
+}
+
+// Stochastic calculates the full stochastic oscillator, returning %K and %D.
+// A kSmoothing of 1 gives the fast stochastic and 3 the slow stochastic.
+func (sc *StrategyContext) Stochastic(symbol string, kPeriod, kSmoothing, dPeriod int) (float64, float64, error) {
+	for _, period := range []int{kPeriod, kSmoothing, dPeriod} {
+		if err := validatePeriod("Stochastic", period); err != nil {
+			return 0, 0, err
+		}
 	}
-	diPlus = (diPlus / float64(period)) / atr * 100
-	diMinus = (diMinus / float64(period)) / atr * 100
 
-	// Calculate DX
-	if diPlus+diMinus == 0 {
-		return 0, nil
+	indicator, err := sc.indicator(symbol, "Stochastic", strategy.NewIndicatorSpec("stochastic", float64(kPeriod), float64(kSmoothing), float64(dPeriod)))
+	if err != nil {
+		return 0, 0, err
 	}
-	dx := math.Abs(diPlus-diMinus) / (diPlus + diMinus) * 100
 
-	// For simplicity, return DX as ADX approximation
-	// In a full implementation, ADX would be the smoothed average of DX values
-	return dx, nil
+	stochastic := indicator.(*indicators.Stochastic)
+	return stochastic.Value(), stochastic.D(), nil
 }
 
-// SuperTrend calculates SuperTrend indicator
-func (sc *StrategyContext) SuperTrend(symbol string, period int, multiplier float64) (float64, error) {
-	data, exists := sc.indicators[symbol]
-	if !exists || data.HighHistory == nil || data.LowHistory == nil {
-		return 0, fmt.Errorf("no price history available for symbol %s", symbol)
+// StochasticRSI calculates the Stochastic RSI, returning %K and %D
+func (sc *StrategyContext) StochasticRSI(symbol string, rsiPeriod, stochPeriod, kSmoothing, dPeriod int) (float64, float64, error) {
+	for _, period := range []int{rsiPeriod, stochPeriod, kSmoothing, dPeriod} {
+		if err := validatePeriod("Stochastic RSI", period); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	indicator, err := sc.indicator(symbol, "Stochastic RSI", strategy.NewIndicatorSpec("stoch_rsi", float64(rsiPeriod), float64(stochPeriod), float64(kSmoothing), float64(dPeriod)))
+	if err != nil {
+		return 0, 0, err
+	}
+
+	stochRSI := indicator.(*indicators.StochasticRSI)
+	return stochRSI.Value(), stochRSI.D(), nil
+}
+
+// WilliamsR calculates Williams %R
+func (sc *StrategyContext) WilliamsR(symbol string, period int) (float64, error) {
+	if err := validatePeriod("Williams %R", period); err != nil {
+		return 0, err
 	}
 
-	if len(data.HighHistory) < period || len(data.LowHistory) < period || len(data.PriceHistory) < period {
-		return 0, fmt.Errorf("insufficient data for SuperTrend: need %d periods, have %d", period, len(data.PriceHistory))
+	indicator, err := sc.indicator(symbol, "Williams %R", strategy.NewIndicatorSpec("williams_r", float64(period)))
+	if err != nil {
+		return 0, err
+	}
+	return indicator.Value(), nil
+}
+
+// CCI calculates Commodity Channel Index
+func (sc *StrategyContext) CCI(symbol string, period int) (float64, error) {
+	if err := validatePeriod("CCI", period); err != nil {
+		return 0, err
+	}
+
+	indicator, err := sc.indicator(symbol, "CCI", strategy.NewIndicatorSpec("cci", float64(period)))
+	if err != nil {
+		return 0, err
 	}
+	return indicator.Value(), nil
+}
+
+// ROC calculates Rate of Change as a percentage
+func (sc *StrategyContext) ROC(symbol string, period int) (float64, error) {
+	if err := validatePeriod("ROC", period); err != nil {
+		return 0, err
+	}
+
+	indicator, err := sc.indicator(symbol, "ROC", strategy.NewIndicatorSpec("roc", float64(period)))
+	if err != nil {
+		return 0, err
+	}
+	return indicator.Value(), nil
+}
+
+// Aroon calculates Aroon Up, Aroon Down and the Aroon oscillator
+func (sc *StrategyContext) Aroon(symbol string, period int) (float64, float64, float64, error) {
+	if err := validatePeriod("Aroon", period); err != nil {
+		return 0, 0, 0, err
+	}
+
+	indicator, err := sc.indicator(symbol, "Aroon", strategy.NewIndicatorSpec("aroon", float64(period)))
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	aroon := indicator.(*indicators.Aroon)
+	return aroon.Up(), aroon.Down(), aroon.Value(), nil
+}
 
-	// Calculate ATR for the period
-	atr := 0.0
-	if len(data.ADXData.TrueRanges) >= period {
-		start := len(data.ADXData.TrueRanges) - period
-		for i := start; i < len(data.ADXData.TrueRanges); i++ {
-			atr += data.ADXData.TrueRanges[i]
+// Ichimoku calculates the Ichimoku cloud, returning the conversion line, base line,
+// the leading spans of the cloud under the current bar and the lagging span
+func (sc *StrategyContext) Ichimoku(symbol string, tenkanPeriod, kijunPeriod, senkouBPeriod, displacement int) (float64, float64, float64, float64, float64, error) {
+	for _, period := range []int{tenkanPeriod, kijunPeriod, senkouBPeriod, displacement} {
+		if err := validatePeriod("Ichimoku", period); err != nil {
+			return 0, 0, 0, 0, 0, err
 		}
-		atr /= float64(period)
-	} else {
-		// Fallback: simple range calculation
-		start := len(data.HighHistory) - period
-		for i := start; i < len(data.HighHistory); i++ {
-			atr += data.HighHistory[i] - data.LowHistory[i]
+	}
+
+	indicator, err := sc.indicator(symbol, "Ichimoku", strategy.NewIndicatorSpec("ichimoku", float64(tenkanPeriod), float64(kijunPeriod), float64(senkouBPeriod), float64(displacement)))
+	if err != nil {
+		return 0, 0, 0, 0, 0, err
+	}
+
+	ichimoku := indicator.(*indicators.Ichimoku)
+	return ichimoku.Tenkan(), ichimoku.Kijun(), ichimoku.SenkouA(), ichimoku.SenkouB(), ichimoku.Chikou(), nil
+}
+
+// alignedCloses returns the closes of two symbols at the timestamps they share in their stored history, oldest first
+func (sc *StrategyContext) alignedCloses(symbol, other string) ([]float64, []float64) {
+	otherCloses := make(map[int64]float64, len(sc.indicators[other].Bars))
+	for _, bar := range sc.indicators[other].Bars {
+		otherCloses[bar.Timestamp.UnixNano()] = bar.Close
+	}
+
+	var closes, aligned []float64
+	for _, bar := range sc.indicators[symbol].Bars {
+		if otherClose, ok := otherCloses[bar.Timestamp.UnixNano()]; ok {
+			closes = append(closes, bar.Close)
+			aligned = append(aligned, otherClose)
+		}
+	}
+	return closes, aligned
+}
+
+// simpleReturn returns the change from previous to current as a fraction (0 if previous is 0)
+func simpleReturn(previous, current float64) float64 {
+	if previous == 0 {
+		return 0
+	}
+	return current/previous - 1
+}
+
+// pairIndicator returns the registered pair indicator of a symbol against another symbol,
+// creating it and seeding it from the closes they share in the stored history on first use.
+// Registered pair indicators are fed once per data point, so requests cost O(1).
+func (sc *StrategyContext) pairIndicator(name, symbol, other string, spec strategy.IndicatorSpec, returns bool, create func() indicators.PairIndicator) (indicators.PairIndicator, error) {
+	if symbol == other {
+		return nil, fmt.Errorf("cross-asset indicators need two different symbols, got %s twice", symbol)
+	}
+	for _, s := range []string{symbol, other} {
+		if data, exists := sc.indicators[s]; !exists || len(data.Bars) == 0 {
+			return nil, fmt.Errorf("no price history available for symbol %s", s)
 		}
-		atr /= float64(period)
 	}
 
-	// Calculate HL2 (median price)
-	currentHigh := data.HighHistory[len(data.HighHistory)-1]
-	currentLow := data.LowHistory[len(data.LowHistory)-1]
-	hl2 := (currentHigh + currentLow) / 2
+	key := pairKey{symbol: symbol, other: other, spec: spec.String()}
+	tracked := sc.registry.getPair(key, returns, func() ([]float64, []float64) {
+		return sc.alignedCloses(symbol, other)
+	}, create)
+	if !tracked.indicator.Ready() {
+		return nil, fmt.Errorf("insufficient data for %s: need %d aligned periods, have %d", name, tracked.indicator.WarmupPeriod(), tracked.pairs)
+	}
+	return tracked.indicator, nil
+}
+
+// Correlation calculates the rolling correlation of two symbols' returns
+func (sc *StrategyContext) Correlation(symbol, other string, period int) (float64, error) {
+	if err := validatePeriod("Correlation", period); err != nil {
+		return 0, err
+	}
 
-	// Calculate SuperTrend
-	upperBand := hl2 + (multiplier * atr)
-	lowerBand := hl2 - (multiplier * atr)
+	correlation, err := sc.pairIndicator("Correlation", symbol, other, strategy.NewIndicatorSpec("correlation", float64(period)), true, func() indicators.PairIndicator {
+		return indicators.NewCorrelation(period)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return correlation.Value(), nil
+}
 
-	currentClose := data.PriceHistory[len(data.PriceHistory)-1]
+// Beta calculates the rolling beta of a symbol's returns against a benchmark's returns
+func (sc *StrategyContext) Beta(symbol, benchmark string, period int) (float64, error) {
+	if err := validatePeriod("Beta", period); err != nil {
+		return 0, err
+	}
 
-	// Simple SuperTrend logic: return lower band if price is above, upper band if below
-	if currentClose > hl2 {
-		return lowerBand, nil
-	} else {
-		return upperBand, nil
+	beta, err := sc.pairIndicator("Beta", symbol, benchmark, strategy.NewIndicatorSpec("beta", float64(period)), true, func() indicators.PairIndicator {
+		return indicators.NewBeta(period)
+	})
+	if err != nil {
+		return 0, err
 	}
+	return beta.Value(), nil
 }
 
-// ParabolicSAR calculates Parabolic SAR
-func (sc *StrategyContext) ParbolicSAR(symbol string, step, max float64) (float64, error) {
+// hedgeRatio returns the rolling regression of a symbol's closes on another symbol's closes.
+// HedgeRatio, SpreadZScore and Cointegration share one regression per pair and period.
+func (sc *StrategyContext) hedgeRatio(name, symbol, other string, period int, minPeriod int) (*indicators.HedgeRatio, error) {
+	if period < minPeriod {
+		return nil, fmt.Errorf("invalid %s period %d: must be at least %d", name, period, minPeriod)
+	}
+
+	hedgeRatio, err := sc.pairIndicator(name, symbol, other, strategy.NewIndicatorSpec("hedge_ratio", float64(period)), false, func() indicators.PairIndicator {
+		return indicators.NewHedgeRatio(period)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return hedgeRatio.(*indicators.HedgeRatio), nil
+}
+
+// HedgeRatio calculates the rolling OLS hedge ratio and intercept of a symbol against another symbol
+func (sc *StrategyContext) HedgeRatio(symbol, other string, period int) (float64, float64, error) {
+	hedgeRatio, err := sc.hedgeRatio("Hedge Ratio", symbol, other, period, 2)
+	if err != nil {
+		return 0, 0, err
+	}
+	return hedgeRatio.Value(), hedgeRatio.Intercept(), nil
+}
+
+// SpreadZScore calculates the z-score of the spread between a symbol and its hedge against another symbol
+func (sc *StrategyContext) SpreadZScore(symbol, other string, period int) (float64, error) {
+	hedgeRatio, err := sc.hedgeRatio("Spread Z-Score", symbol, other, period, 2)
+	if err != nil {
+		return 0, err
+	}
+	return hedgeRatio.SpreadZScore(), nil
+}
+
+// Cointegration calculates the Engle-Granger statistic of the spread between two symbols
+func (sc *StrategyContext) Cointegration(symbol, other string, period int) (float64, error) {
+	hedgeRatio, err := sc.hedgeRatio("Cointegration", symbol, other, period, 3)
+	if err != nil {
+		return 0, err
+	}
+	return hedgeRatio.CointegrationStatistic(), nil
+}
+
+// CrossSectionalRank ranks an indicator's value for a symbol against the other symbols in the
+// current data point. Symbols without enough history for the indicator are left out. The
+// values of the current symbols are collected and sorted once per data point and spec.
+func (sc *StrategyContext) CrossSectionalRank(symbol string, spec strategy.IndicatorSpec) (int, float64, error) {
+	value, err := sc.Indicator(symbol, spec)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	name := spec.String()
+	values, cached := sc.ranks[name]
+	if !cached {
+		values = make([]float64, 0, len(sc.symbols))
+		for _, peer := range sc.symbols {
+			if peerValue, err := sc.Indicator(peer, spec); err == nil {
+				values = append(values, peerValue)
+			}
+		}
+		sort.Float64s(values)
+		sc.ranks[name] = values
+	}
+
+	// The symbol's own value is among them only if it is in the current data point
+	count := len(values)
+	if i := sort.SearchStrings(sc.symbols, symbol); i == len(sc.symbols) || sc.symbols[i] != symbol {
+		count++
+	}
+	below := sort.SearchFloat64s(values, value)
+	above := len(values) - sort.Search(len(values), func(i int) bool { return values[i] > value })
+
+	percentile := 100.0
+	if count > 1 {
+		percentile = 100 * float64(below) / float64(count-1)
+	}
+	return above + 1, percentile, nil
+}
+
+// CandlestickPatterns detects the candlestick patterns completed by the latest bar, strongest first
+func (sc *StrategyContext) CandlestickPatterns(symbol string) ([]strategy.PatternSignal, error) {
 	data, exists := sc.indicators[symbol]
-	if !exists || data.HighHistory == nil || data.LowHistory == nil {
-		return 0, fmt.Errorf("no price history available for symbol %s", symbol)
+	if !exists || len(data.Bars) == 0 {
+		return nil, fmt.Errorf("no price history available for symbol %s", symbol)
+	}
+	return patterns.Detect(data.Bars), nil
+}
+
+// GetBars returns the most recent count bars for a symbol, oldest first
+func (sc *StrategyContext) GetBars(symbol string, count int) ([]strategy.BarData, error) {
+	if count <= 0 {
+		return nil, fmt.Errorf("invalid bar count %d: must be positive", count)
+	}
+
+	data, exists := sc.indicators[symbol]
+	if !exists || len(data.Bars) == 0 {
+		return nil, fmt.Errorf("no price history available for symbol %s", symbol)
+	}
+	if count > len(data.Bars) {
+		return nil, fmt.Errorf("insufficient history for %s: need %d bars, have %d (max lookback %d)", symbol, count, len(data.Bars), sc.maxLookback)
 	}
 
-	highs := data.HighHistory
-	lows := data.LowHistory
+	bars := make([]strategy.BarData, count)
+	copy(bars, data.Bars[len(data.Bars)-count:])
+	return bars, nil
+}
+
+// Indicator calculates the current value of any indicator described by spec
+func (sc *StrategyContext) Indicator(symbol string, spec strategy.IndicatorSpec) (float64, error) {
+	indicator, err := sc.indicator(symbol, spec.String(), spec)
+	if err != nil {
+		return 0, err
+	}
+	return indicators.OutputValue(indicator, spec.Output)
+}
+
+// IndicatorHistory returns the last count values of any indicator described by spec, oldest
+// first. Values are recorded by the registered indicator as bars arrive, so the last one
+// always matches Indicator and up to the max lookback values are kept.
+func (sc *StrategyContext) IndicatorHistory(symbol string, spec strategy.IndicatorSpec, count int) ([]float64, error) {
+	if count <= 0 {
+		return nil, fmt.Errorf("invalid history count %d: must be positive", count)
+	}
 
-	if len(highs) < 2 || len(lows) < 2 {
-		return 0, fmt.Errorf("insufficient data for Parabolic SAR: need at least 2 periods")
+	tracked, err := sc.trackedIndicator(symbol, spec)
+	if err != nil {
+		return nil, err
 	}
 
-	// Simplified Parabolic SAR calculation
-	// In a full implementation, this would maintain state across calls
-	currentHigh := highs[len(highs)-1]
-	prevHigh := highs[len(highs)-2]
-	prevLow := lows[len(lows)-2]
+	history := tracked.history(spec.Output)
+	if history == nil {
+		_, err := indicators.OutputValue(tracked.indicator, spec.Output)
+		return nil, err
+	}
 
-	// Simple approximation: use previous low as SAR for uptrend
-	if currentHigh > prevHigh {
-		return prevLow, nil
-	} else {
-		return prevHigh, nil
+	if history.Len() < count {
+		needed := tracked.indicator.WarmupPeriod() + count - 1
+		return nil, fmt.Errorf("insufficient data for %s: need %d periods, have %d (max lookback %d)", spec, needed, tracked.bars, sc.maxLookback)
 	}
+	values := make([]float64, count)
+	offset := history.Len() - count
+	for i := range values {
+		values[i] = history.At(offset + i)
+	}
+	return values, nil
 }
 
 // Log logs a message with the given level and fields
@@ -444,43 +755,3 @@ func (sc *StrategyContext) Log(level string, message string, fields map[string]i
 
 	event.Msg(message)
 }
-
-// updateADXData updates the ADX calculation data
-func (sc *StrategyContext) updateADXData(data *IndicatorData, high, low, close float64) {
-	if data.ADXData.PrevClose == 0 {
-		// First data point, just store values
-		data.ADXData.PrevHigh = high
-		data.ADXData.PrevLow = low
-		data.ADXData.PrevClose = close
-		return
-	}
-
-	// Calculate True Range
-	tr1 := high - low
-	tr2 := math.Abs(high - data.ADXData.PrevClose)
-	tr3 := math.Abs(low - data.ADXData.PrevClose)
-	tr := math.Max(tr1, math.Max(tr2, tr3))
-	data.ADXData.TrueRanges = append(data.ADXData.TrueRanges, tr)
-
-	// Calculate Directional Movements
-	dmPlus := 0.0
-	dmMinus := 0.0
-
-	if high-data.ADXData.PrevHigh > data.ADXData.PrevLow-low {
-		if high-data.ADXData.PrevHigh > 0 {
-			dmPlus = high - data.ADXData.PrevHigh
-		}
-	} else {
-		if data.ADXData.PrevLow-low > 0 {
-			dmMinus = data.ADXData.PrevLow - low
-		}
-	}
-
-	data.ADXData.DMPlus = append(data.ADXData.DMPlus, dmPlus)
-	data.ADXData.DMMinus = append(data.ADXData.DMMinus, dmMinus)
-
-	// Update previous values
-	data.ADXData.PrevHigh = high
-	data.ADXData.PrevLow = low
-	data.ADXData.PrevClose = close
-}
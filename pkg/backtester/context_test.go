@@ -0,0 +1,550 @@
+package backtester
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ridopark/JonBuhTrader/pkg/indicators"
+	"github.com/ridopark/JonBuhTrader/pkg/strategy"
+)
+
+const testSymbol = "TEST"
+
+// StockCharts ChartSchool "Moving Averages - Simple and Exponential" 10-day example
+var (
+	movingAverageCloses = []float64{
+		22.2734, 22.1940, 22.0847, 22.1741, 22.1840, 22.1344, 22.2337, 22.4323, 22.2436, 22.2933,
+		22.1542, 22.3926, 22.3816, 22.6109, 23.3558, 24.0519, 23.7530, 23.8324, 23.9516, 23.6338,
+		23.8225, 23.8722, 23.6537, 23.1870, 23.0976, 23.3260, 22.6805, 23.0976, 22.4025, 22.1725,
+	}
+	publishedSMA10 = []float64{
+		22.22, 22.21, 22.23, 22.26, 22.31, 22.42, 22.61, 22.77, 22.91, 23.08,
+		23.21, 23.38, 23.53, 23.65, 23.71, 23.69, 23.61, 23.51, 23.43, 23.28, 23.13,
+	}
+	publishedEMA10 = []float64{
+		22.22, 22.21, 22.24, 22.27, 22.33, 22.52, 22.80, 22.97, 23.13, 23.28,
+		23.34, 23.43, 23.51, 23.54, 23.47, 23.40, 23.39, 23.26, 23.23, 23.08, 22.92,
+	}
+)
+
+// StockCharts ChartSchool "Relative Strength Index" 14-day example
+var (
+	rsiCloses = []float64{
+		44.3389, 44.0902, 44.1497, 43.6124, 44.3278, 44.8264, 45.0955, 45.4245, 45.8433, 46.0826,
+		45.8931, 46.0328, 45.6140, 46.2820, 46.2820, 46.0028, 46.0328, 46.4116, 46.2222, 45.6439,
+		46.2122, 46.2521, 45.7137, 46.4515, 45.7835, 45.3548, 44.0288, 44.1783, 44.2181, 44.5672,
+		43.4205, 42.6628, 43.1314,
+	}
+	publishedRSI14 = []float64{
+		70.53, 66.32, 66.55, 69.41, 66.36, 57.97, 62.93, 63.26, 56.06, 62.38,
+		54.71, 50.42, 39.99, 41.46, 41.87, 45.46, 37.30, 33.08, 37.77,
+	}
+)
+
+// newTestContext creates a strategy context with no history
+func newTestContext() *StrategyContext {
+	return NewStrategyContext(&Engine{sessionLocation: time.UTC})
+}
+
+// closeBars creates bars whose open, high and low equal the close
+func closeBars(closes []float64) []strategy.BarData {
+	bars := make([]strategy.BarData, len(closes))
+	for i, price := range closes {
+		bars[i] = strategy.BarData{Open: price, High: price, Low: price, Close: price, Volume: 1000}
+	}
+	return bars
+}
+
+// syntheticBars creates a deterministic trending and oscillating OHLC series
+func syntheticBars(count int) []strategy.BarData {
+	bars := make([]strategy.BarData, count)
+	price := 100.0
+	for i := range bars {
+		open := price
+		price += 1.5*math.Sin(float64(i)/4) + 0.3*math.Cos(float64(i)*1.7)
+		bars[i] = strategy.BarData{
+			Open:   open,
+			High:   math.Max(open, price) + 0.4 + 0.2*math.Abs(math.Sin(float64(i))),
+			Low:    math.Min(open, price) - 0.4 - 0.2*math.Abs(math.Cos(float64(i))),
+			Close:  price,
+			Volume: 1000,
+		}
+	}
+	return bars
+}
+
+// addBar adds a bar to the context history
+func addBar(ctx *StrategyContext, index int, bar strategy.BarData) {
+	timestamp := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC).Add(time.Duration(index) * time.Hour)
+	bar.Symbol = testSymbol
+	bar.Timestamp = timestamp
+	ctx.UpdatePriceHistory(strategy.DataPoint{
+		Timestamp: timestamp,
+		Bars:      map[string]strategy.BarData{testSymbol: bar},
+	})
+}
+
+// series feeds bars one at a time and collects the indicator value once it is available
+func series(t *testing.T, bars []strategy.BarData, warmup int, value func(ctx *StrategyContext) (float64, error)) []float64 {
+	t.Helper()
+	ctx := newTestContext()
+	var values []float64
+	for i, bar := range bars {
+		addBar(ctx, i, bar)
+		v, err := value(ctx)
+		if i+1 < warmup {
+			if err == nil {
+				t.Fatalf("bar %d: expected insufficient data error before warm-up of %d bars", i+1, warmup)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("bar %d: unexpected error: %v", i+1, err)
+		}
+		values = append(values, v)
+	}
+	return values
+}
+
+// assertSeries compares values against expected values within tolerance
+func assertSeries(t *testing.T, name string, got, want []float64, tolerance float64) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("%s: got %d values, want %d", name, len(got), len(want))
+	}
+	for i := range want {
+		if math.Abs(got[i]-want[i]) > tolerance {
+			t.Errorf("%s[%d] = %.4f, want %.4f (tolerance %g)", name, i, got[i], want[i], tolerance)
+		}
+	}
+}
+
+func TestSMAMatchesPublishedValues(t *testing.T) {
+	got := series(t, closeBars(movingAverageCloses), 10, func(ctx *StrategyContext) (float64, error) {
+		return ctx.SMA(testSymbol, 10)
+	})
+	// Published values are rounded to the cent
+	assertSeries(t, "SMA(10)", got, publishedSMA10, 0.0051+1e-9)
+}
+
+func TestEMAMatchesPublishedValues(t *testing.T) {
+	got := series(t, closeBars(movingAverageCloses), 10, func(ctx *StrategyContext) (float64, error) {
+		return ctx.EMA(testSymbol, 10)
+	})
+	// The EMA is seeded with the SMA of the first 10 closes; published values are rounded to the cent
+	assertSeries(t, "EMA(10)", got, publishedEMA10, 0.0051+1e-9)
+}
+
+func TestRSIMatchesPublishedValues(t *testing.T) {
+	got := series(t, closeBars(rsiCloses), 15, func(ctx *StrategyContext) (float64, error) {
+		return ctx.RSI(testSymbol, 14)
+	})
+	// Wilder smoothing seeded with the simple average of the first 14 changes;
+	// published values are rounded to two decimals
+	assertSeries(t, "RSI(14)", got, publishedRSI14, 0.0051+1e-9)
+}
+
+func TestRSIEdgeCases(t *testing.T) {
+	tests := []struct {
+		name   string
+		closes []float64
+		want   float64
+	}{
+		{"only gains", []float64{1, 2, 3, 4, 5, 6}, 100},
+		{"only losses", []float64{6, 5, 4, 3, 2, 1}, 0},
+		{"no movement", []float64{5, 5, 5, 5, 5, 5}, 50},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := newTestContext()
+			for i, bar := range closeBars(tt.closes) {
+				addBar(ctx, i, bar)
+			}
+			got, err := ctx.RSI(testSymbol, 5)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("RSI = %f, want %f", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMACDIsNotLimitedByLookback(t *testing.T) {
+	// With only 40 bars of history, a replayed MACD would be reseeded on every bar;
+	// the persistent series must keep matching a MACD fed the full history
+	reference := indicators.NewMACD(12, 26, 9)
+	ctx := newTestContext()
+	ctx.maxLookback = 40
+	for i, bar := range syntheticBars(150) {
+		reference.Update(bar)
+		addBar(ctx, i, bar)
+		macd, signal, histogram, err := ctx.MACD(testSymbol, 12, 26, 9)
+		if !reference.Ready() {
+			if err == nil {
+				t.Fatalf("bar %d: expected insufficient data error", i+1)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("bar %d: unexpected error: %v", i+1, err)
+		}
+		if macd != reference.Value() || signal != reference.Signal() || histogram != reference.Histogram() {
+			t.Fatalf("bar %d: MACD = (%f, %f, %f), want (%f, %f, %f)", i+1, macd, signal, histogram, reference.Value(), reference.Signal(), reference.Histogram())
+		}
+	}
+}
+
+func TestSuperTrendParameterSetsAreIndependent(t *testing.T) {
+	bars := syntheticBars(120)
+	shared := newTestContext()
+	onlyFast := newTestContext()
+	onlySlow := newTestContext()
+
+	for i, bar := range bars {
+		for _, ctx := range []*StrategyContext{shared, onlyFast, onlySlow} {
+			addBar(ctx, i, bar)
+		}
+
+		fast, fastErr := shared.SuperTrend(testSymbol, 7, 2)
+		slow, slowErr := shared.SuperTrend(testSymbol, 10, 3)
+		wantFast, wantFastErr := onlyFast.SuperTrend(testSymbol, 7, 2)
+		wantSlow, wantSlowErr := onlySlow.SuperTrend(testSymbol, 10, 3)
+
+		if (fastErr == nil) != (wantFastErr == nil) || (slowErr == nil) != (wantSlowErr == nil) {
+			t.Fatalf("bar %d: readiness differs when parameter sets share a context", i+1)
+		}
+		if fast != wantFast || slow != wantSlow {
+			t.Fatalf("bar %d: SuperTrend = (%f, %f), want (%f, %f)", i+1, fast, slow, wantFast, wantSlow)
+		}
+	}
+}
+
+func TestIndicatorErrors(t *testing.T) {
+	ctx := newTestContext()
+	for i, bar := range syntheticBars(10) {
+		addBar(ctx, i, bar)
+	}
+
+	tests := []struct {
+		name    string
+		call    func() error
+		message string
+	}{
+		{"SMA zero period", func() error { _, err := ctx.SMA(testSymbol, 0); return err }, "invalid SMA period"},
+		{"EMA negative period", func() error { _, err := ctx.EMA(testSymbol, -3); return err }, "invalid EMA period"},
+		{"RSI zero period", func() error { _, err := ctx.RSI(testSymbol, 0); return err }, "invalid RSI period"},
+		{"MACD zero period", func() error { _, _, _, err := ctx.MACD(testSymbol, 12, 0, 9); return err }, "invalid MACD period"},
+		{"ADX zero period", func() error { _, err := ctx.ADX(testSymbol, 0); return err }, "invalid ADX period"},
+		{"SuperTrend zero period", func() error { _, err := ctx.SuperTrend(testSymbol, 0, 3); return err }, "invalid SuperTrend period"},
+		{"SuperTrend zero multiplier", func() error { _, err := ctx.SuperTrend(testSymbol, 10, 0); return err }, "invalid SuperTrend multiplier"},
+		{"SAR max below step", func() error { _, err := ctx.ParbolicSAR(testSymbol, 0.02, 0.01); return err }, "invalid Parabolic SAR parameters"},
+		{"SAR zero step", func() error { _, err := ctx.ParbolicSAR(testSymbol, 0, 0.2); return err }, "invalid Parabolic SAR parameters"},
+		{"unknown symbol", func() error { _, err := ctx.SMA("MISSING", 5); return err }, "no price history available for symbol MISSING"},
+		{"SMA warm-up", func() error { _, err := ctx.SMA(testSymbol, 11); return err }, "insufficient data for SMA: need 11 periods, have 10"},
+		{"RSI warm-up", func() error { _, err := ctx.RSI(testSymbol, 10); return err }, "insufficient data for RSI: need 11 periods, have 10"},
+		{"ADX warm-up", func() error { _, err := ctx.ADX(testSymbol, 6); return err }, "insufficient data for ADX: need 12 periods, have 10"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call()
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("error %q does not contain %q", err, tt.message)
+			}
+		})
+	}
+}
+
+func TestWarmupBoundaries(t *testing.T) {
+	tests := []struct {
+		name   string
+		warmup int
+		call   func(ctx *StrategyContext) error
+	}{
+		{"SMA(5)", 5, func(ctx *StrategyContext) error { _, err := ctx.SMA(testSymbol, 5); return err }},
+		{"EMA(5)", 5, func(ctx *StrategyContext) error { _, err := ctx.EMA(testSymbol, 5); return err }},
+		{"RSI(5)", 6, func(ctx *StrategyContext) error { _, err := ctx.RSI(testSymbol, 5); return err }},
+		{"MACD(3,6,4)", 9, func(ctx *StrategyContext) error { _, _, _, err := ctx.MACD(testSymbol, 3, 6, 4); return err }},
+		{"ADX(5)", 10, func(ctx *StrategyContext) error { _, err := ctx.ADX(testSymbol, 5); return err }},
+		{"SuperTrend(5, 3)", 5, func(ctx *StrategyContext) error { _, err := ctx.SuperTrend(testSymbol, 5, 3); return err }},
+		{"ParabolicSAR", 2, func(ctx *StrategyContext) error { _, err := ctx.ParbolicSAR(testSymbol, 0.02, 0.2); return err }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := newTestContext()
+			for i, bar := range syntheticBars(tt.warmup) {
+				err := tt.call(ctx)
+				if err == nil {
+					t.Fatalf("value available after %d bars, want %d", i, tt.warmup)
+				}
+				addBar(ctx, i, bar)
+			}
+			if err := tt.call(ctx); err != nil {
+				t.Fatalf("no value after %d bars: %v", tt.warmup, err)
+			}
+		})
+	}
+}
+
+func TestMaxLookbackLimitsHistory(t *testing.T) {
+	ctx := newTestContext()
+	ctx.maxLookback = 20
+	bars := syntheticBars(50)
+	for i, bar := range bars {
+		addBar(ctx, i, bar)
+	}
+
+	if _, err := ctx.GetBars(testSymbol, 21); err == nil {
+		t.Fatal("expected an error when requesting more bars than the max lookback")
+	}
+	history, err := ctx.GetBars(testSymbol, 20)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if history[19].Close != bars[49].Close || history[0].Close != bars[30].Close {
+		t.Error("GetBars did not return the most recent bars oldest first")
+	}
+
+	// Indicators first requested now can only be seeded from the stored history
+	if _, err := ctx.SMA(testSymbol, 21); err == nil {
+		t.Error("expected SMA(21) to fail with a lookback of 20")
+	}
+
+	// The SMA only depends on its window, so it is unaffected by trimming
+	got, err := ctx.SMA(testSymbol, 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := 0.0
+	for _, bar := range bars[40:] {
+		want += bar.Close / 10
+	}
+	if math.Abs(got-want) > 1e-9 {
+		t.Errorf("SMA(10) = %f, want %f", got, want)
+	}
+}
+
+func TestIndicatorHistoryMatchesPointValues(t *testing.T) {
+	bars := closeBars(movingAverageCloses)
+	ctx := newTestContext()
+	for i, bar := range bars {
+		addBar(ctx, i, bar)
+	}
+
+	history, err := ctx.IndicatorHistory(testSymbol, strategy.NewIndicatorSpec("sma", 10), 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertSeries(t, "SMA(10) history", history, publishedSMA10[len(publishedSMA10)-5:], 0.0051+1e-9)
+
+	if _, err := ctx.IndicatorHistory(testSymbol, strategy.NewIndicatorSpec("sma", 10), 22); err == nil {
+		t.Error("expected an error when requesting more values than the history supports")
+	}
+	if _, err := ctx.Indicator(testSymbol, strategy.NewIndicatorSpec("macd", 12, 26, 9).WithOutput("bogus")); err == nil {
+		t.Error("expected an error for an unknown output")
+	}
+}
+
+func TestIndicatorHistoryMatchesIndicatorAfterTrimming(t *testing.T) {
+	ctx := newTestContext()
+	ctx.maxLookback = 40
+	spec := strategy.NewIndicatorSpec("ema", 10)
+
+	// The EMA keeps its state from the first bar, long after those bars are trimmed
+	var seen []float64
+	for i, bar := range syntheticBars(100) {
+		addBar(ctx, i, bar)
+		if value, err := ctx.Indicator(testSymbol, spec); err == nil {
+			seen = append(seen, value)
+		}
+	}
+
+	history, err := ctx.IndicatorHistory(testSymbol, spec, 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	current, _ := ctx.Indicator(testSymbol, spec)
+	if history[len(history)-1] != current {
+		t.Errorf("last history value = %v, want the current EMA %v", history[len(history)-1], current)
+	}
+	assertSeries(t, "EMA(10) history", history, seen[len(seen)-5:], 0)
+
+	// History is bounded by the max lookback
+	if _, err := ctx.IndicatorHistory(testSymbol, spec, 40); err != nil {
+		t.Errorf("unexpected error for a full lookback of history: %v", err)
+	}
+	if _, err := ctx.IndicatorHistory(testSymbol, spec, 41); err == nil {
+		t.Error("expected an error when requesting more values than the max lookback")
+	}
+}
+
+// addBars feeds one data point holding a bar for each symbol
+func addBars(ctx *StrategyContext, index int, bars map[string]strategy.BarData) {
+	timestamp := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC).Add(time.Duration(index) * time.Hour)
+	dataPoint := strategy.DataPoint{Timestamp: timestamp, Bars: make(map[string]strategy.BarData, len(bars))}
+	for symbol, bar := range bars {
+		bar.Symbol = symbol
+		bar.Timestamp = timestamp
+		dataPoint.Bars[symbol] = bar
+	}
+	ctx.UpdatePriceHistory(dataPoint)
+}
+
+func TestPairIndicatorsFollowSharedTimestamps(t *testing.T) {
+	const period = 10
+	// The standalone indicators see every shared timestamp, long after the context trims its history
+	correlation := indicators.NewCorrelation(period)
+	beta := indicators.NewBeta(period)
+	hedgeRatio := indicators.NewHedgeRatio(period)
+
+	early := newTestContext()
+	early.maxLookback = 30
+	late := newTestContext()
+	late.maxLookback = 30
+
+	var previousA, previousB float64
+	shared := 0
+	for i, bar := range syntheticBars(80) {
+		bars := map[string]strategy.BarData{"AAA": bar}
+		// BBB skips every seventh bar, so those timestamps are not shared
+		if i%7 != 3 {
+			closeB := 0.5*bar.Close + 10 + math.Cos(float64(i))
+			bars["BBB"] = strategy.BarData{Open: closeB, High: closeB, Low: closeB, Close: closeB}
+
+			if shared > 0 {
+				correlation.AddPair(closeB/previousB-1, bar.Close/previousA-1)
+				beta.AddPair(closeB/previousB-1, bar.Close/previousA-1)
+			}
+			hedgeRatio.AddPair(closeB, bar.Close)
+			previousA, previousB = bar.Close, closeB
+			shared++
+		}
+		addBars(early, i, bars)
+		addBars(late, i, bars)
+
+		// Registered from the first bar, before any history is trimmed
+		early.Correlation("AAA", "BBB", period)
+		early.Beta("AAA", "BBB", period)
+		early.HedgeRatio("AAA", "BBB", period)
+		early.SpreadZScore("AAA", "BBB", period)
+		early.Cointegration("AAA", "BBB", period)
+	}
+
+	gotCorrelation, err := early.Correlation("AAA", "BBB", period)
+	if err != nil {
+		t.Fatalf("Correlation: %v", err)
+	}
+	gotBeta, _ := early.Beta("AAA", "BBB", period)
+	gotRatio, gotIntercept, _ := early.HedgeRatio("AAA", "BBB", period)
+	gotZScore, _ := early.SpreadZScore("AAA", "BBB", period)
+	gotStatistic, _ := early.Cointegration("AAA", "BBB", period)
+	assertClose(t, "correlation", gotCorrelation, correlation.Value())
+	assertClose(t, "beta", gotBeta, beta.Value())
+	assertClose(t, "hedge ratio", gotRatio, hedgeRatio.Value())
+	assertClose(t, "intercept", gotIntercept, hedgeRatio.Intercept())
+	assertClose(t, "spread z-score", gotZScore, hedgeRatio.SpreadZScore())
+	assertClose(t, "cointegration", gotStatistic, hedgeRatio.CointegrationStatistic())
+
+	// The hedge ratio, spread z-score and cointegration share one regression
+	if got := len(early.registry.pairs); got != 3 {
+		t.Errorf("registry has %d pair indicators, want 3 (correlation, beta and hedge ratio)", got)
+	}
+	if tracked := early.registry.pairs[pairKey{symbol: "AAA", other: "BBB", spec: "correlation(10)"}]; tracked == nil || tracked.pairs != shared-1 {
+		t.Errorf("correlation should have seen the %d returns between shared timestamps, got %+v", shared-1, tracked)
+	}
+
+	// Windowed indicators registered late are seeded from the trimmed history to the same values
+	lateCorrelation, err := late.Correlation("AAA", "BBB", period)
+	if err != nil {
+		t.Fatalf("late Correlation: %v", err)
+	}
+	assertClose(t, "late correlation", lateCorrelation, gotCorrelation)
+	lateZScore, _ := late.SpreadZScore("AAA", "BBB", period)
+	assertClose(t, "late spread z-score", lateZScore, gotZScore)
+}
+
+func TestPairIndicatorErrors(t *testing.T) {
+	ctx := newTestContext()
+	for i, bar := range syntheticBars(5) {
+		addBars(ctx, i, map[string]strategy.BarData{"AAA": bar, "BBB": bar})
+	}
+
+	if _, err := ctx.Correlation("AAA", "AAA", 3); err == nil {
+		t.Error("expected an error for a symbol against itself")
+	}
+	if _, err := ctx.Beta("AAA", "ZZZ", 3); err == nil {
+		t.Error("expected an error for a symbol without history")
+	}
+	// Five shared closes give only four returns
+	if _, err := ctx.Correlation("AAA", "BBB", 5); err == nil {
+		t.Error("expected an error with fewer returns than the period")
+	}
+	if _, _, err := ctx.HedgeRatio("AAA", "BBB", 5); err != nil {
+		t.Errorf("unexpected error with five shared closes: %v", err)
+	}
+	if _, err := ctx.Cointegration("AAA", "BBB", 2); err == nil {
+		t.Error("expected an error for a cointegration period below 3")
+	}
+	if _, err := ctx.Correlation("AAA", "BBB", 0); err == nil {
+		t.Error("expected an error for a zero period")
+	}
+}
+
+func TestCrossSectionalRank(t *testing.T) {
+	ctx := newTestContext()
+	spec := strategy.NewIndicatorSpec("sma", 1)
+	point := func(closes map[string]float64) map[string]strategy.BarData {
+		bars := make(map[string]strategy.BarData, len(closes))
+		for symbol, price := range closes {
+			bars[symbol] = strategy.BarData{Open: price, High: price, Low: price, Close: price}
+		}
+		return bars
+	}
+
+	addBars(ctx, 0, point(map[string]float64{"AAA": 10, "BBB": 20, "CCC": 20, "DDD": 30}))
+	tests := []struct {
+		symbol     string
+		rank       int
+		percentile float64
+	}{
+		{"AAA", 4, 0},
+		{"BBB", 2, 100.0 / 3}, // Ties rank together
+		{"CCC", 2, 100.0 / 3},
+		{"DDD", 1, 100},
+	}
+	for _, tt := range tests {
+		rank, percentile, err := ctx.CrossSectionalRank(tt.symbol, spec)
+		if err != nil {
+			t.Fatalf("CrossSectionalRank(%s): %v", tt.symbol, err)
+		}
+		if rank != tt.rank || math.Abs(percentile-tt.percentile) > 1e-9 {
+			t.Errorf("%s rank = %d (%.2f%%), want %d (%.2f%%)", tt.symbol, rank, percentile, tt.rank, tt.percentile)
+		}
+	}
+
+	// The ranking is rebuilt for the next data point; CCC is ranked against it from its stored history
+	addBars(ctx, 1, point(map[string]float64{"AAA": 40, "BBB": 5}))
+	rank, percentile, err := ctx.CrossSectionalRank("CCC", spec)
+	if err != nil {
+		t.Fatalf("CrossSectionalRank(CCC): %v", err)
+	}
+	if rank != 2 || percentile != 50 {
+		t.Errorf("CCC rank = %d (%.2f%%), want 2 (50%%)", rank, percentile)
+	}
+	if rank, _, _ := ctx.CrossSectionalRank("AAA", spec); rank != 1 {
+		t.Errorf("AAA rank = %d, want 1", rank)
+	}
+
+	if _, _, err := ctx.CrossSectionalRank("ZZZ", spec); err == nil {
+		t.Error("expected an error for a symbol without history")
+	}
+}
@@ -0,0 +1,92 @@
+package backtester
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ridopark/JonBuhTrader/pkg/feed"
+)
+
+// DefaultBaseCurrency is the base currency used when none is configured
+const DefaultBaseCurrency = "USD"
+
+// FXRateTable stores FX rate series used to convert amounts into the base currency
+type FXRateTable struct {
+	baseCurrency string
+	series       map[string][]feed.FXRate // currency -> rates quoted in base currency, oldest first
+}
+
+// NewFXRateTable creates an empty FX rate table for the given base currency
+func NewFXRateTable(baseCurrency string) *FXRateTable {
+	return &FXRateTable{
+		baseCurrency: baseCurrency,
+		series:       make(map[string][]feed.FXRate),
+	}
+}
+
+// GetBaseCurrency returns the currency all rates are quoted in
+func (t *FXRateTable) GetBaseCurrency() string {
+	return t.baseCurrency
+}
+
+// AddRates adds a rate series for a currency. Rates may be quoted either as
+// currency/base or base/currency; the latter are inverted on insertion.
+func (t *FXRateTable) AddRates(currency string, rates []feed.FXRate) error {
+	converted := make([]feed.FXRate, 0, len(rates))
+	for _, rate := range rates {
+		if rate.Rate <= 0 {
+			return fmt.Errorf("invalid FX rate %f for %s/%s at %s", rate.Rate, rate.Base, rate.Quote, rate.Timestamp)
+		}
+
+		switch {
+		case rate.Base == currency && rate.Quote == t.baseCurrency:
+			converted = append(converted, rate)
+		case rate.Base == t.baseCurrency && rate.Quote == currency:
+			converted = append(converted, feed.FXRate{
+				Base:      currency,
+				Quote:     t.baseCurrency,
+				Timestamp: rate.Timestamp,
+				Rate:      1 / rate.Rate,
+			})
+		default:
+			return fmt.Errorf("FX rate %s/%s cannot convert %s into %s", rate.Base, rate.Quote, currency, t.baseCurrency)
+		}
+	}
+
+	merged := append(t.series[currency], converted...)
+	sort.Slice(merged, func(i, j int) bool {
+		return merged[i].Timestamp.Before(merged[j].Timestamp)
+	})
+	t.series[currency] = merged
+
+	return nil
+}
+
+// HasRates returns true if the table can convert the currency into the base currency
+func (t *FXRateTable) HasRates(currency string) bool {
+	return currency == t.baseCurrency || len(t.series[currency]) > 0
+}
+
+// GetRate returns the most recent rate at or before the given time for converting
+// one unit of currency into the base currency
+func (t *FXRateTable) GetRate(currency string, at time.Time) (float64, error) {
+	if currency == t.baseCurrency || currency == "" {
+		return 1.0, nil
+	}
+
+	rates := t.series[currency]
+	if len(rates) == 0 {
+		return 0, fmt.Errorf("no FX rates available for %s/%s", currency, t.baseCurrency)
+	}
+
+	// Find the first rate after the requested time, then step back one
+	idx := sort.Search(len(rates), func(i int) bool {
+		return rates[i].Timestamp.After(at)
+	})
+	if idx == 0 {
+		return 0, fmt.Errorf("no FX rate for %s/%s at or before %s", currency, t.baseCurrency, at.Format(time.RFC3339))
+	}
+
+	return rates[idx-1].Rate, nil
+}
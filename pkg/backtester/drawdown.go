@@ -0,0 +1,129 @@
+package backtester
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// DefaultTopDrawdowns is the number of drawdown periods kept in the results by default
+const DefaultTopDrawdowns = 5
+
+// DrawdownPeriod is an episode from an equity peak, through its trough, to the recovery of the peak
+type DrawdownPeriod struct {
+	Peak         time.Time `json:"peak"`
+	Trough       time.Time `json:"trough"`
+	Recovery     time.Time `json:"recovery"` // Zero while ongoing
+	Ongoing      bool      `json:"ongoing"`  // The equity had not recovered its peak by the end of the run
+	PeakValue    float64   `json:"peak_value"`
+	TroughValue  float64   `json:"trough_value"`
+	Depth        float64   `json:"depth"`         // Decline from peak to trough as a decimal (0.1 = 10%)
+	DurationDays float64   `json:"duration_days"` // Peak to recovery, or to the end of the run if ongoing
+	DeclineDays  float64   `json:"decline_days"`  // Peak to trough
+	RecoveryDays float64   `json:"recovery_days"` // Trough to recovery, or to the end of the run if ongoing
+	RecoveryBars int       `json:"recovery_bars"` // Equity points from trough to recovery
+	DurationBars int       `json:"duration_bars"` // Equity points from peak to recovery
+}
+
+// SetTopDrawdowns sets how many of the deepest drawdown periods are kept in the results
+func (e *Engine) SetTopDrawdowns(n int) {
+	if n <= 0 {
+		n = DefaultTopDrawdowns
+	}
+	e.results.topDrawdowns = n
+}
+
+// DrawdownPeriods returns every drawdown episode of the equity curve in chronological
+// order. The initial capital is the first peak.
+func DrawdownPeriods(curve []EquityPoint, initialCapital float64) []DrawdownPeriod {
+	if len(curve) == 0 {
+		return nil
+	}
+
+	var periods []DrawdownPeriod
+	peakValue, peakTime, peakIndex := initialCapital, curve[0].Timestamp, 0
+	var current *DrawdownPeriod
+	troughIndex := 0
+
+	for i, point := range curve {
+		if point.Value >= peakValue {
+			if current != nil {
+				current.Recovery = point.Timestamp
+				current.DurationDays = days(current.Peak, point.Timestamp)
+				current.RecoveryDays = days(current.Trough, point.Timestamp)
+				current.DurationBars = i - peakIndex
+				current.RecoveryBars = i - troughIndex
+				periods = append(periods, *current)
+				current = nil
+			}
+			peakValue, peakTime, peakIndex = point.Value, point.Timestamp, i
+			continue
+		}
+
+		if current == nil {
+			current = &DrawdownPeriod{Peak: peakTime, PeakValue: peakValue, TroughValue: math.Inf(1)}
+		}
+		if point.Value < current.TroughValue {
+			current.Trough, current.TroughValue = point.Timestamp, point.Value
+			current.Depth = 1 - point.Value/peakValue
+			current.DeclineDays = days(current.Peak, point.Timestamp)
+			troughIndex = i
+		}
+	}
+
+	if current != nil {
+		last := len(curve) - 1
+		current.Ongoing = true
+		current.DurationDays = days(current.Peak, curve[last].Timestamp)
+		current.RecoveryDays = days(current.Trough, curve[last].Timestamp)
+		current.DurationBars = last - peakIndex
+		current.RecoveryBars = last - troughIndex
+		periods = append(periods, *current)
+	}
+	return periods
+}
+
+// TopDrawdownPeriods returns the n deepest drawdown episodes, deepest first
+func TopDrawdownPeriods(curve []EquityPoint, initialCapital float64, n int) []DrawdownPeriod {
+	periods := DrawdownPeriods(curve, initialCapital)
+	sort.SliceStable(periods, func(i, j int) bool {
+		return periods[i].Depth > periods[j].Depth
+	})
+	if len(periods) > n {
+		periods = periods[:n]
+	}
+	return periods
+}
+
+// calculateDrawdownMetrics sets the deepest drawdown periods and the Ulcer and pain
+// indexes, which are measured on daily closes
+func (r *Results) calculateDrawdownMetrics() {
+	n := r.topDrawdowns
+	if n <= 0 {
+		n = DefaultTopDrawdowns
+	}
+	r.DrawdownPeriods = TopDrawdownPeriods(r.EquityCurve, r.InitialCapital, n)
+
+	closes := r.dailyCloses(r.EquityCurve)
+	if len(closes) == 0 {
+		return
+	}
+	peak := r.InitialCapital
+	var sumSquares, sum float64
+	for _, point := range closes {
+		peak = math.Max(peak, point.Value)
+		drawdown := 0.0
+		if peak > 0 {
+			drawdown = (1 - point.Value/peak) * 100
+		}
+		sumSquares += drawdown * drawdown
+		sum += drawdown
+	}
+	r.Metrics.UlcerIndex = math.Sqrt(sumSquares / float64(len(closes)))
+	r.Metrics.PainIndex = sum / float64(len(closes))
+}
+
+// days returns the number of days between two times
+func days(from, to time.Time) float64 {
+	return to.Sub(from).Hours() / 24
+}
@@ -0,0 +1,100 @@
+package backtester
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// valueCurve returns one equity point per day at 21:00 UTC with the given values
+func valueCurve(values ...float64) []EquityPoint {
+	first := time.Date(2024, 1, 2, 21, 0, 0, 0, time.UTC)
+	curve := make([]EquityPoint, len(values))
+	for i, value := range values {
+		curve[i] = EquityPoint{Timestamp: first.AddDate(0, 0, i), Value: value}
+	}
+	return curve
+}
+
+func TestDrawdownPeriods(t *testing.T) {
+	// A 20% drawdown recovered on day 4, then a 10% drawdown still open at the end
+	curve := valueCurve(100, 90, 80, 95, 100, 110, 104.5, 99, 105)
+	periods := DrawdownPeriods(curve, 100)
+	if len(periods) != 2 {
+		t.Fatalf("got %d periods, want 2", len(periods))
+	}
+
+	recovered := periods[0]
+	if recovered.Ongoing || !recovered.Peak.Equal(curve[0].Timestamp) || !recovered.Trough.Equal(curve[2].Timestamp) || !recovered.Recovery.Equal(curve[4].Timestamp) {
+		t.Errorf("first period = %+v, want peak on day 0, trough on day 2 and recovery on day 4", recovered)
+	}
+	assertClose(t, "peak value", recovered.PeakValue, 100)
+	assertClose(t, "trough value", recovered.TroughValue, 80)
+	assertClose(t, "depth", recovered.Depth, 0.2)
+	assertClose(t, "decline days", recovered.DeclineDays, 2)
+	assertClose(t, "duration days", recovered.DurationDays, 4)
+	assertClose(t, "recovery days", recovered.RecoveryDays, 2)
+	if recovered.DurationBars != 4 || recovered.RecoveryBars != 2 {
+		t.Errorf("first period bars = %d peak to recovery, %d trough to recovery; want 4 and 2", recovered.DurationBars, recovered.RecoveryBars)
+	}
+
+	// Still open: measured to the last equity point and without a recovery time
+	ongoing := periods[1]
+	if !ongoing.Ongoing || !ongoing.Recovery.IsZero() || !ongoing.Peak.Equal(curve[5].Timestamp) || !ongoing.Trough.Equal(curve[7].Timestamp) {
+		t.Errorf("second period = %+v, want an ongoing period from day 5 with its trough on day 7", ongoing)
+	}
+	assertClose(t, "depth", ongoing.Depth, 0.1)
+	assertClose(t, "decline days", ongoing.DeclineDays, 2)
+	assertClose(t, "duration days", ongoing.DurationDays, 3)
+	assertClose(t, "recovery days", ongoing.RecoveryDays, 1)
+	if ongoing.DurationBars != 3 || ongoing.RecoveryBars != 1 {
+		t.Errorf("second period bars = %d peak to end, %d trough to end; want 3 and 1", ongoing.DurationBars, ongoing.RecoveryBars)
+	}
+
+	top := TopDrawdownPeriods(curve, 100, 1)
+	if len(top) != 1 || top[0].Depth != recovered.Depth {
+		t.Errorf("top period = %+v, want the 20%% drawdown", top)
+	}
+}
+
+func TestDrawdownPeriodsStartBelowInitialCapital(t *testing.T) {
+	// The initial capital is the first peak, dated at the first equity point
+	curve := valueCurve(95, 97, 100)
+	periods := DrawdownPeriods(curve, 100)
+	if len(periods) != 1 {
+		t.Fatalf("got %d periods, want 1", len(periods))
+	}
+	period := periods[0]
+	if period.Ongoing || !period.Peak.Equal(curve[0].Timestamp) || !period.Recovery.Equal(curve[2].Timestamp) {
+		t.Errorf("period = %+v, want a recovered period from day 0 to day 2", period)
+	}
+	assertClose(t, "peak value", period.PeakValue, 100)
+	assertClose(t, "depth", period.Depth, 0.05)
+	if period.DurationBars != 2 || period.RecoveryBars != 2 {
+		t.Errorf("bars = %d and %d, want 2 and 2", period.DurationBars, period.RecoveryBars)
+	}
+
+	if got := DrawdownPeriods(valueCurve(100, 101, 101), 100); len(got) != 0 {
+		t.Errorf("periods of a rising curve = %+v, want none", got)
+	}
+}
+
+func TestDrawdownMetricsUseDailyCloses(t *testing.T) {
+	curve := valueCurve(100, 90, 80, 95, 100, 110, 104.5, 99, 105)
+	// An intraday low on the first day is not a session close
+	curve = append([]EquityPoint{{Timestamp: curve[0].Timestamp.Add(-time.Hour), Value: 50}}, curve...)
+	r := &Results{InitialCapital: 100, EquityCurve: curve, Metrics: &PerformanceMetrics{}, topDrawdowns: 2}
+	r.calculateDrawdownMetrics()
+
+	if len(r.DrawdownPeriods) != 2 || r.DrawdownPeriods[0].Depth != 0.5 {
+		t.Errorf("drawdown periods = %+v, want the intraday 50%% drawdown first", r.DrawdownPeriods)
+	}
+	drawdowns := []float64{0, 10, 20, 5, 0, 0, 5, 10, (1 - 105.0/110) * 100}
+	var sum, sumSquares float64
+	for _, d := range drawdowns {
+		sum += d
+		sumSquares += d * d
+	}
+	assertClose(t, "ulcer index", r.Metrics.UlcerIndex, math.Sqrt(sumSquares/9))
+	assertClose(t, "pain index", r.Metrics.PainIndex, sum/9)
+}
@@ -2,6 +2,7 @@ package backtester
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/ridopark/JonBuhTrader/pkg/feed"
 	"github.com/ridopark/JonBuhTrader/pkg/logging"
@@ -18,6 +19,20 @@ type Engine struct {
 	results   *Results
 	ctx       *StrategyContext
 	logger    zerolog.Logger
+
+	// Session tracking for daily futures settlement
+	sessionLocation *time.Location
+	currentSession  time.Time
+
+	// Portfolio-level risk limits (nil when disabled)
+	circuitBreaker *circuitBreaker
+
+	// Daily periods per year for annualized metrics (0 = from the instruments)
+	periodsPerYear int
+
+	// Benchmark the results are compared with (empty symbol when disabled)
+	benchmarkSymbol string
+	benchmarkBars   []strategy.BarData
 }
 
 // NewEngine creates a new backtesting engine with default configuration
@@ -42,9 +57,11 @@ func NewEngineWithConfig(s strategy.Strategy, f feed.DataFeed, initialCapital fl
 	broker := NewBroker(commissionConfig, slippage, maxSlippage)
 	results := &Results{
 		StrategyName:   s.GetName(),
+		BaseCurrency:   portfolio.GetBaseCurrency(),
 		InitialCapital: initialCapital,
 		Trades:         make([]strategy.TradeEvent, 0),
 		EquityCurve:    make([]EquityPoint, 0),
+		Bars:           make(map[string][]strategy.BarData),
 	}
 
 	engine := &Engine{
@@ -54,7 +71,10 @@ func NewEngineWithConfig(s strategy.Strategy, f feed.DataFeed, initialCapital fl
 		portfolio: portfolio,
 		results:   results,
 		logger:    logging.GetLogger("backtester"),
+
+		sessionLocation: time.UTC,
 	}
+	results.sessionLocation = time.UTC
 
 	// Create context after engine is initialized
 	engine.ctx = NewStrategyContext(engine)
@@ -62,10 +82,67 @@ func NewEngineWithConfig(s strategy.Strategy, f feed.DataFeed, initialCapital fl
 	return engine
 }
 
+// SetBaseCurrency sets the currency the initial capital and results are expressed in
+func (e *Engine) SetBaseCurrency(currency string) {
+	e.portfolio.SetBaseCurrency(currency)
+	e.results.BaseCurrency = currency
+}
+
+// SetInstruments registers instrument reference data such as the trading currency of each symbol
+func (e *Engine) SetInstruments(instruments []strategy.Instrument) {
+	for _, instrument := range instruments {
+		e.portfolio.SetInstrument(instrument)
+		e.broker.SetInstrument(instrument)
+	}
+}
+
+// SetSessionLocation sets the time zone used to determine trading session boundaries
+func (e *Engine) SetSessionLocation(location *time.Location) {
+	e.sessionLocation = location
+	e.results.sessionLocation = location
+}
+
+// SetRiskFreeRate sets the annual risk-free rate (as a decimal) used by Sharpe, Sortino and alpha
+func (e *Engine) SetRiskFreeRate(rate float64) {
+	e.results.RiskFreeRate = rate
+}
+
+// SetPeriodsPerYear sets the number of daily periods per year used to annualize metrics.
+// Zero selects CalendarDaysPerYear when every instrument is crypto and TradingDaysPerYear otherwise.
+func (e *Engine) SetPeriodsPerYear(periods int) {
+	e.periodsPerYear = periods
+}
+
+// SetMaxLookback sets the number of bars kept per symbol for history and indicator calculations
+func (e *Engine) SetMaxLookback(bars int) {
+	if bars <= 0 {
+		bars = DefaultMaxLookback
+	}
+	e.ctx.maxLookback = bars
+}
+
+// SetRiskLimits enables portfolio-level circuit breakers
+func (e *Engine) SetRiskLimits(limits RiskLimits) {
+	if !limits.Enabled() {
+		e.circuitBreaker = nil
+		return
+	}
+	e.circuitBreaker = newCircuitBreaker(limits)
+}
+
+// SetFXRates sets the FX rate series used to convert foreign currency amounts into the base currency
+func (e *Engine) SetFXRates(table *FXRateTable) {
+	e.portfolio.SetFXRates(table)
+}
+
 // Run executes the backtest
 func (e *Engine) Run() error {
 	e.logger.Info().Msg("Starting backtest execution")
 
+	if err := e.portfolio.ValidateFXRates(); err != nil {
+		return fmt.Errorf("invalid currency configuration: %w", err)
+	}
+
 	// Initialize strategy
 	if err := e.strategy.Initialize(e.ctx); err != nil {
 		return fmt.Errorf("failed to initialize strategy: %w", err)
@@ -99,6 +176,22 @@ func (e *Engine) Run() error {
 
 		dataPointCount++
 
+		// Update FX rates before any trades are settled
+		if err := e.portfolio.UpdateFXRates(dataPoint.Timestamp); err != nil {
+			return fmt.Errorf("error updating FX rates: %w", err)
+		}
+
+		// Settle futures variation margin at session boundaries
+		session := e.sessionDate(dataPoint.Timestamp)
+		if !e.currentSession.IsZero() && !session.Equal(e.currentSession) {
+			e.settleSession()
+			e.startSession(session)
+		}
+		e.currentSession = session
+
+		// Close positions in futures contracts that have expired
+		e.closeExpiredPositions(dataPoint.Timestamp)
+
 		// Update price history for technical indicators
 		e.ctx.UpdatePriceHistory(*dataPoint)
 
@@ -112,6 +205,29 @@ func (e *Engine) Run() error {
 		// Execute orders through broker
 		for _, order := range orders {
 			bar := dataPoint.Bars[order.Symbol]
+
+			if e.circuitBreaker != nil && e.circuitBreaker.halted {
+				e.rejectOrder(order, dataPoint.Timestamp, "trading halted by circuit breaker")
+				continue
+			}
+
+			if instrument := e.portfolio.GetInstrument(order.Symbol); instrument != nil && instrument.IsExpired(dataPoint.Timestamp) {
+				e.rejectOrder(order, dataPoint.Timestamp, "contract has expired")
+				continue
+			}
+
+			order, err = e.broker.PrepareOrder(order, bar)
+			if err != nil {
+				e.rejectOrder(order, dataPoint.Timestamp, err.Error())
+				continue
+			}
+
+			if !e.portfolio.HasSufficientMargin(order) {
+				e.rejectOrder(order, dataPoint.Timestamp, fmt.Sprintf("insufficient margin: %.2f in use, %.2f cash",
+					e.portfolio.GetMarginRequirement(false), e.portfolio.GetCash()))
+				continue
+			}
+
 			trade, err := e.broker.ExecuteOrder(order, bar)
 			if err != nil {
 				e.logger.Error().Err(err).Msg("Order execution failed")
@@ -119,6 +235,7 @@ func (e *Engine) Run() error {
 			}
 
 			// Apply trade to portfolio
+			trade.FXRate = e.portfolio.GetFXRate(e.portfolio.GetCurrency(trade.Symbol))
 			e.portfolio.ExecuteTrade(*trade, bar.Close)
 
 			// Notify strategy of trade
@@ -128,22 +245,40 @@ func (e *Engine) Run() error {
 
 			// Record trade in results
 			e.results.Trades = append(e.results.Trades, *trade)
+
+			if e.circuitBreaker != nil {
+				e.circuitBreaker.recordTrade(e.portfolio.GetLastTradePL())
+				if event := e.circuitBreaker.checkTrades(dataPoint.Timestamp); event != nil {
+					e.tripCircuitBreaker(*event)
+				}
+			}
 		}
 
 		// Update portfolio value with current market prices
 		e.portfolio.UpdateMarketValues(dataPoint.Bars)
 
+		if e.circuitBreaker != nil {
+			if event := e.circuitBreaker.checkPortfolio(dataPoint.Timestamp, e.portfolio.GetDayPL(), e.portfolio.GetCurrentDrawdown()); event != nil {
+				e.tripCircuitBreaker(*event)
+				e.portfolio.UpdateMarketValues(nil)
+			}
+		}
+
 		// Record equity point
 		e.results.EquityCurve = append(e.results.EquityCurve, EquityPoint{
 			Timestamp: dataPoint.Timestamp,
 			Value:     e.portfolio.GetTotalValue(),
 		})
+		for symbol, bar := range dataPoint.Bars {
+			e.results.Bars[symbol] = append(e.results.Bars[symbol], bar)
+		}
 	}
 
 	e.logger.Info().Int("bars_processed", dataPointCount).Msg("Backtest completed")
 
 	if dataPointCount > 0 {
 		e.CloseAllPostionsAtEnd()
+		e.portfolio.TakeDailySnapshot(e.currentSession)
 	}
 
 	// Cleanup strategy
@@ -159,7 +294,32 @@ func (e *Engine) Run() error {
 	e.results.FinalCapital = e.portfolio.GetTotalValue()
 	e.results.TotalReturn = (e.results.FinalCapital - e.results.InitialCapital) / e.results.InitialCapital * 100
 	e.results.TotalPL = e.results.FinalCapital - e.results.InitialCapital
+	e.results.AssetPL = e.portfolio.GetAssetPL()
+	e.results.CurrencyPL = e.portfolio.GetCurrencyPL()
+	e.results.MaxDrawdown = e.portfolio.GetMaxDrawdown()
 	e.results.Portfolio = e.portfolio.ToStrategyPortfolio()
+	e.results.DailySnapshots = e.portfolio.GetDailySnapshots()
+
+	// Match fills into round trips, with P&L scaled by each contract multiplier
+	multipliers := make(map[string]float64, len(e.results.Bars))
+	for symbol := range e.results.Bars {
+		multipliers[symbol] = e.portfolio.GetMultiplier(symbol)
+	}
+	e.results.RoundTrips = RoundTrips(e.results.Trades, e.results.Bars, multipliers)
+
+	e.results.PeriodsPerYear = e.periodsPerYear
+	if e.results.PeriodsPerYear <= 0 {
+		e.results.PeriodsPerYear = e.defaultPeriodsPerYear()
+	}
+
+	if e.benchmarkSymbol != "" {
+		bars := e.results.Bars
+		if e.benchmarkSymbol != BenchmarkUniverse {
+			bars = map[string][]strategy.BarData{e.benchmarkSymbol: e.benchmarkBars}
+		}
+		e.results.BenchmarkSymbol = e.benchmarkSymbol
+		e.results.BenchmarkCurve = BuyAndHoldCurve(bars, e.results.EquityCurve, e.results.InitialCapital)
+	}
 
 	// Calculate performance metrics
 	e.results.CalculateMetrics()
@@ -179,74 +339,26 @@ func (e *Engine) CloseAllPostionsAtEnd() {
 
 	liquidationCount := 0
 	totalLiquidationValue := 0.0
+	finalTimestamp := e.results.EquityCurve[len(e.results.EquityCurve)-1].Timestamp
 
 	for symbol, position := range positions {
 		if position.Quantity == 0 {
 			continue // Skip positions with zero quantity
 		}
 
-		// Get the last known price for this symbol from the portfolio's market values
-		// We'll use the position's current market value to derive the price
-		lastPrice := 0.0
-		if position.Quantity != 0 {
-			lastPrice = position.MarketValue / position.Quantity
-		}
-
-		if lastPrice <= 0 {
-			e.logger.Error().Str("symbol", symbol).Msg("Cannot liquidate position: invalid price")
-			continue
-		}
-
-		// Determine the order side based on current position
-		var orderSide strategy.OrderSide
-		quantity := position.Quantity
-		if quantity > 0 {
-			orderSide = strategy.OrderSideSell // Close long position
-		} else {
-			orderSide = strategy.OrderSideBuy // Close short position
-			quantity = -quantity              // Make quantity positive for the order
-		}
-
-		// Create liquidation order
-		liquidationOrder := strategy.Order{
-			Symbol:   symbol,
-			Side:     orderSide,
-			Quantity: quantity,
-			Type:     strategy.OrderTypeMarket,
-			Reason:   "end_of_backtest_liquidation",
-		}
-
-		// Create a synthetic bar for liquidation at the last known price
-		liquidationBar := strategy.BarData{
-			Symbol:    symbol,
-			Timestamp: e.results.EquityCurve[len(e.results.EquityCurve)-1].Timestamp,
-			Open:      lastPrice,
-			High:      lastPrice,
-			Low:       lastPrice,
-			Close:     lastPrice,
-			Volume:    0, // Synthetic bar has no volume
-		}
-
-		// Execute the liquidation order
-		trade, err := e.broker.ExecuteOrder(liquidationOrder, liquidationBar)
+		trade, err := e.liquidatePosition(symbol, position, finalTimestamp, "end_of_backtest_liquidation")
 		if err != nil {
 			e.logger.Error().Err(err).Str("symbol", symbol).Msg("Failed to execute liquidation order")
 			continue
 		}
 
-		// Apply trade to portfolio
-		e.portfolio.ExecuteTrade(*trade, lastPrice)
-
-		// Record the liquidation trade in results
-		e.results.Trades = append(e.results.Trades, *trade)
-
-		liquidationValue := trade.Quantity * trade.Price
+		liquidationValue := trade.Quantity * trade.Price * e.portfolio.GetMultiplier(symbol)
 		totalLiquidationValue += liquidationValue
 		liquidationCount++
 
 		e.logger.Info().
 			Str("symbol", symbol).
-			Str("side", string(orderSide)).
+			Str("side", string(trade.Side)).
 			Float64("quantity", trade.Quantity).
 			Float64("price", trade.Price).
 			Float64("value", liquidationValue).
@@ -255,8 +367,10 @@ func (e *Engine) CloseAllPostionsAtEnd() {
 	}
 
 	if liquidationCount > 0 {
+		// Revalue the portfolio now that positions have been closed
+		e.portfolio.UpdateMarketValues(nil)
+
 		// Record final equity point after all liquidations
-		finalTimestamp := e.results.EquityCurve[len(e.results.EquityCurve)-1].Timestamp
 		e.results.EquityCurve = append(e.results.EquityCurve, EquityPoint{
 			Timestamp: finalTimestamp,
 			Value:     e.portfolio.GetTotalValue(),
@@ -271,6 +385,179 @@ func (e *Engine) CloseAllPostionsAtEnd() {
 	}
 }
 
+// rejectOrder logs an order rejected before execution and records it in the results
+func (e *Engine) rejectOrder(order strategy.Order, timestamp time.Time, reason string) {
+	e.logger.Error().
+		Str("symbol", order.Symbol).
+		Str("side", string(order.Side)).
+		Str("type", string(order.Type)).
+		Float64("quantity", order.Quantity).
+		Str("reason", reason).
+		Msg("Order rejected")
+
+	e.results.RejectedOrders = append(e.results.RejectedOrders, OrderRejection{
+		Timestamp: timestamp,
+		Order:     order,
+		Reason:    reason,
+	})
+}
+
+// liquidatePosition closes a position at its last known price and records the trade
+func (e *Engine) liquidatePosition(symbol string, position *strategy.Position, timestamp time.Time, reason string) (*strategy.TradeEvent, error) {
+	lastPrice := e.portfolio.GetLastPrice(symbol)
+	if lastPrice <= 0 {
+		return nil, fmt.Errorf("cannot liquidate position in %s: invalid price %f", symbol, lastPrice)
+	}
+
+	// Determine the order side based on current position
+	var orderSide strategy.OrderSide
+	quantity := position.Quantity
+	if quantity > 0 {
+		orderSide = strategy.OrderSideSell // Close long position
+	} else {
+		orderSide = strategy.OrderSideBuy // Close short position
+		quantity = -quantity              // Make quantity positive for the order
+	}
+
+	// Create liquidation order
+	liquidationOrder := strategy.Order{
+		Symbol:   symbol,
+		Side:     orderSide,
+		Quantity: quantity,
+		Type:     strategy.OrderTypeMarket,
+		Reason:   reason,
+	}
+
+	// Create a synthetic bar for liquidation at the last known price
+	liquidationBar := strategy.BarData{
+		Symbol:    symbol,
+		Timestamp: timestamp,
+		Open:      lastPrice,
+		High:      lastPrice,
+		Low:       lastPrice,
+		Close:     lastPrice,
+		Volume:    0, // Synthetic bar has no volume
+	}
+
+	// Execute the liquidation order
+	trade, err := e.broker.ExecuteOrder(liquidationOrder, liquidationBar)
+	if err != nil {
+		return nil, err
+	}
+
+	// Apply trade to portfolio
+	trade.FXRate = e.portfolio.GetFXRate(e.portfolio.GetCurrency(trade.Symbol))
+	e.portfolio.ExecuteTrade(*trade, lastPrice)
+
+	// Record the liquidation trade in results
+	e.results.Trades = append(e.results.Trades, *trade)
+
+	return trade, nil
+}
+
+// closeExpiredPositions liquidates positions in futures contracts that have expired
+func (e *Engine) closeExpiredPositions(timestamp time.Time) {
+	for symbol, position := range e.portfolio.GetPositions() {
+		instrument := e.portfolio.GetInstrument(symbol)
+		if instrument == nil || !instrument.IsExpired(timestamp) {
+			continue
+		}
+
+		trade, err := e.liquidatePosition(symbol, position, timestamp, "contract_expiration")
+		if err != nil {
+			e.logger.Error().Err(err).Str("symbol", symbol).Msg("Failed to close expired contract")
+			continue
+		}
+
+		e.logger.Info().
+			Str("symbol", symbol).
+			Time("expiration", instrument.Expiration).
+			Float64("quantity", trade.Quantity).
+			Float64("price", trade.Price).
+			Msg("Expired contract position closed")
+
+		if err := e.strategy.OnTrade(e.ctx, *trade); err != nil {
+			e.logger.Error().Err(err).Msg("Strategy error on trade")
+		}
+	}
+}
+
+// sessionDate returns the trading session date a timestamp belongs to
+func (e *Engine) sessionDate(timestamp time.Time) time.Time {
+	local := timestamp.In(e.sessionLocation)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, e.sessionLocation)
+}
+
+// settleSession performs end-of-session processing: futures variation margin and the daily snapshot
+func (e *Engine) settleSession() {
+	variation := e.portfolio.SettleFutures()
+	if variation != 0 {
+		e.logger.Debug().
+			Time("session", e.currentSession).
+			Float64("variation_margin", variation).
+			Msg("Futures positions marked to market")
+	}
+
+	maintenance := e.portfolio.GetMarginRequirement(true)
+	if maintenance > 0 && e.portfolio.GetCash() < maintenance {
+		e.logger.Warn().
+			Time("session", e.currentSession).
+			Float64("cash", e.portfolio.GetCash()).
+			Float64("maintenance_margin", maintenance).
+			Msg("Margin call: cash below maintenance margin")
+	}
+
+	snapshot := e.portfolio.TakeDailySnapshot(e.currentSession)
+	e.logger.Debug().
+		Time("session", snapshot.Date).
+		Float64("total_value", snapshot.TotalValue).
+		Float64("day_pl", snapshot.DayPL).
+		Float64("gross_exposure", snapshot.GrossExposure).
+		Msg("Daily snapshot recorded")
+}
+
+// startSession resets per-session state when a new trading session begins
+func (e *Engine) startSession(session time.Time) {
+	if e.circuitBreaker != nil && e.circuitBreaker.startSession() {
+		e.logger.Info().Time("session", session).Msg("Circuit breaker reset, trading resumed")
+	}
+}
+
+// tripCircuitBreaker records a circuit breaker event, flattens positions if
+// configured and notifies the strategy
+func (e *Engine) tripCircuitBreaker(event strategy.CircuitBreakerEvent) {
+	e.logger.Warn().
+		Str("rule", event.Rule).
+		Float64("value", event.Value).
+		Float64("limit", event.Limit).
+		Str("action", event.Action).
+		Str("scope", event.Scope).
+		Time("timestamp", event.Timestamp).
+		Msg("Circuit breaker tripped, trading halted")
+
+	e.results.CircuitBreakerEvents = append(e.results.CircuitBreakerEvents, event)
+
+	if event.Action == string(CircuitBreakerFlatten) {
+		for symbol, position := range e.portfolio.GetPositions() {
+			trade, err := e.liquidatePosition(symbol, position, event.Timestamp, "circuit_breaker_"+event.Rule)
+			if err != nil {
+				e.logger.Error().Err(err).Str("symbol", symbol).Msg("Failed to flatten position")
+				continue
+			}
+
+			if err := e.strategy.OnTrade(e.ctx, *trade); err != nil {
+				e.logger.Error().Err(err).Msg("Strategy error on trade")
+			}
+		}
+	}
+
+	if handler, ok := e.strategy.(strategy.CircuitBreakerHandler); ok {
+		if err := handler.OnCircuitBreaker(e.ctx, event); err != nil {
+			e.logger.Error().Err(err).Msg("Strategy error on circuit breaker")
+		}
+	}
+}
+
 // GetResults returns the backtest results
 func (e *Engine) GetResults() *Results {
 	return e.results
@@ -0,0 +1,199 @@
+package backtester
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/ridopark/JonBuhTrader/pkg/strategy"
+)
+
+// sliceFeed replays a fixed list of data points
+type sliceFeed struct {
+	dataPoints []strategy.DataPoint
+	next       int
+}
+
+func (f *sliceFeed) Initialize() error { return nil }
+func (f *sliceFeed) HasMoreData() bool { return f.next < len(f.dataPoints) }
+func (f *sliceFeed) Reset() error      { f.next = 0; return nil }
+func (f *sliceFeed) Close() error      { return nil }
+func (f *sliceFeed) GetSymbols() []string {
+	return []string{testSymbol}
+}
+func (f *sliceFeed) GetTimeframe() string { return "1h" }
+
+func (f *sliceFeed) GetNextDataPoint() (*strategy.DataPoint, error) {
+	if !f.HasMoreData() {
+		return nil, nil
+	}
+	dataPoint := f.dataPoints[f.next]
+	f.next++
+	return &dataPoint, nil
+}
+
+// scriptedStrategy submits the orders returned by onDataPoint for each data point index
+type scriptedStrategy struct {
+	onDataPoint func(ctx strategy.Context, index int, dataPoint strategy.DataPoint) []strategy.Order
+	index       int
+	events      []strategy.CircuitBreakerEvent
+}
+
+func (s *scriptedStrategy) Initialize(ctx strategy.Context) error { return nil }
+
+func (s *scriptedStrategy) OnDataPoint(ctx strategy.Context, dataPoint strategy.DataPoint) ([]strategy.Order, error) {
+	defer func() { s.index++ }()
+	if s.onDataPoint == nil {
+		return nil, nil
+	}
+	return s.onDataPoint(ctx, s.index, dataPoint), nil
+}
+
+func (s *scriptedStrategy) OnTrade(ctx strategy.Context, trade strategy.TradeEvent) error { return nil }
+func (s *scriptedStrategy) Cleanup(ctx strategy.Context) error                            { return nil }
+func (s *scriptedStrategy) GetName() string                                               { return "scripted" }
+func (s *scriptedStrategy) GetParameters() map[string]interface{}                         { return nil }
+
+func (s *scriptedStrategy) OnCircuitBreaker(ctx strategy.Context, event strategy.CircuitBreakerEvent) error {
+	s.events = append(s.events, event)
+	return nil
+}
+
+// priceFeed returns a feed with one testSymbol bar per timestamp
+func priceFeed(timestamps []time.Time, closes []float64) *sliceFeed {
+	feed := &sliceFeed{}
+	for i, timestamp := range timestamps {
+		bar := strategy.BarData{Symbol: testSymbol, Timestamp: timestamp, Open: closes[i], High: closes[i], Low: closes[i], Close: closes[i], Volume: 1000}
+		feed.dataPoints = append(feed.dataPoints, strategy.DataPoint{
+			Timestamp: timestamp,
+			Bars:      map[string]strategy.BarData{testSymbol: bar},
+		})
+	}
+	return feed
+}
+
+// marketOrder returns a market order for testSymbol
+func marketOrder(side strategy.OrderSide, quantity float64) strategy.Order {
+	return strategy.Order{Symbol: testSymbol, Side: side, Type: strategy.OrderTypeMarket, Quantity: quantity}
+}
+
+// newFrictionlessEngine returns an engine without commission or slippage; regulatory fees still apply
+func newFrictionlessEngine(s strategy.Strategy, feed *sliceFeed) *Engine {
+	return NewEngineWithConfig(s, feed, 10000, "fixed", 0, 0, 0)
+}
+
+func TestDailySnapshotsFollowSessionLocation(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+
+	// 21:00 New York on March 1 is already March 2 in UTC
+	timestamps := []time.Time{
+		time.Date(2024, 3, 1, 14, 0, 0, 0, time.UTC),
+		time.Date(2024, 3, 2, 2, 0, 0, 0, time.UTC),
+		time.Date(2024, 3, 2, 15, 0, 0, 0, time.UTC),
+	}
+	closes := []float64{100, 110, 105}
+	// FINRA TAF on both trades and the SEC fee on the closing sale
+	buyFees := 10 * 0.000145
+	sellFees := 10*105*0.0000278 + 10*0.000145
+
+	tests := []struct {
+		name     string
+		location *time.Location
+		dates    []time.Time
+		values   []float64
+		dayPL    []float64
+		returns  []float64
+		exposure float64
+	}{
+		{
+			name:     "UTC",
+			location: time.UTC,
+			dates:    []time.Time{time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC)},
+			values:   []float64{10000 - buyFees, 10050 - buyFees - sellFees},
+			dayPL:    []float64{-buyFees, 50 - sellFees},
+			returns:  []float64{-buyFees / 10000, (50 - sellFees) / (10000 - buyFees)},
+			exposure: 1000,
+		},
+		{
+			name:     "New York",
+			location: newYork,
+			dates:    []time.Time{time.Date(2024, 3, 1, 0, 0, 0, 0, newYork), time.Date(2024, 3, 2, 0, 0, 0, 0, newYork)},
+			values:   []float64{10100 - buyFees, 10050 - buyFees - sellFees},
+			dayPL:    []float64{100 - buyFees, -50 - sellFees},
+			returns:  []float64{(100 - buyFees) / 10000, (-50 - sellFees) / (10100 - buyFees)},
+			exposure: 1100,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &scriptedStrategy{onDataPoint: func(ctx strategy.Context, index int, dataPoint strategy.DataPoint) []strategy.Order {
+				if index == 0 {
+					return []strategy.Order{marketOrder(strategy.OrderSideBuy, 10)}
+				}
+				return nil
+			}}
+			engine := newFrictionlessEngine(s, priceFeed(timestamps, closes))
+			engine.SetSessionLocation(tt.location)
+			if err := engine.Run(); err != nil {
+				t.Fatalf("Run: %v", err)
+			}
+
+			snapshots := engine.GetResults().DailySnapshots
+			if len(snapshots) != len(tt.dates) {
+				t.Fatalf("got %d snapshots, want %d", len(snapshots), len(tt.dates))
+			}
+			for i, snapshot := range snapshots {
+				if !snapshot.Date.Equal(tt.dates[i]) {
+					t.Errorf("snapshot %d date = %v, want %v", i, snapshot.Date, tt.dates[i])
+				}
+				assertClose(t, "total value", snapshot.TotalValue, tt.values[i])
+				assertClose(t, "day P&L", snapshot.DayPL, tt.dayPL[i])
+				assertClose(t, "daily return", snapshot.DailyReturn, tt.returns[i])
+			}
+
+			// The first session ends holding the position opened at 100, marked to its last close
+			first := snapshots[0]
+			if position, exists := first.Positions[testSymbol]; !exists || position.Quantity != 10 {
+				t.Errorf("first snapshot positions = %+v, want 10 %s", first.Positions, testSymbol)
+			}
+			assertClose(t, "gross exposure", first.GrossExposure, tt.exposure)
+			assertClose(t, "net exposure", first.NetExposure, first.GrossExposure)
+			// The last session ends flat after the end-of-run liquidation
+			last := snapshots[len(snapshots)-1]
+			if len(last.Positions) != 0 || last.GrossExposure != 0 {
+				t.Errorf("last snapshot positions = %+v, want none", last.Positions)
+			}
+			assertClose(t, "realized P&L", last.RealizedPL, 50-buyFees-sellFees)
+		})
+	}
+}
+
+func TestTakeDailySnapshotStartsNewSession(t *testing.T) {
+	p := NewPortfolio(10000, NewCommissionConfig(CommissionTypeFixed, 0))
+	short := strategy.TradeEvent{Symbol: testSymbol, Side: strategy.OrderSideSell, Quantity: 10, Price: 100, Timestamp: day1}
+	if err := p.ExecuteTrade(short, 100); err != nil {
+		t.Fatalf("short: %v", err)
+	}
+	p.UpdateMarketValues(map[string]strategy.BarData{testSymbol: {Symbol: testSymbol, Close: 90}})
+
+	snapshot := p.TakeDailySnapshot(day1)
+	assertClose(t, "day P&L", snapshot.DayPL, 100)
+	assertClose(t, "unrealized P&L", snapshot.UnrealizedPL, 100)
+	assertClose(t, "realized P&L", snapshot.RealizedPL, 0)
+	assertClose(t, "gross exposure", snapshot.GrossExposure, 900)
+	assertClose(t, "net exposure", snapshot.NetExposure, -900)
+
+	// A session without price changes has no P&L
+	if p.GetDayPL() != 0 {
+		t.Errorf("day P&L after snapshot = %v, want 0", p.GetDayPL())
+	}
+	next := p.TakeDailySnapshot(day2)
+	assertClose(t, "unchanged day P&L", next.DayPL, 0)
+	returns := p.GetDailyReturns()
+	if len(returns) != 2 || math.Abs(returns[0]-0.01) > 1e-12 || returns[1] != 0 {
+		t.Errorf("daily returns = %v, want [0.01 0]", returns)
+	}
+}
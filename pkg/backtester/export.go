@@ -0,0 +1,410 @@
+package backtester
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// ExportFormat is a file format results can be exported in
+type ExportFormat string
+
+const (
+	ExportJSON    ExportFormat = "json"    // Full run: config, metrics, trades, equity curve and final portfolio
+	ExportCSV     ExportFormat = "csv"     // Trades, round trips, equity curve and rolling metrics
+	ExportParquet ExportFormat = "parquet" // Trades, round trips, equity curve and rolling metrics
+)
+
+// ParseExportFormats parses a comma-separated list of export formats (e.g., "json,csv")
+func ParseExportFormats(value string) ([]ExportFormat, error) {
+	var formats []ExportFormat
+	seen := make(map[ExportFormat]bool)
+	for _, part := range strings.Split(value, ",") {
+		format := ExportFormat(strings.ToLower(strings.TrimSpace(part)))
+		if format == "" || seen[format] {
+			continue
+		}
+		switch format {
+		case ExportJSON, ExportCSV, ExportParquet:
+		default:
+			return nil, fmt.Errorf("unknown export format %q (expected json, csv or parquet)", part)
+		}
+		seen[format] = true
+		formats = append(formats, format)
+	}
+	return formats, nil
+}
+
+// RunConfig describes how a backtest was run
+type RunConfig struct {
+	Strategy   string                 `json:"strategy"`
+	Parameters map[string]interface{} `json:"parameters"` // Strategy parameters
+	Settings   map[string]string      `json:"settings"`   // Run settings (e.g., command-line flags)
+	RunAt      time.Time              `json:"run_at"`
+}
+
+// RunReport is the complete record of a backtest written by the JSON export
+type RunReport struct {
+	Config  RunConfig `json:"config"`
+	Results *Results  `json:"results"`
+}
+
+// tradeRow is a flattened trade for CSV and Parquet export
+type tradeRow struct {
+	ID         string    `parquet:"id"`
+	OrderID    string    `parquet:"order_id"`
+	Timestamp  time.Time `parquet:"timestamp"`
+	Symbol     string    `parquet:"symbol"`
+	Side       string    `parquet:"side"`
+	Quantity   float64   `parquet:"quantity"`
+	Price      float64   `parquet:"price"`
+	Commission float64   `parquet:"commission"`
+	SecFee     float64   `parquet:"sec_fee"`
+	FinraTaf   float64   `parquet:"finra_taf"`
+	Slippage   float64   `parquet:"slippage"`
+	FXRate     float64   `parquet:"fx_rate"`
+	Strategy   string    `parquet:"strategy"`
+	Reason     string    `parquet:"reason"`
+}
+
+var tradeColumns = []string{"id", "order_id", "timestamp", "symbol", "side", "quantity", "price", "commission", "sec_fee", "finra_taf", "slippage", "fx_rate", "strategy", "reason"}
+
+// record formats the row for CSV
+func (t tradeRow) record() []string {
+	return []string{
+		t.ID,
+		t.OrderID,
+		t.Timestamp.Format(time.RFC3339Nano),
+		t.Symbol,
+		t.Side,
+		formatFloat(t.Quantity),
+		formatFloat(t.Price),
+		formatFloat(t.Commission),
+		formatFloat(t.SecFee),
+		formatFloat(t.FinraTaf),
+		formatFloat(t.Slippage),
+		formatFloat(t.FXRate),
+		t.Strategy,
+		t.Reason,
+	}
+}
+
+// roundTripRow is a round trip for CSV and Parquet export
+type roundTripRow struct {
+	Symbol      string    `parquet:"symbol"`
+	Direction   string    `parquet:"direction"`
+	EntryTime   time.Time `parquet:"entry_time"`
+	ExitTime    time.Time `parquet:"exit_time"`
+	EntryPrice  float64   `parquet:"entry_price"`
+	ExitPrice   float64   `parquet:"exit_price"`
+	Quantity    float64   `parquet:"quantity"`
+	Fees        float64   `parquet:"fees"`
+	GrossPL     float64   `parquet:"gross_pl"`
+	NetPL       float64   `parquet:"net_pl"`
+	BaseFees    float64   `parquet:"base_fees"`
+	BaseNetPL   float64   `parquet:"base_net_pl"`
+	ReturnPct   float64   `parquet:"return_pct"`
+	BarsHeld    int64     `parquet:"bars_held"`
+	MAE         float64   `parquet:"mae"`
+	MFE         float64   `parquet:"mfe"`
+	EntryReason string    `parquet:"entry_reason"`
+	ExitReason  string    `parquet:"exit_reason"`
+	Strategy    string    `parquet:"strategy"`
+}
+
+var roundTripColumns = []string{"symbol", "direction", "entry_time", "exit_time", "entry_price", "exit_price", "quantity", "fees", "gross_pl", "net_pl", "base_fees", "base_net_pl", "return_pct", "bars_held", "mae", "mfe", "entry_reason", "exit_reason", "strategy"}
+
+// record formats the row for CSV
+func (rt roundTripRow) record() []string {
+	return []string{
+		rt.Symbol,
+		rt.Direction,
+		rt.EntryTime.Format(time.RFC3339Nano),
+		rt.ExitTime.Format(time.RFC3339Nano),
+		formatFloat(rt.EntryPrice),
+		formatFloat(rt.ExitPrice),
+		formatFloat(rt.Quantity),
+		formatFloat(rt.Fees),
+		formatFloat(rt.GrossPL),
+		formatFloat(rt.NetPL),
+		formatFloat(rt.BaseFees),
+		formatFloat(rt.BaseNetPL),
+		formatFloat(rt.ReturnPct),
+		strconv.FormatInt(rt.BarsHeld, 10),
+		formatFloat(rt.MAE),
+		formatFloat(rt.MFE),
+		rt.EntryReason,
+		rt.ExitReason,
+		rt.Strategy,
+	}
+}
+
+// equityRow is an equity curve point for CSV and Parquet export
+type equityRow struct {
+	Timestamp time.Time `parquet:"timestamp"`
+	Value     float64   `parquet:"value"`
+	Drawdown  float64   `parquet:"drawdown"` // Decline from the running peak as a decimal (0.05 = 5%)
+}
+
+var equityColumns = []string{"timestamp", "value", "drawdown"}
+
+// record formats the row for CSV
+func (e equityRow) record() []string {
+	return []string{e.Timestamp.Format(time.RFC3339Nano), formatFloat(e.Value), formatFloat(e.Drawdown)}
+}
+
+// rollingRow is a rolling metrics point for CSV and Parquet export
+type rollingRow struct {
+	Window     int64     `parquet:"window"`
+	Timestamp  time.Time `parquet:"timestamp"`
+	Return     float64   `parquet:"return"`
+	Sharpe     float64   `parquet:"sharpe"`
+	Volatility float64   `parquet:"volatility"`
+	Beta       float64   `parquet:"beta"`
+	WinRate    float64   `parquet:"win_rate"`
+	RoundTrips int64     `parquet:"round_trips"`
+	Drawdown   float64   `parquet:"drawdown"`
+}
+
+var rollingColumns = []string{"window", "timestamp", "return", "sharpe", "volatility", "beta", "win_rate", "round_trips", "drawdown"}
+
+// record formats the row for CSV
+func (r rollingRow) record() []string {
+	return []string{
+		strconv.FormatInt(r.Window, 10),
+		r.Timestamp.Format(time.RFC3339Nano),
+		formatFloat(r.Return),
+		formatFloat(r.Sharpe),
+		formatFloat(r.Volatility),
+		formatFloat(r.Beta),
+		formatFloat(r.WinRate),
+		strconv.FormatInt(r.RoundTrips, 10),
+		formatFloat(r.Drawdown),
+	}
+}
+
+// Export writes the results in each format to a new directory under outputDir, named
+// after the strategy and run time, and returns the directory's path. An existing
+// directory is never reused.
+func Export(outputDir string, config RunConfig, results *Results, formats []ExportFormat) (string, error) {
+	if config.RunAt.IsZero() {
+		config.RunAt = time.Now()
+	}
+	if results.Metrics == nil {
+		results.CalculateMetrics()
+	}
+
+	name := config.Strategy
+	if name == "" {
+		name = results.StrategyName
+	}
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create output directory: %w", err)
+	}
+	// Runs started in the same second get a numeric suffix instead of overwriting each other
+	baseDir := filepath.Join(outputDir, fmt.Sprintf("%s_%s", sanitizeName(name), config.RunAt.Format("20060102_150405")))
+	runDir := baseDir
+	for suffix := 2; ; suffix++ {
+		err := os.Mkdir(runDir, 0755)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return "", fmt.Errorf("failed to create run directory: %w", err)
+		}
+		runDir = fmt.Sprintf("%s_%d", baseDir, suffix)
+	}
+
+	trades := tradeRows(results)
+	roundTrips := roundTripRows(results)
+	equity := equityRows(results)
+	rolling := rollingRows(results)
+	for _, format := range formats {
+		var err error
+		switch format {
+		case ExportJSON:
+			err = writeJSON(filepath.Join(runDir, "run.json"), RunReport{Config: config, Results: results})
+		case ExportCSV:
+			err = writeCSV(filepath.Join(runDir, "trades.csv"), tradeColumns, trades)
+			if err == nil {
+				err = writeCSV(filepath.Join(runDir, "round_trips.csv"), roundTripColumns, roundTrips)
+			}
+			if err == nil {
+				err = writeCSV(filepath.Join(runDir, "equity.csv"), equityColumns, equity)
+			}
+			if err == nil {
+				err = writeCSV(filepath.Join(runDir, "rolling.csv"), rollingColumns, rolling)
+			}
+		case ExportParquet:
+			err = parquet.WriteFile(filepath.Join(runDir, "trades.parquet"), trades)
+			if err == nil {
+				err = parquet.WriteFile(filepath.Join(runDir, "round_trips.parquet"), roundTrips)
+			}
+			if err == nil {
+				err = parquet.WriteFile(filepath.Join(runDir, "equity.parquet"), equity)
+			}
+			if err == nil {
+				err = parquet.WriteFile(filepath.Join(runDir, "rolling.parquet"), rolling)
+			}
+		default:
+			err = fmt.Errorf("unknown export format %q", format)
+		}
+		if err != nil {
+			return runDir, fmt.Errorf("failed to export %s: %w", format, err)
+		}
+	}
+
+	return runDir, nil
+}
+
+// tradeRows flattens the results' trades
+func tradeRows(results *Results) []tradeRow {
+	rows := make([]tradeRow, 0, len(results.Trades))
+	for _, trade := range results.Trades {
+		rows = append(rows, tradeRow{
+			ID:         trade.ID,
+			OrderID:    trade.OrderID,
+			Timestamp:  trade.Timestamp,
+			Symbol:     trade.Symbol,
+			Side:       string(trade.Side),
+			Quantity:   trade.Quantity,
+			Price:      trade.Price,
+			Commission: trade.Commission,
+			SecFee:     trade.SecFee,
+			FinraTaf:   trade.FinraTaf,
+			Slippage:   trade.Slippage,
+			FXRate:     trade.FXRate,
+			Strategy:   trade.Strategy,
+			Reason:     trade.Reason,
+		})
+	}
+	return rows
+}
+
+// roundTripRows converts the results' round trips
+func roundTripRows(results *Results) []roundTripRow {
+	rows := make([]roundTripRow, 0, len(results.RoundTrips))
+	for _, rt := range results.RoundTrips {
+		rows = append(rows, roundTripRow{
+			Symbol:      rt.Symbol,
+			Direction:   rt.Direction,
+			EntryTime:   rt.EntryTime,
+			ExitTime:    rt.ExitTime,
+			EntryPrice:  rt.EntryPrice,
+			ExitPrice:   rt.ExitPrice,
+			Quantity:    rt.Quantity,
+			Fees:        rt.Fees,
+			GrossPL:     rt.GrossPL,
+			NetPL:       rt.NetPL,
+			BaseFees:    rt.BaseFees,
+			BaseNetPL:   rt.BaseNetPL,
+			ReturnPct:   rt.ReturnPct,
+			BarsHeld:    int64(rt.BarsHeld),
+			MAE:         rt.MAE,
+			MFE:         rt.MFE,
+			EntryReason: rt.EntryReason,
+			ExitReason:  rt.ExitReason,
+			Strategy:    rt.Strategy,
+		})
+	}
+	return rows
+}
+
+// equityRows converts the equity curve, adding the drawdown at each point
+func equityRows(results *Results) []equityRow {
+	rows := make([]equityRow, 0, len(results.EquityCurve))
+	peak := results.InitialCapital
+	for _, point := range results.EquityCurve {
+		if point.Value > peak {
+			peak = point.Value
+		}
+		drawdown := 0.0
+		if peak > 0 {
+			drawdown = (peak - point.Value) / peak
+		}
+		rows = append(rows, equityRow{Timestamp: point.Timestamp, Value: point.Value, Drawdown: drawdown})
+	}
+	return rows
+}
+
+// rollingRows flattens the rolling metrics of every window
+func rollingRows(results *Results) []rollingRow {
+	var rows []rollingRow
+	for _, rolling := range results.Rolling {
+		for _, point := range rolling.Points {
+			rows = append(rows, rollingRow{
+				Window:     int64(rolling.Window),
+				Timestamp:  point.Timestamp,
+				Return:     point.Return,
+				Sharpe:     point.Sharpe,
+				Volatility: point.Volatility,
+				Beta:       point.Beta,
+				WinRate:    point.WinRate,
+				RoundTrips: int64(point.RoundTrips),
+				Drawdown:   point.Drawdown,
+			})
+		}
+	}
+	return rows
+}
+
+// writeJSON writes v as indented JSON
+func writeJSON(path string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", filepath.Base(path), err)
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// writeCSV writes a header and one record per row
+func writeCSV[T interface{ record() []string }](path string, header []string, rows []T) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		if err := writer.Write(row.record()); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// formatFloat formats a value with the fewest digits that round-trip
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// sanitizeName makes a strategy name safe to use in a directory name
+func sanitizeName(name string) string {
+	if name == "" {
+		return "backtest"
+	}
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		}
+		return '_'
+	}, name)
+}
@@ -0,0 +1,179 @@
+package backtester
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/ridopark/JonBuhTrader/pkg/strategy"
+)
+
+func TestParseExportFormats(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    []ExportFormat
+		wantErr bool
+	}{
+		{value: "json", want: []ExportFormat{ExportJSON}},
+		{value: " CSV , Parquet ", want: []ExportFormat{ExportCSV, ExportParquet}},
+		{value: "csv,json,csv,,JSON", want: []ExportFormat{ExportCSV, ExportJSON}},
+		{value: "", want: nil},
+		{value: "json,xml", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseExportFormats(tt.value)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseExportFormats(%q) = %v, want an error", tt.value, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseExportFormats(%q): %v", tt.value, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseExportFormats(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+// exportResults runs a buy at 100 and a sale at 110 over three days
+func exportResults(t *testing.T) *Results {
+	t.Helper()
+	start := time.Date(2024, 3, 1, 15, 0, 0, 0, time.UTC)
+	timestamps := []time.Time{start, start.AddDate(0, 0, 1), start.AddDate(0, 0, 2)}
+	s := &scriptedStrategy{onDataPoint: func(ctx strategy.Context, index int, dataPoint strategy.DataPoint) []strategy.Order {
+		switch index {
+		case 0:
+			return []strategy.Order{marketOrder(strategy.OrderSideBuy, 10)}
+		case 2:
+			return []strategy.Order{marketOrder(strategy.OrderSideSell, 10)}
+		}
+		return nil
+	}}
+	engine := newFrictionlessEngine(s, priceFeed(timestamps, []float64{100, 95, 110}))
+	if err := engine.Run(); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	return engine.GetResults()
+}
+
+// readCSV returns every record of a CSV file, header included
+func readCSV(t *testing.T, path string) [][]string {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open %s: %v", filepath.Base(path), err)
+	}
+	defer file.Close()
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatalf("read %s: %v", filepath.Base(path), err)
+	}
+	return records
+}
+
+func TestExportWritesEveryFormat(t *testing.T) {
+	results := exportResults(t)
+	config := RunConfig{
+		Strategy:   "ma crossover/v2",
+		Parameters: map[string]interface{}{"fast": 10.0},
+		RunAt:      time.Date(2024, 4, 1, 9, 30, 15, 0, time.UTC),
+	}
+	runDir, err := Export(t.TempDir(), config, results, []ExportFormat{ExportJSON, ExportCSV, ExportParquet})
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	if name := filepath.Base(runDir); name != "ma_crossover_v2_20240401_093015" {
+		t.Errorf("run directory = %q, want ma_crossover_v2_20240401_093015", name)
+	}
+
+	data, err := os.ReadFile(filepath.Join(runDir, "run.json"))
+	if err != nil {
+		t.Fatalf("read run.json: %v", err)
+	}
+	var report RunReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("decode run.json: %v", err)
+	}
+	if report.Config.Strategy != config.Strategy || !report.Config.RunAt.Equal(config.RunAt) || report.Config.Parameters["fast"] != 10.0 {
+		t.Errorf("run.json config = %+v, want %+v", report.Config, config)
+	}
+	if report.Results == nil || len(report.Results.Trades) != len(results.Trades) || report.Results.Metrics == nil {
+		t.Fatalf("run.json results = %+v, want %d trades and metrics", report.Results, len(results.Trades))
+	}
+
+	files := []struct {
+		name   string
+		header []string
+		rows   int
+	}{
+		{"trades", tradeColumns, len(results.Trades)},
+		{"round_trips", roundTripColumns, len(results.RoundTrips)},
+		{"equity", equityColumns, len(results.EquityCurve)},
+		{"rolling", rollingColumns, len(rollingRows(results))},
+	}
+	for _, file := range files {
+		records := readCSV(t, filepath.Join(runDir, file.name+".csv"))
+		if len(records) == 0 || !reflect.DeepEqual(records[0], file.header) {
+			t.Errorf("%s.csv header = %v, want %v", file.name, records, file.header)
+			continue
+		}
+		if len(records)-1 != file.rows {
+			t.Errorf("%s.csv has %d rows, want %d", file.name, len(records)-1, file.rows)
+		}
+		if _, err := os.Stat(filepath.Join(runDir, file.name+".parquet")); err != nil {
+			t.Errorf("%s.parquet: %v", file.name, err)
+		}
+	}
+
+	trades := readCSV(t, filepath.Join(runDir, "trades.csv"))
+	if len(trades) != 3 || trades[1][3] != testSymbol || trades[1][4] != string(strategy.OrderSideBuy) || trades[1][5] != "10" || trades[1][6] != "100" || trades[1][11] != "1" {
+		t.Errorf("trades.csv = %v, want a buy of 10 %s at 100 in the base currency and a sale", trades, testSymbol)
+	}
+	// Parquet keeps the equity curve and its drawdown exactly
+	equity, err := parquet.ReadFile[equityRow](filepath.Join(runDir, "equity.parquet"))
+	if err != nil {
+		t.Fatalf("read equity.parquet: %v", err)
+	}
+	want := equityRows(results)
+	if len(equity) != len(want) {
+		t.Fatalf("equity.parquet has %d rows, want %d", len(equity), len(want))
+	}
+	for i := range want {
+		if !equity[i].Timestamp.Equal(want[i].Timestamp) || equity[i].Value != want[i].Value || equity[i].Drawdown != want[i].Drawdown {
+			t.Errorf("equity.parquet row %d = %+v, want %+v", i, equity[i], want[i])
+		}
+	}
+	roundTrips, err := parquet.ReadFile[roundTripRow](filepath.Join(runDir, "round_trips.parquet"))
+	if err != nil {
+		t.Fatalf("read round_trips.parquet: %v", err)
+	}
+	if len(roundTrips) != 1 || roundTrips[0].Symbol != testSymbol || roundTrips[0].GrossPL != 100 {
+		t.Errorf("round_trips.parquet = %+v, want one %s round trip with 100 gross P&L", roundTrips, testSymbol)
+	}
+}
+
+func TestExportKeepsSameSecondRunsApart(t *testing.T) {
+	outputDir := filepath.Join(t.TempDir(), "runs")
+	config := RunConfig{Strategy: "scripted", RunAt: time.Date(2024, 4, 1, 9, 30, 15, 0, time.UTC)}
+
+	var runDirs []string
+	for i := 0; i < 3; i++ {
+		runDir, err := Export(outputDir, config, exportResults(t), []ExportFormat{ExportJSON})
+		if err != nil {
+			t.Fatalf("Export %d: %v", i, err)
+		}
+		runDirs = append(runDirs, filepath.Base(runDir))
+	}
+	want := []string{"scripted_20240401_093015", "scripted_20240401_093015_2", "scripted_20240401_093015_3"}
+	if !reflect.DeepEqual(runDirs, want) {
+		t.Errorf("run directories = %v, want %v", runDirs, want)
+	}
+}
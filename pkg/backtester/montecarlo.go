@@ -0,0 +1,329 @@
+package backtester
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MonteCarloMethod is a way of generating alternative equity paths from a run
+type MonteCarloMethod string
+
+// Monte Carlo methods
+const (
+	MonteCarloReshuffle MonteCarloMethod = "reshuffle" // Round trips in random order
+	MonteCarloBootstrap MonteCarloMethod = "bootstrap" // Daily returns resampled with replacement
+	MonteCarloSkip      MonteCarloMethod = "skip"      // Each round trip dropped with SkipProbability
+)
+
+// Monte Carlo defaults
+const (
+	DefaultMonteCarloSimulations = 1000
+	DefaultSkipProbability       = 0.1
+	DefaultRuinDrawdown          = 0.5
+	DefaultConfidenceLevel       = 0.95
+)
+
+// MonteCarloConfig configures a Monte Carlo analysis. Zero values use the defaults.
+type MonteCarloConfig struct {
+	Simulations     int                `json:"simulations"`      // Paths per method
+	Methods         []MonteCarloMethod `json:"methods"`          // All methods when empty
+	SkipProbability *float64           `json:"skip_probability"` // Default when nil, so 0 skips nothing
+	RuinDrawdown    float64            `json:"ruin_drawdown"`    // Drawdown counted as ruin, as a decimal
+	ConfidenceLevel float64            `json:"confidence_level"` // Two-sided interval width, as a decimal
+	Workers         int                `json:"workers"`          // Parallel workers (0 = one per CPU)
+	Seed            uint64             `json:"seed"`             // Random seed (0 = time-based)
+}
+
+// ConfidenceInterval summarizes the distribution of a simulated statistic
+type ConfidenceInterval struct {
+	Lower  float64 `json:"lower"`
+	Median float64 `json:"median"`
+	Upper  float64 `json:"upper"`
+	Mean   float64 `json:"mean"`
+}
+
+// MonteCarloResult summarizes the paths of one method. Returns and drawdowns are in percent.
+type MonteCarloResult struct {
+	Method            MonteCarloMethod   `json:"method"`
+	Simulations       int                `json:"simulations"`
+	FinalReturn       ConfidenceInterval `json:"final_return"`
+	MaxDrawdown       ConfidenceInterval `json:"max_drawdown"`
+	SharpeRatio       ConfidenceInterval `json:"sharpe_ratio"`
+	ProbabilityOfRuin float64            `json:"probability_of_ruin"` // Share of paths reaching the ruin drawdown, in percent
+	ProbabilityOfLoss float64            `json:"probability_of_loss"` // Share of paths ending below the initial capital, in percent
+}
+
+// MonteCarloSkipped records a method the run did not have the data for
+type MonteCarloSkipped struct {
+	Method MonteCarloMethod `json:"method"`
+	Reason string           `json:"reason"`
+}
+
+// MonteCarloAnalysis is the outcome of a Monte Carlo analysis of a run
+type MonteCarloAnalysis struct {
+	Config  MonteCarloConfig    `json:"config"`
+	Results []MonteCarloResult  `json:"results"`
+	Skipped []MonteCarloSkipped `json:"skipped,omitempty"`
+}
+
+// ParseMonteCarloMethods parses a comma-separated list of Monte Carlo methods
+func ParseMonteCarloMethods(value string) ([]MonteCarloMethod, error) {
+	var methods []MonteCarloMethod
+	seen := make(map[MonteCarloMethod]bool)
+	for _, part := range strings.Split(value, ",") {
+		method := MonteCarloMethod(strings.ToLower(strings.TrimSpace(part)))
+		if method == "" || seen[method] {
+			continue
+		}
+		switch method {
+		case MonteCarloReshuffle, MonteCarloBootstrap, MonteCarloSkip:
+		default:
+			return nil, fmt.Errorf("unknown Monte Carlo method %q (expected reshuffle, bootstrap or skip)", part)
+		}
+		seen[method] = true
+		methods = append(methods, method)
+	}
+	return methods, nil
+}
+
+// pathOutcome is the measurement of one simulated path
+type pathOutcome struct {
+	finalReturn, maxDrawdown, sharpe float64
+}
+
+// RunMonteCarlo simulates alternative equity paths from the results' round trips and
+// daily returns. Round-trip paths add each round trip's net P&L, in the base currency, to
+// the initial capital, so reshuffling changes drawdowns but not the final return; their
+// Sharpe ratio is annualized at the run's round trips per year. Paths are simulated in
+// parallel, and a non-zero seed makes the analysis reproducible. Methods the run lacks
+// the round trips or returns for are skipped and recorded rather than failing the analysis.
+func RunMonteCarlo(results *Results, config MonteCarloConfig) (*MonteCarloAnalysis, error) {
+	if config.Simulations == 0 {
+		config.Simulations = DefaultMonteCarloSimulations
+	}
+	if len(config.Methods) == 0 {
+		config.Methods = []MonteCarloMethod{MonteCarloReshuffle, MonteCarloBootstrap, MonteCarloSkip}
+	}
+	if config.SkipProbability == nil {
+		skip := DefaultSkipProbability
+		config.SkipProbability = &skip
+	}
+	if config.RuinDrawdown == 0 {
+		config.RuinDrawdown = DefaultRuinDrawdown
+	}
+	if config.ConfidenceLevel == 0 {
+		config.ConfidenceLevel = DefaultConfidenceLevel
+	}
+	if config.Workers <= 0 {
+		config.Workers = runtime.NumCPU()
+	}
+	if config.Seed == 0 {
+		config.Seed = uint64(time.Now().UnixNano())
+	}
+
+	switch {
+	case config.Simulations < 0:
+		return nil, fmt.Errorf("simulations must be positive, got %d", config.Simulations)
+	case *config.SkipProbability < 0 || *config.SkipProbability >= 1:
+		return nil, fmt.Errorf("skip probability must be in [0, 1), got %v", *config.SkipProbability)
+	case config.RuinDrawdown <= 0 || config.RuinDrawdown > 1:
+		return nil, fmt.Errorf("ruin drawdown must be in (0, 1], got %v", config.RuinDrawdown)
+	case config.ConfidenceLevel <= 0 || config.ConfidenceLevel >= 1:
+		return nil, fmt.Errorf("confidence level must be in (0, 1), got %v", config.ConfidenceLevel)
+	case results.InitialCapital <= 0:
+		return nil, fmt.Errorf("initial capital must be positive, got %v", results.InitialCapital)
+	}
+	for _, method := range config.Methods {
+		switch method {
+		case MonteCarloReshuffle, MonteCarloBootstrap, MonteCarloSkip:
+		default:
+			return nil, fmt.Errorf("unknown Monte Carlo method %q", method)
+		}
+	}
+
+	returns := results.DailyReturns()
+	pnl := make([]float64, len(results.RoundTrips))
+	for i, rt := range results.RoundTrips {
+		pnl[i] = rt.BaseNetPL
+	}
+	periods := float64(results.periodsPerYear())
+	tradesPerYear := 0.0
+	if len(returns) > 0 {
+		tradesPerYear = float64(len(pnl)) * periods / float64(len(returns))
+	}
+
+	analysis := &MonteCarloAnalysis{Config: config}
+	for methodIndex, method := range config.Methods {
+		var simulate func(rng *rand.Rand) pathOutcome
+		switch method {
+		case MonteCarloReshuffle, MonteCarloSkip:
+			if len(pnl) == 0 {
+				analysis.Skipped = append(analysis.Skipped, MonteCarloSkipped{Method: method, Reason: "no round trips"})
+				continue
+			}
+			skip := 0.0
+			if method == MonteCarloSkip {
+				skip = *config.SkipProbability
+			}
+			simulate = func(rng *rand.Rand) pathOutcome {
+				path := make([]float64, 0, len(pnl))
+				for _, p := range pnl {
+					if skip == 0 || rng.Float64() >= skip {
+						path = append(path, p)
+					}
+				}
+				if method == MonteCarloReshuffle {
+					rng.Shuffle(len(path), func(i, j int) { path[i], path[j] = path[j], path[i] })
+				}
+				return measurePnLPath(path, results.InitialCapital, tradesPerYear, results.RiskFreeRate)
+			}
+		case MonteCarloBootstrap:
+			if len(returns) < 2 {
+				analysis.Skipped = append(analysis.Skipped, MonteCarloSkipped{
+					Method: method,
+					Reason: fmt.Sprintf("needs at least 2 daily returns, got %d", len(returns)),
+				})
+				continue
+			}
+			simulate = func(rng *rand.Rand) pathOutcome {
+				path := make([]float64, len(returns))
+				for i := range path {
+					path[i] = returns[rng.IntN(len(returns))]
+				}
+				return measureReturnPath(path, periods, results.RiskFreeRate)
+			}
+		}
+
+		outcomes := simulatePaths(config, uint64(methodIndex), simulate)
+		analysis.Results = append(analysis.Results, summarizePaths(method, outcomes, config))
+	}
+	return analysis, nil
+}
+
+// simulatePaths runs the simulations across the configured workers. Each path gets its
+// own generator seeded by the path index, so outcomes do not depend on scheduling.
+func simulatePaths(config MonteCarloConfig, stream uint64, simulate func(*rand.Rand) pathOutcome) []pathOutcome {
+	outcomes := make([]pathOutcome, config.Simulations)
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < config.Workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				rng := rand.New(rand.NewPCG(config.Seed, stream<<32|uint64(i)))
+				outcomes[i] = simulate(rng)
+			}
+		}()
+	}
+	for i := range outcomes {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return outcomes
+}
+
+// measurePnLPath measures an equity path built by adding each base-currency P&L to the
+// initial capital
+func measurePnLPath(pnl []float64, initialCapital, tradesPerYear, riskFreeRate float64) pathOutcome {
+	equity, peak, maxDrawdown := initialCapital, initialCapital, 0.0
+	returns := make([]float64, 0, len(pnl))
+	for _, p := range pnl {
+		if equity <= 0 {
+			break
+		}
+		returns = append(returns, p/equity)
+		equity += p
+		peak = math.Max(peak, equity)
+		maxDrawdown = math.Max(maxDrawdown, math.Min(1-equity/peak, 1))
+	}
+	return pathOutcome{
+		finalReturn: (equity/initialCapital - 1) * 100,
+		maxDrawdown: maxDrawdown * 100,
+		sharpe:      annualizedSharpe(returns, tradesPerYear, riskFreeRate),
+	}
+}
+
+// measureReturnPath measures an equity path compounded from period returns
+func measureReturnPath(returns []float64, periodsPerYear, riskFreeRate float64) pathOutcome {
+	growth, peak, maxDrawdown := 1.0, 1.0, 0.0
+	for _, ret := range returns {
+		growth *= 1 + ret
+		peak = math.Max(peak, growth)
+		maxDrawdown = math.Max(maxDrawdown, math.Min(1-growth/peak, 1))
+	}
+	return pathOutcome{
+		finalReturn: (growth - 1) * 100,
+		maxDrawdown: maxDrawdown * 100,
+		sharpe:      annualizedSharpe(returns, periodsPerYear, riskFreeRate),
+	}
+}
+
+// annualizedSharpe returns the Sharpe ratio of returns net of the risk-free rate
+func annualizedSharpe(returns []float64, periodsPerYear, riskFreeRate float64) float64 {
+	volatility := stdDev(returns)
+	if volatility == 0 || periodsPerYear <= 0 {
+		return 0
+	}
+	return (mean(returns) - riskFreeRate/periodsPerYear) / volatility * math.Sqrt(periodsPerYear)
+}
+
+// summarizePaths computes the confidence intervals and probabilities of the outcomes
+func summarizePaths(method MonteCarloMethod, outcomes []pathOutcome, config MonteCarloConfig) MonteCarloResult {
+	result := MonteCarloResult{Method: method, Simulations: len(outcomes)}
+	if len(outcomes) == 0 {
+		return result
+	}
+
+	finalReturns := make([]float64, len(outcomes))
+	drawdowns := make([]float64, len(outcomes))
+	sharpes := make([]float64, len(outcomes))
+	ruined, losses := 0, 0
+	for i, outcome := range outcomes {
+		finalReturns[i], drawdowns[i], sharpes[i] = outcome.finalReturn, outcome.maxDrawdown, outcome.sharpe
+		if outcome.maxDrawdown >= config.RuinDrawdown*100 {
+			ruined++
+		}
+		if outcome.finalReturn < 0 {
+			losses++
+		}
+	}
+
+	result.FinalReturn = confidenceInterval(finalReturns, config.ConfidenceLevel)
+	result.MaxDrawdown = confidenceInterval(drawdowns, config.ConfidenceLevel)
+	result.SharpeRatio = confidenceInterval(sharpes, config.ConfidenceLevel)
+	result.ProbabilityOfRuin = float64(ruined) / float64(len(outcomes)) * 100
+	result.ProbabilityOfLoss = float64(losses) / float64(len(outcomes)) * 100
+	return result
+}
+
+// confidenceInterval returns the central interval of values at the confidence level
+func confidenceInterval(values []float64, level float64) ConfidenceInterval {
+	sort.Float64s(values)
+	tail := (1 - level) / 2
+	return ConfidenceInterval{
+		Lower:  percentile(values, tail),
+		Median: percentile(values, 0.5),
+		Upper:  percentile(values, 1-tail),
+		Mean:   mean(values),
+	}
+}
+
+// percentile interpolates the q quantile of sorted values
+func percentile(sorted []float64, q float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	position := q * float64(len(sorted)-1)
+	lower := int(math.Floor(position))
+	upper := int(math.Ceil(position))
+	weight := position - float64(lower)
+	return sorted[lower]*(1-weight) + sorted[upper]*weight
+}
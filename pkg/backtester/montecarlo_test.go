@@ -0,0 +1,144 @@
+package backtester
+
+import (
+	"reflect"
+	"testing"
+)
+
+// monteCarloResults has daily returns for bootstrapping and round trips in EUR whose
+// base-currency P&L is 1.2 times their EUR P&L
+func monteCarloResults() *Results {
+	pnl := []float64{300, -200, 150, -400, 250, 100, -50, 500}
+	roundTrips := make([]RoundTrip, len(pnl))
+	for i, p := range pnl {
+		roundTrips[i] = RoundTrip{NetPL: p, BaseNetPL: p * 1.2}
+	}
+	return &Results{
+		InitialCapital: 10000,
+		EquityCurve:    dailyCurve(10000, []float64{0.01, -0.02, 0.015, 0.005, -0.01, 0.02, -0.005, 0.01}),
+		RoundTrips:     roundTrips,
+	}
+}
+
+func TestMonteCarloIsDeterministicForASeed(t *testing.T) {
+	results := monteCarloResults()
+	config := MonteCarloConfig{Simulations: 200, Seed: 42, Workers: 1}
+	first, err := RunMonteCarlo(results, config)
+	if err != nil {
+		t.Fatalf("RunMonteCarlo: %v", err)
+	}
+
+	// Outcomes do not depend on how paths are spread across workers
+	config.Workers = 4
+	second, err := RunMonteCarlo(results, config)
+	if err != nil {
+		t.Fatalf("RunMonteCarlo: %v", err)
+	}
+	if !reflect.DeepEqual(first.Results, second.Results) {
+		t.Errorf("same seed gave different outcomes:\n%+v\n%+v", first.Results, second.Results)
+	}
+
+	config.Seed = 43
+	third, err := RunMonteCarlo(results, config)
+	if err != nil {
+		t.Fatalf("RunMonteCarlo: %v", err)
+	}
+	if reflect.DeepEqual(first.Results[1], third.Results[1]) {
+		t.Error("different seeds gave the same bootstrap outcomes")
+	}
+}
+
+func TestMonteCarloReshuffleKeepsFinalReturn(t *testing.T) {
+	results := monteCarloResults()
+	analysis, err := RunMonteCarlo(results, MonteCarloConfig{Simulations: 500, Methods: []MonteCarloMethod{MonteCarloReshuffle}, Seed: 7})
+	if err != nil {
+		t.Fatalf("RunMonteCarlo: %v", err)
+	}
+	reshuffle := analysis.Results[0]
+
+	// Every ordering adds the same base-currency P&L: 650 EUR at 1.2
+	want := 650 * 1.2 / 10000 * 100
+	for name, value := range map[string]float64{
+		"lower":  reshuffle.FinalReturn.Lower,
+		"median": reshuffle.FinalReturn.Median,
+		"upper":  reshuffle.FinalReturn.Upper,
+		"mean":   reshuffle.FinalReturn.Mean,
+	} {
+		assertClose(t, "final return "+name, value, want)
+	}
+	if reshuffle.ProbabilityOfLoss != 0 {
+		t.Errorf("probability of loss = %v, want 0", reshuffle.ProbabilityOfLoss)
+	}
+	// The order changes drawdowns, which never exceed every loss in a row from the initial capital
+	if reshuffle.MaxDrawdown.Lower >= reshuffle.MaxDrawdown.Upper {
+		t.Errorf("max drawdown interval = %+v, want a spread", reshuffle.MaxDrawdown)
+	}
+	if reshuffle.MaxDrawdown.Upper > (200+400+50)*1.2/10000*100 {
+		t.Errorf("max drawdown upper bound = %v, beyond all losses in a row", reshuffle.MaxDrawdown.Upper)
+	}
+}
+
+func TestMonteCarloRejectsInvalidConfig(t *testing.T) {
+	results := monteCarloResults()
+	certain := 1.0
+	for _, config := range []MonteCarloConfig{
+		{Simulations: -1},
+		{SkipProbability: &certain},
+		{RuinDrawdown: 1.5},
+		{ConfidenceLevel: 1},
+		{Methods: []MonteCarloMethod{"jackknife"}},
+	} {
+		if _, err := RunMonteCarlo(results, config); err == nil {
+			t.Errorf("RunMonteCarlo(%+v) succeeded, want an error", config)
+		}
+	}
+}
+
+func TestMonteCarloSkipsMethodsWithoutData(t *testing.T) {
+	// Daily returns to bootstrap but no round trips to reshuffle or skip
+	results := monteCarloResults()
+	results.RoundTrips = nil
+	analysis, err := RunMonteCarlo(results, MonteCarloConfig{Simulations: 10, Seed: 1})
+	if err != nil {
+		t.Fatalf("RunMonteCarlo: %v", err)
+	}
+	if len(analysis.Results) != 1 || analysis.Results[0].Method != MonteCarloBootstrap {
+		t.Errorf("results = %+v, want bootstrap only", analysis.Results)
+	}
+	want := []MonteCarloSkipped{
+		{Method: MonteCarloReshuffle, Reason: "no round trips"},
+		{Method: MonteCarloSkip, Reason: "no round trips"},
+	}
+	if !reflect.DeepEqual(analysis.Skipped, want) {
+		t.Errorf("skipped = %+v, want %+v", analysis.Skipped, want)
+	}
+}
+
+func TestMonteCarloZeroSkipProbabilityKeepsEveryRoundTrip(t *testing.T) {
+	none := 0.0
+	analysis, err := RunMonteCarlo(monteCarloResults(), MonteCarloConfig{
+		Simulations:     50,
+		Methods:         []MonteCarloMethod{MonteCarloSkip},
+		SkipProbability: &none,
+		Seed:            3,
+	})
+	if err != nil {
+		t.Fatalf("RunMonteCarlo: %v", err)
+	}
+	if *analysis.Config.SkipProbability != 0 {
+		t.Errorf("skip probability = %v, want 0", *analysis.Config.SkipProbability)
+	}
+	// Every path adds all round trips in their original order
+	finalReturn := analysis.Results[0].FinalReturn
+	assertClose(t, "final return lower", finalReturn.Lower, 650*1.2/10000*100)
+	assertClose(t, "final return upper", finalReturn.Upper, 650*1.2/10000*100)
+
+	// A nil probability uses the default
+	analysis, err = RunMonteCarlo(monteCarloResults(), MonteCarloConfig{Simulations: 1, Methods: []MonteCarloMethod{MonteCarloSkip}, Seed: 3})
+	if err != nil {
+		t.Fatalf("RunMonteCarlo: %v", err)
+	}
+	if *analysis.Config.SkipProbability != DefaultSkipProbability {
+		t.Errorf("default skip probability = %v, want %v", *analysis.Config.SkipProbability, DefaultSkipProbability)
+	}
+}
@@ -1,6 +1,7 @@
 package backtester
 
 import (
+	"fmt"
 	"math"
 	"time"
 
@@ -9,14 +10,33 @@ import (
 
 // Portfolio manages positions, cash, and P&L tracking
 type Portfolio struct {
-	cash             float64
+	cash             map[string]float64 // currency -> cash balance
+	baseCurrency     string
 	initialCash      float64
 	positions        map[string]*strategy.Position
 	trades           []strategy.TradeEvent
 	totalValue       float64
 	commissionConfig *CommissionConfig
 
+	// Multi-currency support
+	instruments  map[string]*strategy.Instrument // symbol -> instrument reference data
+	fxRates      *FXRateTable
+	currentRates map[string]float64 // currency -> base currency rate as of the current bar
+
+	// Futures support
+	lastPrices       map[string]float64 // symbol -> last known price
+	settlementPrices map[string]float64 // symbol -> price futures positions were last marked to market at
+
+	// Currency attribution (base currency)
+	realizedAssetPL float64 // Realized P&L at entry FX rates, net of fees
+
+	// Outcome of the most recent trade that closed (part of) a position
+	lastTradeClosed bool
+	lastTradePL     float64 // Realized P&L net of fees in the base currency
+
 	// Performance tracking
+	dayStartValue   float64 // Total value at the start of the current session
+	snapshots       []strategy.DailySnapshot
 	dailyReturns    []float64
 	equity          []EquityPoint
 	maxDrawdown     float64
@@ -26,27 +46,172 @@ type Portfolio struct {
 
 // EquityPoint represents equity at a point in time
 type EquityPoint struct {
-	Timestamp time.Time
-	Value     float64
+	Timestamp time.Time `json:"timestamp"`
+	Value     float64   `json:"value"`
 }
 
 // NewPortfolio creates a new portfolio with the given initial capital
 func NewPortfolio(initialCapital float64, commissionConfig *CommissionConfig) *Portfolio {
 	return &Portfolio{
-		cash:             initialCapital,
+		cash:             map[string]float64{DefaultBaseCurrency: initialCapital},
+		baseCurrency:     DefaultBaseCurrency,
 		initialCash:      initialCapital,
 		positions:        make(map[string]*strategy.Position),
 		trades:           make([]strategy.TradeEvent, 0),
 		totalValue:       initialCapital,
 		commissionConfig: commissionConfig,
+		instruments:      make(map[string]*strategy.Instrument),
+		currentRates:     make(map[string]float64),
+		lastPrices:       make(map[string]float64),
+		settlementPrices: make(map[string]float64),
 		equity:           make([]EquityPoint, 0),
 		peakValue:        initialCapital,
+		dayStartValue:    initialCapital,
+	}
+}
+
+// SetBaseCurrency sets the currency the initial capital and portfolio value are expressed in.
+// It must be called before any trades are executed.
+func (p *Portfolio) SetBaseCurrency(currency string) {
+	p.cash = map[string]float64{currency: p.cash[p.baseCurrency]}
+	p.baseCurrency = currency
+}
+
+// GetBaseCurrency returns the base currency of the portfolio
+func (p *Portfolio) GetBaseCurrency() string {
+	return p.baseCurrency
+}
+
+// SetInstrument registers reference data for a symbol
+func (p *Portfolio) SetInstrument(instrument strategy.Instrument) {
+	p.instruments[instrument.Symbol] = &instrument
+}
+
+// SetFXRates sets the FX rate table used to convert foreign currencies into the base currency
+func (p *Portfolio) SetFXRates(table *FXRateTable) {
+	p.fxRates = table
+}
+
+// GetInstrument returns the reference data for a symbol, or nil if none was registered
+func (p *Portfolio) GetInstrument(symbol string) *strategy.Instrument {
+	return p.instruments[symbol]
+}
+
+// isFuture returns true if the symbol is a registered futures contract
+func (p *Portfolio) isFuture(symbol string) bool {
+	instrument, exists := p.instruments[symbol]
+	return exists && instrument.IsFuture()
+}
+
+// GetMultiplier returns the contract multiplier for a symbol (1 for non-futures)
+func (p *Portfolio) GetMultiplier(symbol string) float64 {
+	if instrument, exists := p.instruments[symbol]; exists {
+		return instrument.GetMultiplier()
+	}
+	return 1.0
+}
+
+// GetLastPrice returns the last known price for a symbol
+func (p *Portfolio) GetLastPrice(symbol string) float64 {
+	return p.lastPrices[symbol]
+}
+
+// GetCurrency returns the currency a symbol trades in, defaulting to the base currency
+func (p *Portfolio) GetCurrency(symbol string) string {
+	if instrument, exists := p.instruments[symbol]; exists && instrument.Currency != "" {
+		return instrument.Currency
+	}
+	return p.baseCurrency
+}
+
+// ValidateFXRates checks that every instrument currency can be converted into the base currency
+func (p *Portfolio) ValidateFXRates() error {
+	for symbol := range p.instruments {
+		currency := p.GetCurrency(symbol)
+		if currency == p.baseCurrency {
+			continue
+		}
+		if p.fxRates == nil || p.fxRates.GetBaseCurrency() != p.baseCurrency || !p.fxRates.HasRates(currency) {
+			return fmt.Errorf("no FX rates to convert %s (%s) into base currency %s", currency, symbol, p.baseCurrency)
+		}
+	}
+	return nil
+}
+
+// UpdateFXRates refreshes the conversion rates for all currencies held or traded as of the given time
+func (p *Portfolio) UpdateFXRates(timestamp time.Time) error {
+	currencies := make(map[string]bool)
+	for currency := range p.cash {
+		currencies[currency] = true
+	}
+	for symbol := range p.instruments {
+		currencies[p.GetCurrency(symbol)] = true
+	}
+
+	for currency := range currencies {
+		if currency == p.baseCurrency {
+			continue
+		}
+		if p.fxRates == nil {
+			return fmt.Errorf("no FX rates configured for %s/%s", currency, p.baseCurrency)
+		}
+		rate, err := p.fxRates.GetRate(currency, timestamp)
+		if err != nil {
+			return err
+		}
+		p.currentRates[currency] = rate
+	}
+
+	return nil
+}
+
+// GetFXRate returns the current rate for converting one unit of currency into the base currency
+func (p *Portfolio) GetFXRate(currency string) float64 {
+	if currency == p.baseCurrency || currency == "" {
+		return 1.0
 	}
+	return p.currentRates[currency]
 }
 
-// GetCash returns the current cash balance
+// GetCash returns the total cash balance converted to the base currency
 func (p *Portfolio) GetCash() float64 {
-	return p.cash
+	total := 0.0
+	for currency, balance := range p.cash {
+		total += balance * p.GetFXRate(currency)
+	}
+	return total
+}
+
+// GetCashBalance returns the cash balance held in a single currency
+func (p *Portfolio) GetCashBalance(currency string) float64 {
+	return p.cash[currency]
+}
+
+// GetCashBalances returns a copy of the cash balances per currency
+func (p *Portfolio) GetCashBalances() map[string]float64 {
+	balances := make(map[string]float64, len(p.cash))
+	for currency, balance := range p.cash {
+		balances[currency] = balance
+	}
+	return balances
+}
+
+// creditCash adds an amount to a currency balance
+func (p *Portfolio) creditCash(currency string, amount float64) {
+	p.cash[currency] += amount
+}
+
+// debitCash withdraws an amount from a currency balance. Any shortfall in a
+// foreign currency is converted from the base currency at the current rate.
+func (p *Portfolio) debitCash(currency string, amount float64) {
+	if currency != p.baseCurrency {
+		shortfall := amount - math.Max(p.cash[currency], 0)
+		if shortfall > 0 {
+			p.cash[p.baseCurrency] -= shortfall * p.GetFXRate(currency)
+			p.cash[currency] += shortfall
+		}
+	}
+	p.cash[currency] -= amount
 }
 
 // GetPosition returns the position for a symbol, or nil if no position exists
@@ -83,13 +248,26 @@ func (p *Portfolio) GetTotalReturn() float64 {
 func (p *Portfolio) ExecuteTrade(trade strategy.TradeEvent, currentPrice float64) error {
 	symbol := trade.Symbol
 
+	currency := p.GetCurrency(symbol)
+	fxRate := p.GetFXRate(currency)
+	multiplier := p.GetMultiplier(symbol)
+	isFuture := p.isFuture(symbol)
+
+	// Futures positions are marked to the trade price before they change so that
+	// only the P&L since the last settlement flows through variation margin
+	if isFuture {
+		p.markToMarket(symbol, trade.Price)
+	}
+
 	// Get or create position
 	position, exists := p.positions[symbol]
 	if !exists {
 		position = &strategy.Position{
-			Symbol:   symbol,
-			Quantity: 0,
-			AvgPrice: 0,
+			Symbol:    symbol,
+			Quantity:  0,
+			AvgPrice:  0,
+			Currency:  currency,
+			AvgFXRate: fxRate,
 		}
 		p.positions[symbol] = position
 	}
@@ -99,22 +277,28 @@ func (p *Portfolio) ExecuteTrade(trade strategy.TradeEvent, currentPrice float64
 	totalFees := trade.Commission + trade.SecFee + trade.FinraTaf + trade.Slippage
 	totalCost := tradeValue + totalFees
 
+	// Fees are a trading cost and count against asset P&L
+	p.realizedAssetPL -= totalFees * fxRate
+	p.lastTradeClosed = false
+	p.lastTradePL = -totalFees * fxRate
+
 	// Update position based on trade side
 	if trade.Side == strategy.OrderSideBuy {
 		if position.Quantity >= 0 {
 			// Adding to long position or opening new long position
 			newQuantity := position.Quantity + trade.Quantity
+			position.AvgFXRate = blendFXRate(position, trade.Quantity, trade.Price, fxRate)
 			position.AvgPrice = ((position.AvgPrice * position.Quantity) + (trade.Price * trade.Quantity)) / newQuantity
 			position.Quantity = newQuantity
-			p.cash -= totalCost
 		} else {
 			// Covering short position
 			if math.Abs(trade.Quantity) <= math.Abs(position.Quantity) {
 				// Partial or full cover
-				realizedPL := (position.AvgPrice - trade.Price) * trade.Quantity
+				realizedPL := (position.AvgPrice - trade.Price) * trade.Quantity * multiplier
 				position.RealizedPL += realizedPL
+				p.realizedAssetPL += realizedPL * position.AvgFXRate
+				p.recordClosedPL(realizedPL * position.AvgFXRate)
 				position.Quantity += trade.Quantity
-				p.cash -= totalCost
 
 				if position.Quantity == 0 {
 					position.AvgPrice = 0
@@ -122,35 +306,38 @@ func (p *Portfolio) ExecuteTrade(trade strategy.TradeEvent, currentPrice float64
 			} else {
 				// Cover and reverse
 				coverQuantity := math.Abs(position.Quantity)
-				realizedPL := (position.AvgPrice - trade.Price) * coverQuantity
+				realizedPL := (position.AvgPrice - trade.Price) * coverQuantity * multiplier
 				position.RealizedPL += realizedPL
+				p.realizedAssetPL += realizedPL * position.AvgFXRate
+				p.recordClosedPL(realizedPL * position.AvgFXRate)
 
 				// New long position
 				newLongQuantity := trade.Quantity - coverQuantity
 				position.Quantity = newLongQuantity
 				position.AvgPrice = trade.Price
-				p.cash -= totalCost
+				position.AvgFXRate = fxRate
 			}
 		}
 	} else { // SELL
 		if position.Quantity <= 0 {
 			// Adding to short position or opening new short position
 			newQuantity := position.Quantity - trade.Quantity
+			position.AvgFXRate = blendFXRate(position, trade.Quantity, trade.Price, fxRate)
 			if position.Quantity == 0 {
 				position.AvgPrice = trade.Price
 			} else {
 				position.AvgPrice = ((position.AvgPrice * math.Abs(position.Quantity)) + (trade.Price * trade.Quantity)) / math.Abs(newQuantity)
 			}
 			position.Quantity = newQuantity
-			p.cash += tradeValue - totalFees
 		} else {
 			// Selling long position
 			if trade.Quantity <= position.Quantity {
 				// Partial or full sale
-				realizedPL := (trade.Price - position.AvgPrice) * trade.Quantity
+				realizedPL := (trade.Price - position.AvgPrice) * trade.Quantity * multiplier
 				position.RealizedPL += realizedPL
+				p.realizedAssetPL += realizedPL * position.AvgFXRate
+				p.recordClosedPL(realizedPL * position.AvgFXRate)
 				position.Quantity -= trade.Quantity
-				p.cash += tradeValue - totalFees
 
 				if position.Quantity == 0 {
 					position.AvgPrice = 0
@@ -158,24 +345,37 @@ func (p *Portfolio) ExecuteTrade(trade strategy.TradeEvent, currentPrice float64
 			} else {
 				// Sell and reverse
 				sellQuantity := position.Quantity
-				realizedPL := (trade.Price - position.AvgPrice) * sellQuantity
+				realizedPL := (trade.Price - position.AvgPrice) * sellQuantity * multiplier
 				position.RealizedPL += realizedPL
+				p.realizedAssetPL += realizedPL * position.AvgFXRate
+				p.recordClosedPL(realizedPL * position.AvgFXRate)
 
 				// New short position
 				newShortQuantity := trade.Quantity - sellQuantity
 				position.Quantity = -newShortQuantity
 				position.AvgPrice = trade.Price
-				p.cash += tradeValue - totalFees
+				position.AvgFXRate = fxRate
 			}
 		}
 	}
 
+	// Settle cash. Futures only exchange fees at trade time; price moves
+	// settle through variation margin instead of the contract notional.
+	if isFuture {
+		p.debitCash(currency, totalFees)
+	} else if trade.Side == strategy.OrderSideBuy {
+		p.debitCash(currency, totalCost)
+	} else {
+		p.creditCash(currency, tradeValue-totalFees)
+	}
+
 	// Update market value and unrealized P&L
-	position.MarketValue = position.MarketValue + position.Quantity*currentPrice
+	p.lastPrices[symbol] = currentPrice
+	position.MarketValue = position.Quantity * currentPrice * multiplier
 	if position.Quantity > 0 {
-		position.UnrealizedPL = (currentPrice - position.AvgPrice) * position.Quantity
+		position.UnrealizedPL = (currentPrice - position.AvgPrice) * position.Quantity * multiplier
 	} else if position.Quantity < 0 {
-		position.UnrealizedPL = (position.AvgPrice - currentPrice) * math.Abs(position.Quantity)
+		position.UnrealizedPL = (position.AvgPrice - currentPrice) * math.Abs(position.Quantity) * multiplier
 	} else {
 		position.UnrealizedPL = 0
 	}
@@ -183,6 +383,7 @@ func (p *Portfolio) ExecuteTrade(trade strategy.TradeEvent, currentPrice float64
 	// Remove position if quantity is zero
 	if position.Quantity == 0 {
 		delete(p.positions, symbol)
+		delete(p.settlementPrices, symbol)
 	}
 
 	// Add trade to history
@@ -191,24 +392,48 @@ func (p *Portfolio) ExecuteTrade(trade strategy.TradeEvent, currentPrice float64
 	return nil
 }
 
+// recordClosedPL records realized P&L from the trade being executed
+func (p *Portfolio) recordClosedPL(realizedPL float64) {
+	p.lastTradeClosed = true
+	p.lastTradePL += realizedPL
+}
+
+// GetLastTradePL returns the realized P&L net of fees of the most recent trade
+// and whether that trade closed (part of) a position
+func (p *Portfolio) GetLastTradePL() (float64, bool) {
+	return p.lastTradePL, p.lastTradeClosed
+}
+
 // UpdateMarketValues updates the market values of all positions
 func (p *Portfolio) UpdateMarketValues(barData map[string]strategy.BarData) {
 	totalMarketValue := 0.0
 
+	for symbol, bar := range barData {
+		p.lastPrices[symbol] = bar.Close
+	}
+
 	for symbol, position := range p.positions {
+		multiplier := p.GetMultiplier(symbol)
 		if bar, exists := barData[symbol]; exists {
-			position.MarketValue = position.Quantity * bar.Close
+			position.MarketValue = position.Quantity * bar.Close * multiplier
 
 			if position.Quantity > 0 {
-				position.UnrealizedPL = (bar.Close - position.AvgPrice) * position.Quantity
+				position.UnrealizedPL = (bar.Close - position.AvgPrice) * position.Quantity * multiplier
 			} else if position.Quantity < 0 {
-				position.UnrealizedPL = (position.AvgPrice - bar.Close) * math.Abs(position.Quantity)
+				position.UnrealizedPL = (position.AvgPrice - bar.Close) * math.Abs(position.Quantity) * multiplier
 			}
 		}
-		totalMarketValue += position.MarketValue
+
+		if p.isFuture(symbol) {
+			// Only the variation since the last settlement is not yet in cash
+			variation := position.Quantity * (p.lastPrices[symbol] - p.settlementPrices[symbol]) * multiplier
+			totalMarketValue += variation * p.GetFXRate(position.Currency)
+			continue
+		}
+		totalMarketValue += position.MarketValue * p.GetFXRate(position.Currency)
 	}
 
-	p.totalValue = p.cash + totalMarketValue
+	p.totalValue = p.GetCash() + totalMarketValue
 
 	// Update drawdown tracking
 	if p.totalValue > p.peakValue {
@@ -222,6 +447,138 @@ func (p *Portfolio) UpdateMarketValues(barData map[string]strategy.BarData) {
 	}
 }
 
+// markToMarket moves a futures position's P&L since its last settlement into
+// cash and returns the variation margin in the instrument currency
+func (p *Portfolio) markToMarket(symbol string, price float64) float64 {
+	position, exists := p.positions[symbol]
+	if !exists || position.Quantity == 0 {
+		p.settlementPrices[symbol] = price
+		return 0
+	}
+
+	variation := position.Quantity * (price - p.settlementPrices[symbol]) * p.GetMultiplier(symbol)
+	if variation >= 0 {
+		p.creditCash(position.Currency, variation)
+	} else {
+		p.debitCash(position.Currency, -variation)
+	}
+	p.settlementPrices[symbol] = price
+
+	return variation
+}
+
+// SettleFutures marks all futures positions to their last price, settling daily
+// variation margin into cash. It returns the total variation in the base currency.
+func (p *Portfolio) SettleFutures() float64 {
+	total := 0.0
+	for symbol, position := range p.positions {
+		if !p.isFuture(symbol) {
+			continue
+		}
+		price, exists := p.lastPrices[symbol]
+		if !exists {
+			continue
+		}
+		total += p.markToMarket(symbol, price) * p.GetFXRate(position.Currency)
+	}
+	return total
+}
+
+// GetMarginRequirement returns the exchange margin required for open futures
+// positions in the base currency, using maintenance or initial margin
+func (p *Portfolio) GetMarginRequirement(maintenance bool) float64 {
+	required := 0.0
+	for symbol, position := range p.positions {
+		instrument := p.instruments[symbol]
+		if instrument == nil || !instrument.IsFuture() {
+			continue
+		}
+		margin := instrument.InitialMargin
+		if maintenance && instrument.MaintenanceMargin > 0 {
+			margin = instrument.MaintenanceMargin
+		}
+		required += math.Abs(position.Quantity) * margin * p.GetFXRate(position.Currency)
+	}
+	return required
+}
+
+// HasSufficientMargin checks if there is enough free cash to post initial margin for a futures order
+func (p *Portfolio) HasSufficientMargin(order strategy.Order) bool {
+	instrument := p.instruments[order.Symbol]
+	if instrument == nil || !instrument.IsFuture() {
+		return true
+	}
+
+	currentQuantity := 0.0
+	if position := p.positions[order.Symbol]; position != nil {
+		currentQuantity = position.Quantity
+	}
+
+	newQuantity := currentQuantity + order.Quantity
+	if order.Side == strategy.OrderSideSell {
+		newQuantity = currentQuantity - order.Quantity
+	}
+
+	// Reducing exposure never requires additional margin
+	additionalContracts := math.Abs(newQuantity) - math.Abs(currentQuantity)
+	if additionalContracts <= 0 {
+		return true
+	}
+
+	additionalMargin := additionalContracts * instrument.InitialMargin * p.GetFXRate(p.GetCurrency(order.Symbol))
+	return p.GetCash()-p.GetMarginRequirement(false) >= additionalMargin
+}
+
+// GetDayPL returns the P&L since the start of the current session
+func (p *Portfolio) GetDayPL() float64 {
+	return p.totalValue - p.dayStartValue
+}
+
+// GetDailyReturns returns the return of each completed session as a decimal
+func (p *Portfolio) GetDailyReturns() []float64 {
+	return p.dailyReturns
+}
+
+// GetDailySnapshots returns the end-of-session snapshots recorded so far
+func (p *Portfolio) GetDailySnapshots() []strategy.DailySnapshot {
+	return p.snapshots
+}
+
+// TakeDailySnapshot records the end-of-session state for the given session date
+// and starts a new session at the current portfolio value
+func (p *Portfolio) TakeDailySnapshot(date time.Time) strategy.DailySnapshot {
+	snapshot := strategy.DailySnapshot{
+		Date:         date,
+		Cash:         p.GetCash(),
+		CashBalances: p.GetCashBalances(),
+		Positions:    make(map[string]strategy.Position, len(p.positions)),
+		TotalValue:   p.totalValue,
+		DayPL:        p.GetDayPL(),
+	}
+
+	for symbol, position := range p.positions {
+		snapshot.Positions[symbol] = *position
+
+		fxRate := p.GetFXRate(position.Currency)
+		marketValue := position.MarketValue * fxRate
+		snapshot.MarketValue += marketValue
+		snapshot.UnrealizedPL += position.UnrealizedPL * fxRate
+		snapshot.GrossExposure += math.Abs(marketValue)
+		snapshot.NetExposure += marketValue
+	}
+	snapshot.RealizedPL = p.GetTotalPL() - snapshot.UnrealizedPL
+
+	if p.dayStartValue != 0 {
+		snapshot.DailyReturn = snapshot.DayPL / p.dayStartValue
+	}
+
+	p.snapshots = append(p.snapshots, snapshot)
+	p.dailyReturns = append(p.dailyReturns, snapshot.DailyReturn)
+	p.dayStartValue = p.totalValue
+
+	return snapshot
+}
+
 // AddEquityPoint adds an equity point for performance tracking
 func (p *Portfolio) AddEquityPoint(timestamp time.Time) {
 	p.equity = append(p.equity, EquityPoint{
@@ -247,11 +604,15 @@ func (p *Portfolio) GetCurrentDrawdown() float64 {
 
 // CanAfford checks if the portfolio can afford a trade
 func (p *Portfolio) CanAfford(order strategy.Order, price float64) bool {
+	if p.isFuture(order.Symbol) {
+		return p.HasSufficientMargin(order)
+	}
+
 	if order.Side == strategy.OrderSideBuy {
 		tradeValue := order.Quantity * price
 		commission := p.commissionConfig.CalculateCommission(tradeValue)
-		totalCost := tradeValue + commission
-		return p.cash >= totalCost
+		totalCost := (tradeValue + commission) * p.GetFXRate(p.GetCurrency(order.Symbol))
+		return p.GetCash() >= totalCost
 	}
 
 	// For sell orders, check if we have enough shares
@@ -263,6 +624,30 @@ func (p *Portfolio) CanAfford(order strategy.Order, price float64) bool {
 	return position.Quantity >= order.Quantity
 }
 
+// GetAssetPL returns the P&L from price moves measured at the FX rates positions were entered at
+func (p *Portfolio) GetAssetPL() float64 {
+	assetPL := p.realizedAssetPL
+	for _, position := range p.positions {
+		assetPL += position.UnrealizedPL * position.AvgFXRate
+	}
+	return assetPL
+}
+
+// GetCurrencyPL returns the P&L caused by FX rate moves on positions and foreign cash
+func (p *Portfolio) GetCurrencyPL() float64 {
+	return p.GetTotalPL() - p.GetAssetPL()
+}
+
+// blendFXRate returns the notional-weighted FX rate after adding quantity at price to a position
+func blendFXRate(position *strategy.Position, quantity, price, fxRate float64) float64 {
+	existing := math.Abs(position.Quantity) * position.AvgPrice
+	added := quantity * price
+	if existing+added == 0 {
+		return fxRate
+	}
+	return (existing*position.AvgFXRate + added*fxRate) / (existing + added)
+}
+
 // ToStrategyPortfolio converts to strategy.Portfolio format
 func (p *Portfolio) ToStrategyPortfolio() *strategy.Portfolio {
 	totalPL := 0.0
@@ -271,10 +656,13 @@ func (p *Portfolio) ToStrategyPortfolio() *strategy.Portfolio {
 	}
 
 	return &strategy.Portfolio{
-		Cash:       p.cash,
-		TotalValue: p.totalValue,
-		Positions:  p.positions,
-		TotalPL:    totalPL,
-		Trades:     p.trades,
+		Cash:         p.GetCash(),
+		CashBalances: p.GetCashBalances(),
+		BaseCurrency: p.baseCurrency,
+		TotalValue:   p.totalValue,
+		Positions:    p.positions,
+		TotalPL:      totalPL,
+		DayPL:        p.GetDayPL(),
+		Trades:       p.trades,
 	}
 }
@@ -0,0 +1,266 @@
+package backtester
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ridopark/JonBuhTrader/pkg/feed"
+	"github.com/ridopark/JonBuhTrader/pkg/strategy"
+)
+
+var (
+	day1 = time.Date(2024, 3, 1, 15, 0, 0, 0, time.UTC)
+	day2 = day1.AddDate(0, 0, 1)
+)
+
+// assertClose fails the test if got differs from want by more than 1e-9
+func assertClose(t *testing.T, name string, got, want float64) {
+	t.Helper()
+	if math.Abs(got-want) > 1e-9 {
+		t.Errorf("%s = %.10f, want %.10f", name, got, want)
+	}
+}
+
+// eurPortfolio returns a USD portfolio trading SAP in EUR, with EUR/USD at 1.10 on day1
+// and 1.20 on day2, and rates loaded as of day1
+func eurPortfolio(t *testing.T) *Portfolio {
+	t.Helper()
+	rates := NewFXRateTable("USD")
+	if err := rates.AddRates("EUR", []feed.FXRate{
+		{Base: "EUR", Quote: "USD", Timestamp: day1, Rate: 1.10},
+		{Base: "EUR", Quote: "USD", Timestamp: day2, Rate: 1.20},
+	}); err != nil {
+		t.Fatalf("AddRates: %v", err)
+	}
+
+	p := NewPortfolio(10000, NewCommissionConfig(CommissionTypeFixed, 0))
+	p.SetInstrument(strategy.Instrument{Symbol: "SAP", Currency: "EUR"})
+	p.SetFXRates(rates)
+	if err := p.UpdateFXRates(day1); err != nil {
+		t.Fatalf("UpdateFXRates: %v", err)
+	}
+	return p
+}
+
+func TestFXRateTableLooksUpAsOfRate(t *testing.T) {
+	rates := NewFXRateTable("USD")
+	// USD/JPY quotes are inverted into JPY/USD on insertion
+	err := rates.AddRates("JPY", []feed.FXRate{
+		{Base: "USD", Quote: "JPY", Timestamp: day2, Rate: 160},
+		{Base: "USD", Quote: "JPY", Timestamp: day1, Rate: 150},
+	})
+	if err != nil {
+		t.Fatalf("AddRates: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		at   time.Time
+		want float64
+		err  string
+	}{
+		{"before first rate", day1.Add(-time.Second), 0, "no FX rate for JPY/USD"},
+		{"at first rate", day1, 1.0 / 150, ""},
+		{"between rates", day1.Add(12 * time.Hour), 1.0 / 150, ""},
+		{"after last rate", day2.AddDate(0, 0, 5), 1.0 / 160, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := rates.GetRate("JPY", tt.at)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			assertClose(t, "rate", got, tt.want)
+		})
+	}
+
+	if rate, err := rates.GetRate("USD", day1); err != nil || rate != 1 {
+		t.Errorf("base currency rate = %v, %v; want 1", rate, err)
+	}
+	if _, err := rates.GetRate("GBP", day1); err == nil {
+		t.Error("expected an error for a currency without rates")
+	}
+	if err := rates.AddRates("GBP", []feed.FXRate{{Base: "EUR", Quote: "CHF", Timestamp: day1, Rate: 1}}); err == nil {
+		t.Error("expected an error for a rate that cannot convert GBP into USD")
+	}
+	if err := rates.AddRates("GBP", []feed.FXRate{{Base: "GBP", Quote: "USD", Timestamp: day1, Rate: 0}}); err == nil {
+		t.Error("expected an error for a non-positive rate")
+	}
+}
+
+func TestDebitCashConvertsForeignShortfall(t *testing.T) {
+	p := eurPortfolio(t)
+	p.creditCash("EUR", 300)
+	p.creditCash("USD", -330) // Bought the 300 EUR at 1.10
+
+	// 700 EUR of the 1000 EUR payment is bought with USD at 1.10
+	p.debitCash("EUR", 1000)
+	assertClose(t, "EUR balance", p.GetCashBalance("EUR"), 0)
+	assertClose(t, "USD balance", p.GetCashBalance("USD"), 10000-330-770)
+
+	// Base-currency debits never convert
+	p.debitCash("USD", 100)
+	assertClose(t, "USD balance after base debit", p.GetCashBalance("USD"), 10000-330-770-100)
+	assertClose(t, "EUR balance after base debit", p.GetCashBalance("EUR"), 0)
+}
+
+func TestForeignTradeSplitsAssetAndCurrencyPL(t *testing.T) {
+	p := eurPortfolio(t)
+
+	buy := strategy.TradeEvent{Symbol: "SAP", Side: strategy.OrderSideBuy, Quantity: 10, Price: 100, Commission: 2, Timestamp: day1}
+	if err := p.ExecuteTrade(buy, 100); err != nil {
+		t.Fatalf("buy: %v", err)
+	}
+	// 1002 EUR bought with USD at 1.10
+	assertClose(t, "USD cash after buy", p.GetCashBalance("USD"), 10000-1002*1.10)
+	assertClose(t, "EUR cash after buy", p.GetCashBalance("EUR"), 0)
+	if position := p.GetPosition("SAP"); position == nil || position.AvgFXRate != 1.10 || position.Currency != "EUR" {
+		t.Fatalf("position = %+v, want EUR position entered at 1.10", position)
+	}
+
+	// Price rises 10 EUR per share while EUR/USD rises from 1.10 to 1.20
+	if err := p.UpdateFXRates(day2); err != nil {
+		t.Fatalf("UpdateFXRates: %v", err)
+	}
+	p.UpdateMarketValues(map[string]strategy.BarData{"SAP": {Symbol: "SAP", Close: 110}})
+
+	total := 10000 - 1002*1.10 + 10*110*1.20
+	assertClose(t, "total value", p.GetTotalValue(), total)
+	// Asset P&L: the 100 EUR gain at the entry rate, less the 2 EUR commission at the trade rate
+	assertClose(t, "asset P&L", p.GetAssetPL(), 100*1.10-2*1.10)
+	assertClose(t, "currency P&L", p.GetCurrencyPL(), total-10000-(100*1.10-2*1.10))
+
+	// Closing realizes the same asset P&L; the EUR proceeds stay in EUR
+	sell := strategy.TradeEvent{Symbol: "SAP", Side: strategy.OrderSideSell, Quantity: 10, Price: 110, Timestamp: day2}
+	if err := p.ExecuteTrade(sell, 110); err != nil {
+		t.Fatalf("sell: %v", err)
+	}
+	p.UpdateMarketValues(map[string]strategy.BarData{"SAP": {Symbol: "SAP", Close: 110}})
+	assertClose(t, "EUR cash after sell", p.GetCashBalance("EUR"), 1100)
+	assertClose(t, "total value after sell", p.GetTotalValue(), total)
+	assertClose(t, "asset P&L after sell", p.GetAssetPL(), 100*1.10-2*1.10)
+	if pl, closed := p.GetLastTradePL(); !closed || math.Abs(pl-100*1.10) > 1e-9 {
+		t.Errorf("last trade P&L = %v, %v; want %v, true", pl, closed, 100*1.10)
+	}
+}
+
+func TestValidateFXRatesRequiresEveryCurrency(t *testing.T) {
+	p := NewPortfolio(10000, NewCommissionConfig(CommissionTypeFixed, 0))
+	p.SetInstrument(strategy.Instrument{Symbol: "AAPL", Currency: "USD"})
+	if err := p.ValidateFXRates(); err != nil {
+		t.Fatalf("base-currency instruments need no rates: %v", err)
+	}
+
+	p.SetInstrument(strategy.Instrument{Symbol: "7203.T", Currency: "JPY"})
+	if err := p.ValidateFXRates(); err == nil {
+		t.Fatal("expected an error without JPY rates")
+	}
+	rates := NewFXRateTable("USD")
+	rates.AddRates("JPY", []feed.FXRate{{Base: "JPY", Quote: "USD", Timestamp: day1, Rate: 0.0067}})
+	p.SetFXRates(rates)
+	if err := p.ValidateFXRates(); err != nil {
+		t.Fatalf("unexpected error with JPY rates: %v", err)
+	}
+}
+
+// futuresPortfolio returns a USD portfolio trading an ES contract worth 50 per point
+func futuresPortfolio() *Portfolio {
+	p := NewPortfolio(100000, NewCommissionConfig(CommissionTypeFixed, 0))
+	p.SetInstrument(strategy.Instrument{
+		Symbol:            "ESM4",
+		AssetType:         strategy.AssetTypeFuture,
+		Currency:          "USD",
+		Multiplier:        50,
+		InitialMargin:     10000,
+		MaintenanceMargin: 8000,
+	})
+	return p
+}
+
+func TestFuturesSettleVariationMargin(t *testing.T) {
+	p := futuresPortfolio()
+
+	buy := strategy.TradeEvent{Symbol: "ESM4", Side: strategy.OrderSideBuy, Quantity: 2, Price: 5000, Commission: 5, Timestamp: day1}
+	if err := p.ExecuteTrade(buy, 5000); err != nil {
+		t.Fatalf("buy: %v", err)
+	}
+	// Only fees leave cash when a futures position opens
+	assertClose(t, "cash after buy", p.GetCash(), 100000-5)
+
+	// Unsettled variation counts toward value before it reaches cash
+	p.UpdateMarketValues(map[string]strategy.BarData{"ESM4": {Symbol: "ESM4", Close: 5010}})
+	assertClose(t, "total value before settlement", p.GetTotalValue(), 100000-5+2*10*50)
+	assertClose(t, "cash before settlement", p.GetCash(), 100000-5)
+
+	assertClose(t, "settled variation", p.SettleFutures(), 2*10*50)
+	assertClose(t, "cash after settlement", p.GetCash(), 100000-5+1000)
+	assertClose(t, "second settlement", p.SettleFutures(), 0)
+
+	// Adding at 4990 first marks the existing contracts down from 5010
+	add := strategy.TradeEvent{Symbol: "ESM4", Side: strategy.OrderSideBuy, Quantity: 1, Price: 4990, Timestamp: day2}
+	if err := p.ExecuteTrade(add, 4990); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	assertClose(t, "cash after add", p.GetCash(), 100000-5+1000-2*20*50)
+
+	// Closing at 5000 marks all three contracts up from 4990
+	sell := strategy.TradeEvent{Symbol: "ESM4", Side: strategy.OrderSideSell, Quantity: 3, Price: 5000, Timestamp: day2}
+	if err := p.ExecuteTrade(sell, 5000); err != nil {
+		t.Fatalf("sell: %v", err)
+	}
+	assertClose(t, "cash after close", p.GetCash(), 100000-5+1000-2000+3*10*50)
+	if pl, closed := p.GetLastTradePL(); !closed || math.Abs(pl-(3*5000-(2*5000+4990))*50) > 1e-9 {
+		t.Errorf("last trade P&L = %v, %v; want 500, true", pl, closed)
+	}
+	// Settled cash and realized P&L agree once the position is flat
+	assertClose(t, "cash equals capital plus realized P&L", p.GetCash(), 100000+500-5)
+	if p.GetPosition("ESM4") != nil {
+		t.Error("expected the position to be removed when flat")
+	}
+}
+
+func TestMarkToMarketOnFlatPositionSetsSettlementPrice(t *testing.T) {
+	p := futuresPortfolio()
+	if variation := p.markToMarket("ESM4", 5000); variation != 0 {
+		t.Errorf("variation without a position = %v, want 0", variation)
+	}
+	if p.settlementPrices["ESM4"] != 5000 {
+		t.Errorf("settlement price = %v, want 5000", p.settlementPrices["ESM4"])
+	}
+}
+
+func TestFuturesMarginRequirement(t *testing.T) {
+	p := futuresPortfolio()
+	buy := strategy.TradeEvent{Symbol: "ESM4", Side: strategy.OrderSideBuy, Quantity: 2, Price: 5000, Commission: 5, Timestamp: day1}
+	if err := p.ExecuteTrade(buy, 5000); err != nil {
+		t.Fatalf("buy: %v", err)
+	}
+	assertClose(t, "initial margin", p.GetMarginRequirement(false), 20000)
+	assertClose(t, "maintenance margin", p.GetMarginRequirement(true), 16000)
+
+	// 79995 of free cash covers seven more contracts but not eight
+	tests := []struct {
+		name  string
+		order strategy.Order
+		want  bool
+	}{
+		{"within free cash", strategy.Order{Symbol: "ESM4", Side: strategy.OrderSideBuy, Quantity: 7}, true},
+		{"beyond free cash", strategy.Order{Symbol: "ESM4", Side: strategy.OrderSideBuy, Quantity: 8}, false},
+		{"reducing exposure", strategy.Order{Symbol: "ESM4", Side: strategy.OrderSideSell, Quantity: 2}, true},
+		{"reversing beyond free cash", strategy.Order{Symbol: "ESM4", Side: strategy.OrderSideSell, Quantity: 12}, false},
+		{"non-futures symbol", strategy.Order{Symbol: "AAPL", Side: strategy.OrderSideBuy, Quantity: 1000}, true},
+	}
+	for _, tt := range tests {
+		if got := p.HasSufficientMargin(tt.order); got != tt.want {
+			t.Errorf("%s: HasSufficientMargin = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package backtester
+
+import (
+	"strings"
+
+	"github.com/ridopark/JonBuhTrader/pkg/indicators"
+	"github.com/ridopark/JonBuhTrader/pkg/strategy"
+)
+
+// indicatorKey identifies a persistent indicator by symbol, timeframe and parameter set.
+// Every output of an indicator is served from the same entry.
+type indicatorKey struct {
+	symbol    string
+	timeframe string
+	spec      string // registrySpec(spec)
+}
+
+// registrySpec returns the registry name of a spec: its lower-cased name and
+// parameters, without the output
+func registrySpec(spec strategy.IndicatorSpec) string {
+	return strategy.IndicatorSpec{Name: strings.ToLower(spec.Name), Params: spec.Params}.String()
+}
+
+// trackedIndicator is an indicator kept up to date with every new bar of a symbol
+type trackedIndicator struct {
+	indicator indicators.Indicator
+	outputs   []string                      // Named outputs recorded besides the main value
+	values    map[string]*indicators.Window // Most recent ready values by output ("" for the main value), oldest first
+	bars      int                           // Number of bars the indicator has seen
+}
+
+// update feeds a bar to the indicator and records its outputs once it is ready
+func (t *trackedIndicator) update(bar strategy.BarData) {
+	t.indicator.Update(bar)
+	t.bars++
+	if !t.indicator.Ready() {
+		return
+	}
+	t.values[""].Add(t.indicator.Value())
+	for _, output := range t.outputs {
+		// Outputs only lists names that OutputValue accepts
+		value, _ := indicators.OutputValue(t.indicator, output)
+		t.values[output].Add(value)
+	}
+}
+
+// history returns the recorded values of an output, or nil if the indicator has no such output
+func (t *trackedIndicator) history(output string) *indicators.Window {
+	output = strings.ToLower(output)
+	if output == "value" {
+		output = ""
+	}
+	return t.values[output]
+}
+
+// indicatorRegistry keeps one persistent indicator per (indicator, symbol, parameters,
+// timeframe). Each indicator is updated once when a bar arrives, so any number of
+// requests for it during the bar are served from its current state.
+type indicatorRegistry struct {
+	entries  map[indicatorKey]*trackedIndicator
+	bySeries map[seriesKey][]*trackedIndicator // Indicators to update for each symbol and timeframe
+	pairs    map[pairKey]*trackedPair
+}
+
+// seriesKey identifies the bar series of a symbol at a timeframe
+type seriesKey struct {
+	symbol    string
+	timeframe string
+}
+
+// newIndicatorRegistry creates an empty registry
+func newIndicatorRegistry() *indicatorRegistry {
+	return &indicatorRegistry{
+		entries:  make(map[indicatorKey]*trackedIndicator),
+		bySeries: make(map[seriesKey][]*trackedIndicator),
+		pairs:    make(map[pairKey]*trackedPair),
+	}
+}
+
+// get returns the indicator for a key, creating it with create and seeding it from
+// history (the symbol's stored bars) on first use. The last historySize values of
+// each output are kept for history requests.
+func (r *indicatorRegistry) get(key indicatorKey, historySize int, history []strategy.BarData, create func() (indicators.Indicator, error)) (*trackedIndicator, error) {
+	if tracked, ok := r.entries[key]; ok {
+		return tracked, nil
+	}
+
+	indicator, err := create()
+	if err != nil {
+		return nil, err
+	}
+
+	tracked := &trackedIndicator{
+		indicator: indicator,
+		outputs:   indicators.Outputs(indicator),
+		values:    map[string]*indicators.Window{"": indicators.NewWindow(historySize)},
+	}
+	for _, output := range tracked.outputs {
+		tracked.values[output] = indicators.NewWindow(historySize)
+	}
+	for _, bar := range history {
+		if bar.Timeframe == key.timeframe {
+			tracked.update(bar)
+		}
+	}
+
+	r.entries[key] = tracked
+	series := seriesKey{symbol: key.symbol, timeframe: key.timeframe}
+	r.bySeries[series] = append(r.bySeries[series], tracked)
+	return tracked, nil
+}
+
+// update feeds a new bar to every indicator registered for its symbol and timeframe
+func (r *indicatorRegistry) update(symbol string, bar strategy.BarData) {
+	for _, tracked := range r.bySeries[seriesKey{symbol: symbol, timeframe: bar.Timeframe}] {
+		tracked.update(bar)
+	}
+}
+
+// pairKey identifies a persistent pair indicator by its two symbols and parameter set
+type pairKey struct {
+	symbol string
+	other  string
+	spec   string // strategy.IndicatorSpec.String()
+}
+
+// trackedPair is a pair indicator fed once for each timestamp at which both symbols have a bar.
+// Pairs are (other, symbol), as closes or as close-to-close returns.
+type trackedPair struct {
+	indicator   indicators.PairIndicator
+	returns     bool    // Feed returns rather than closes
+	closes      int     // Number of shared timestamps seen
+	pairs       int     // Number of pairs the indicator has seen
+	symbolClose float64 // Closes at the previous shared timestamp
+	otherClose  float64
+}
+
+// add feeds the closes of both symbols at the next shared timestamp
+func (t *trackedPair) add(symbolClose, otherClose float64) {
+	t.closes++
+	previousSymbol, previousOther := t.symbolClose, t.otherClose
+	t.symbolClose, t.otherClose = symbolClose, otherClose
+	if !t.returns {
+		t.indicator.AddPair(otherClose, symbolClose)
+		t.pairs++
+		return
+	}
+	if t.closes == 1 {
+		return
+	}
+	t.indicator.AddPair(simpleReturn(previousOther, otherClose), simpleReturn(previousSymbol, symbolClose))
+	t.pairs++
+}
+
+// getPair returns the pair indicator for a key, creating it with create and seeding it
+// with the closes the two symbols share in their stored history on first use
+func (r *indicatorRegistry) getPair(key pairKey, returns bool, history func() ([]float64, []float64), create func() indicators.PairIndicator) *trackedPair {
+	if tracked, ok := r.pairs[key]; ok {
+		return tracked
+	}
+
+	tracked := &trackedPair{indicator: create(), returns: returns}
+	symbolCloses, otherCloses := history()
+	for i := range symbolCloses {
+		tracked.add(symbolCloses[i], otherCloses[i])
+	}
+	r.pairs[key] = tracked
+	return tracked
+}
+
+// updatePairs feeds a data point's bars to every pair indicator whose symbols both have
+// a bar at the same timestamp
+func (r *indicatorRegistry) updatePairs(bars map[string]strategy.BarData) {
+	for key, tracked := range r.pairs {
+		bar, ok := bars[key.symbol]
+		if !ok {
+			continue
+		}
+		otherBar, ok := bars[key.other]
+		if !ok || !otherBar.Timestamp.Equal(bar.Timestamp) {
+			continue
+		}
+		tracked.add(bar.Close, otherBar.Close)
+	}
+}
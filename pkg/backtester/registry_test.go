@@ -0,0 +1,158 @@
+package backtester
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ridopark/JonBuhTrader/pkg/indicators"
+	"github.com/ridopark/JonBuhTrader/pkg/strategy"
+)
+
+func TestRegistryCreatesOneIndicatorPerKey(t *testing.T) {
+	ctx := newTestContext()
+	for i, bar := range syntheticBars(40) {
+		bar.Timeframe = "1h"
+		addBar(ctx, i, bar)
+
+		for j := 0; j < 3; j++ {
+			ctx.SMA(testSymbol, 10)
+			ctx.SMA(testSymbol, 20)
+			ctx.Indicator(testSymbol, strategy.NewIndicatorSpec("sma", 10))
+		}
+	}
+
+	if got := len(ctx.registry.entries); got != 2 {
+		t.Fatalf("registry has %d indicators, want 2 (sma(10) and sma(20))", got)
+	}
+	tracked := ctx.registry.entries[indicatorKey{symbol: testSymbol, timeframe: "1h", spec: registrySpec(strategy.NewIndicatorSpec("sma", 10))}]
+	if tracked == nil || tracked.bars != 40 {
+		t.Fatalf("sma(10) should have seen each of the 40 bars exactly once, got %+v", tracked)
+	}
+}
+
+func TestRegistrySeparatesTimeframes(t *testing.T) {
+	ctx := newTestContext()
+	bars := syntheticBars(30)
+	for i, bar := range bars {
+		bar.Timeframe = "1h"
+		addBar(ctx, i, bar)
+	}
+	hourly, err := ctx.SMA(testSymbol, 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// A bar from another timeframe starts a separate series
+	daily := bars[0]
+	daily.Timeframe = "1d"
+	addBar(ctx, len(bars), daily)
+	if _, err := ctx.SMA(testSymbol, 5); err == nil {
+		t.Fatal("expected the daily SMA to need its own warm-up")
+	}
+
+	tracked := ctx.registry.entries[indicatorKey{symbol: testSymbol, timeframe: "1h", spec: registrySpec(strategy.NewIndicatorSpec("sma", 5))}]
+	if tracked.indicator.Value() != hourly || tracked.bars != len(bars) {
+		t.Error("the hourly SMA was updated with a daily bar")
+	}
+}
+
+func TestRegistrySharesOneEntryAcrossOutputs(t *testing.T) {
+	ctx := newTestContext()
+	macd := strategy.NewIndicatorSpec("macd", 12, 26, 9)
+	for i, bar := range syntheticBars(60) {
+		addBar(ctx, i, bar)
+		ctx.MACD(testSymbol, 12, 26, 9)
+		ctx.Indicator(testSymbol, macd.WithOutput("signal"))
+		ctx.Indicator(testSymbol, strategy.NewIndicatorSpec("MACD", 12, 26, 9).WithOutput("Histogram"))
+	}
+
+	if got := len(ctx.registry.entries); got != 1 {
+		t.Fatalf("registry has %d indicators, want one MACD shared by its outputs", got)
+	}
+	tracked := ctx.registry.entries[indicatorKey{symbol: testSymbol, spec: "macd(12,26,9)"}]
+	if tracked == nil || tracked.bars != 60 {
+		t.Fatalf("MACD should have seen each of the 60 bars exactly once, got %+v", tracked)
+	}
+
+	// Each output is read from the shared indicator, and keeps its own history
+	line, signal, histogram, _ := ctx.MACD(testSymbol, 12, 26, 9)
+	for output, want := range map[string]float64{"": line, "value": line, "macd": line, "signal": signal, "histogram": histogram} {
+		got, err := ctx.Indicator(testSymbol, macd.WithOutput(output))
+		if err != nil || got != want {
+			t.Errorf("output %q = %v, %v; want %v", output, got, err, want)
+		}
+		history, err := ctx.IndicatorHistory(testSymbol, macd.WithOutput(output), 3)
+		if err != nil || history[2] != want {
+			t.Errorf("output %q history = %v, %v; want it to end at %v", output, history, err, want)
+		}
+	}
+
+	if _, err := ctx.Indicator(testSymbol, macd.WithOutput("bogus")); err == nil {
+		t.Error("expected an error for an unknown output of a registered indicator")
+	}
+	if _, err := ctx.IndicatorHistory(testSymbol, macd.WithOutput("bogus"), 3); err == nil {
+		t.Error("expected an error for the history of an unknown output")
+	}
+}
+
+// benchmarkSymbols and benchmarkBars size the multi-indicator benchmarks
+const (
+	benchmarkSymbols = 10
+	benchmarkBars    = 300
+)
+
+// benchmarkDataPoints creates data points for several symbols
+func benchmarkDataPoints() []strategy.DataPoint {
+	series := syntheticBars(benchmarkBars + benchmarkSymbols)
+	points := make([]strategy.DataPoint, benchmarkBars)
+	start := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	for i := range points {
+		timestamp := start.Add(time.Duration(i) * time.Hour)
+		points[i] = strategy.DataPoint{Timestamp: timestamp, Bars: make(map[string]strategy.BarData, benchmarkSymbols)}
+		for s := 0; s < benchmarkSymbols; s++ {
+			symbol := fmt.Sprintf("SYM%d", s)
+			bar := series[i+s]
+			bar.Symbol = symbol
+			bar.Timestamp = timestamp
+			points[i].Bars[symbol] = bar
+		}
+	}
+	return points
+}
+
+// BenchmarkIndicatorsReplayed recomputes SMA, RSI and ADX over the stored history on
+// every request, as the context did before indicators were registered
+func BenchmarkIndicatorsReplayed(b *testing.B) {
+	points := benchmarkDataPoints()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		ctx := newTestContext()
+		for _, point := range points {
+			ctx.UpdatePriceHistory(point)
+			for symbol := range point.Bars {
+				history := ctx.indicators[symbol].Bars
+				indicators.Replay(indicators.NewSMA(20), history)
+				indicators.Replay(indicators.NewRSI(14), history)
+				indicators.Replay(indicators.NewADX(14), history)
+			}
+		}
+	}
+}
+
+// BenchmarkIndicatorsRegistered serves the same requests from the indicator registry
+func BenchmarkIndicatorsRegistered(b *testing.B) {
+	points := benchmarkDataPoints()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		ctx := newTestContext()
+		for _, point := range points {
+			ctx.UpdatePriceHistory(point)
+			for symbol := range point.Bars {
+				ctx.SMA(symbol, 20)
+				ctx.RSI(symbol, 14)
+				ctx.ADX(symbol, 14)
+			}
+		}
+	}
+}
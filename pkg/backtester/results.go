@@ -2,6 +2,8 @@ package backtester
 
 import (
 	"fmt"
+	"math"
+	"sort"
 	"time"
 
 	"github.com/ridopark/JonBuhTrader/pkg/strategy"
@@ -128,20 +130,49 @@ func (pt *PositionTracker) CalculateUnrealizedPL(currentPrice float64) float64 {
 
 // Results contains the results of a backtest
 type Results struct {
-	StrategyName   string                `json:"strategy_name"`
-	StartDate      time.Time             `json:"start_date"`
-	EndDate        time.Time             `json:"end_date"`
-	InitialCapital float64               `json:"initial_capital"`
-	FinalCapital   float64               `json:"final_capital"`
-	TotalReturn    float64               `json:"total_return"`
-	TotalPL        float64               `json:"total_pl"`
-	MaxDrawdown    float64               `json:"max_drawdown"`
-	Trades         []strategy.TradeEvent `json:"trades"`
-	EquityCurve    []EquityPoint         `json:"equity_curve"`
-	Portfolio      *strategy.Portfolio   `json:"portfolio"`
+	StrategyName         string                         `json:"strategy_name"`
+	StartDate            time.Time                      `json:"start_date"`
+	EndDate              time.Time                      `json:"end_date"`
+	BaseCurrency         string                         `json:"base_currency"`
+	InitialCapital       float64                        `json:"initial_capital"`
+	FinalCapital         float64                        `json:"final_capital"`
+	TotalReturn          float64                        `json:"total_return"`
+	TotalPL              float64                        `json:"total_pl"`
+	AssetPL              float64                        `json:"asset_pl"`    // P&L from price moves at entry FX rates
+	CurrencyPL           float64                        `json:"currency_pl"` // P&L from FX rate moves
+	MaxDrawdown          float64                        `json:"max_drawdown"`
+	RiskFreeRate         float64                        `json:"risk_free_rate"`                  // Annual risk-free rate as a decimal
+	PeriodsPerYear       int                            `json:"periods_per_year"`                // Daily periods used to annualize metrics
+	SharpeTrials         int                            `json:"sharpe_trials"`                   // Configurations tried, for the deflated Sharpe ratio
+	SharpeTrialVariance  float64                        `json:"sharpe_trial_variance,omitempty"` // Variance of the trials' daily Sharpe ratios (0 = this run's estimate variance)
+	Trades               []strategy.TradeEvent          `json:"trades"`
+	RoundTrips           []RoundTrip                    `json:"round_trips"` // Fills matched FIFO into closed positions
+	RejectedOrders       []OrderRejection               `json:"rejected_orders"`
+	CircuitBreakerEvents []strategy.CircuitBreakerEvent `json:"circuit_breaker_events"`
+	EquityCurve          []EquityPoint                  `json:"equity_curve"`
+	DailySnapshots       []strategy.DailySnapshot       `json:"daily_snapshots"`
+	Portfolio            *strategy.Portfolio            `json:"portfolio"`
+	Bars                 map[string][]strategy.BarData  `json:"-"` // Bars seen for each symbol, for charts and trade analysis
+	BenchmarkSymbol      string                         `json:"benchmark_symbol,omitempty"`
+	BenchmarkCurve       []EquityPoint                  `json:"benchmark_curve,omitempty"`     // Benchmark buy-and-hold value of the initial capital
+	DrawdownPeriods      []DrawdownPeriod               `json:"drawdown_periods"`              // Deepest drawdown episodes, deepest first
+	Rolling              []RollingMetrics               `json:"rolling"`                       // Rolling-window metrics for each configured window
+	ExcessReturnCurve    []EquityPoint                  `json:"excess_return_curve,omitempty"` // Initial capital scaled by strategy over benchmark value
+	MonteCarlo           *MonteCarloAnalysis            `json:"monte_carlo,omitempty"`         // Robustness analysis, when one was run
 
 	// Performance Metrics
 	Metrics *PerformanceMetrics `json:"metrics"`
+
+	sessionLocation *time.Location // Time zone of session boundaries for daily returns
+	topDrawdowns    int            // Number of drawdown periods to keep (0 = DefaultTopDrawdowns)
+	rollingWindows  []int          // Rolling metric windows in daily periods (nil = DefaultRollingWindows)
+}
+
+// OrderRejection records an order that was rejected before execution
+type OrderRejection struct {
+	Timestamp time.Time      `json:"timestamp"`
+	Order     strategy.Order `json:"order"`
+	Reason    string         `json:"reason"`
 }
 
 // PerformanceMetrics contains detailed performance analysis
@@ -155,6 +186,14 @@ type PerformanceMetrics struct {
 	LargestWin        float64 `json:"largest_win"`
 	LargestLoss       float64 `json:"largest_loss"`
 	ProfitFactor      float64 `json:"profit_factor"`
+	TradeSharpeRatio  float64 `json:"trade_sharpe_ratio"`  // Mean over standard deviation of round-trip P&L, not annualized
+	TradeSortinoRatio float64 `json:"trade_sortino_ratio"` // Mean over downside deviation of round-trip P&L, not annualized
+
+	// Return metrics from daily equity returns, annualized with PeriodsPerYear
+	AnnualizedReturn  float64 `json:"annualized_return"` // Compound annual growth rate as a percentage
+	Volatility        float64 `json:"volatility"`        // Annualized standard deviation of daily returns as a percentage
+	UlcerIndex        float64 `json:"ulcer_index"`       // Root mean square of daily drawdowns, in percent
+	PainIndex         float64 `json:"pain_index"`        // Mean daily drawdown, in percent
 	SharpeRatio       float64 `json:"sharpe_ratio"`
 	SortinoRatio      float64 `json:"sortino_ratio"`
 	MaxDrawdown       float64 `json:"max_drawdown"`
@@ -162,11 +201,39 @@ type PerformanceMetrics struct {
 	CalmarRatio       float64 `json:"calmar_ratio"`
 	VaR95             float64 `json:"var_95"`
 	ExpectedShortfall float64 `json:"expected_shortfall"`
+
+	// Statistical significance of the Sharpe ratio, from daily returns
+	Skewness             float64 `json:"skewness"`
+	Kurtosis             float64 `json:"kurtosis"`                // Excess kurtosis (0 for normal returns)
+	ProbabilisticSharpe  float64 `json:"probabilistic_sharpe"`    // Probability in percent that the true Sharpe ratio is above zero
+	DeflatedSharpe       float64 `json:"deflated_sharpe"`         // Probabilistic Sharpe against the best Sharpe expected by chance over SharpeTrials
+	SharpeTrials         int     `json:"sharpe_trials"`           // Configurations tried, as corrected for by DeflatedSharpe
+	MinTrackRecordLength float64 `json:"min_track_record_length"` // Daily periods needed for 95% confidence that the Sharpe ratio is above zero (0 if not positive)
+
+	// Round-trip statistics, overall and by the entry order's reason
+	RoundTrips         *RoundTripStats           `json:"round_trips"`
+	RoundTripsByReason map[string]RoundTripStats `json:"round_trips_by_reason"`
+
+	// Attribution of round-trip performance and cost totals
+	BySymbol   map[string]Attribution `json:"by_symbol"`
+	ByReason   map[string]Attribution `json:"by_reason"` // By the entry order's reason
+	ByStrategy map[string]Attribution `json:"by_strategy"`
+	Costs      CostBreakdown          `json:"costs"`
+
+	// Comparison with the benchmark (nil without one)
+	Benchmark *BenchmarkMetrics `json:"benchmark,omitempty"`
 }
 
 // CalculateMetrics calculates performance metrics for the results
 func (r *Results) CalculateMetrics() {
 	r.Metrics = &PerformanceMetrics{}
+	r.calculateReturnMetrics()
+	r.calculateSignificance()
+	r.calculateDrawdownMetrics()
+	r.calculateBenchmarkMetrics()
+	r.calculateRoundTripStats()
+	r.calculateAttribution()
+	r.calculateRollingMetrics()
 
 	if len(r.Trades) == 0 {
 		return
@@ -244,31 +311,12 @@ func (r *Results) CalculateMetrics() {
 		r.Metrics.ProfitFactor = totalWins / (-totalLosses)
 	}
 
-	// Drawdown metrics
-	r.Metrics.MaxDrawdown = r.MaxDrawdown
-	r.Metrics.MaxDrawdownPct = r.MaxDrawdown * 100
-
-	// Calmar Ratio (Annual Return / Max Drawdown)
-	if r.MaxDrawdown > 0 {
-		annualReturn := r.TotalReturn // Simplified - should be annualized
-		r.Metrics.CalmarRatio = annualReturn / (r.MaxDrawdown * 100)
-	}
-
-	// Calculate Sharpe Ratio (simplified)
-	if len(r.EquityCurve) > 1 {
-		returns := make([]float64, len(r.EquityCurve)-1)
-		for i := 1; i < len(r.EquityCurve); i++ {
-			if r.EquityCurve[i-1].Value > 0 {
-				returns[i-1] = (r.EquityCurve[i].Value - r.EquityCurve[i-1].Value) / r.EquityCurve[i-1].Value
-			}
-		}
-
-		r.Metrics.SharpeRatio = calculateSharpeRatio(returns)
-		r.Metrics.SortinoRatio = calculateSortinoRatio(returns)
-	}
+	// Trade-based ratios of round-trip P&L
+	r.Metrics.TradeSharpeRatio = calculateSharpeRatio(tradeResults)
+	r.Metrics.TradeSortinoRatio = calculateSortinoRatio(tradeResults)
 }
 
-// calculateSharpeRatio calculates the Sharpe ratio from returns
+// calculateSharpeRatio calculates the Sharpe ratio of a series of returns or P&L values
 func calculateSharpeRatio(returns []float64) float64 {
 	if len(returns) == 0 {
 		return 0
@@ -292,7 +340,7 @@ func calculateSharpeRatio(returns []float64) float64 {
 		return 0
 	}
 
-	stdDev := sumSquares / float64(len(returns)-1)
+	stdDev := math.Sqrt(sumSquares / float64(len(returns)-1))
 	if stdDev <= 0 {
 		return 0
 	}
@@ -301,7 +349,7 @@ func calculateSharpeRatio(returns []float64) float64 {
 	return mean / stdDev
 }
 
-// calculateSortinoRatio calculates the Sortino ratio from returns
+// calculateSortinoRatio calculates the Sortino ratio of a series of returns or P&L values
 func calculateSortinoRatio(returns []float64) float64 {
 	if len(returns) == 0 {
 		return 0
@@ -328,7 +376,7 @@ func calculateSortinoRatio(returns []float64) float64 {
 		return 0 // No downside
 	}
 
-	downsideDeviation := sumDownside / float64(downsideCount)
+	downsideDeviation := math.Sqrt(sumDownside / float64(downsideCount))
 	if downsideDeviation <= 0 {
 		return 0
 	}
@@ -347,15 +395,20 @@ func (r *Results) Summary() string {
 Backtest Results for %s
 =======================
 Period: %s to %s
+Base Currency: %s
 Initial Capital: $%.2f
 Final Capital: $%.2f
 Final Cash: $%.2f
 Total Return: %.2f%%
 Total P&L: $%.2f
+- Asset P&L: $%.2f (%.2f%%)
+- Currency P&L: $%.2f (%.2f%%)
 Max Drawdown: %.2f%%
 
 Trade Statistics:
 - Total Trades: %d
+- Rejected Orders: %d
+- Circuit Breakers Tripped: %d
 - Winning Trades: %d (%.1f%%)
 - Losing Trades: %d (%.1f%%)
 - Average Win: $%.2f
@@ -363,25 +416,36 @@ Trade Statistics:
 - Largest Win: $%.2f
 - Largest Loss: $%.2f
 - Profit Factor: %.2f
+- Trade Sharpe Ratio: %.2f
+- Trade Sortino Ratio: %.2f
 
-Risk Metrics:
+Risk Metrics (daily returns, %d periods/year, %.2f%% risk-free):
+- Annualized Return: %.2f%%
+- Volatility: %.2f%%
 - Sharpe Ratio: %.2f
 - Sortino Ratio: %.2f
 - Calmar Ratio: %.2f
 - Max Drawdown: %.2f%%
-
-All Trades:
-===========`,
+- Ulcer Index: %.2f
+- Pain Index: %.2f
+`,
 		r.StrategyName,
 		r.StartDate.Format("2006-01-02"),
 		r.EndDate.Format("2006-01-02"),
+		r.BaseCurrency,
 		r.InitialCapital,
 		r.FinalCapital,
 		r.Portfolio.Cash,
 		r.TotalReturn,
 		r.FinalCapital-r.InitialCapital,
+		r.AssetPL,
+		r.AssetPL/r.InitialCapital*100,
+		r.CurrencyPL,
+		r.CurrencyPL/r.InitialCapital*100,
 		r.MaxDrawdown*100,
 		r.Metrics.TotalTrades,
+		len(r.RejectedOrders),
+		len(r.CircuitBreakerEvents),
 		r.Metrics.WinningTrades,
 		r.Metrics.WinRate,
 		r.Metrics.LosingTrades,
@@ -391,12 +455,174 @@ All Trades:
 		r.Metrics.LargestWin,
 		r.Metrics.LargestLoss,
 		r.Metrics.ProfitFactor,
+		r.Metrics.TradeSharpeRatio,
+		r.Metrics.TradeSortinoRatio,
+		r.periodsPerYear(),
+		r.RiskFreeRate*100,
+		r.Metrics.AnnualizedReturn,
+		r.Metrics.Volatility,
 		r.Metrics.SharpeRatio,
 		r.Metrics.SortinoRatio,
 		r.Metrics.CalmarRatio,
 		r.Metrics.MaxDrawdownPct,
+		r.Metrics.UlcerIndex,
+		r.Metrics.PainIndex,
 	)
 
+	summary += fmt.Sprintf(`
+Sharpe Significance:
+- Skewness: %.2f
+- Excess Kurtosis: %.2f
+- Probabilistic Sharpe: %.1f%%
+- Deflated Sharpe (%d trials): %.1f%%
+- Min Track Record Length: %.0f daily periods
+`,
+		r.Metrics.Skewness,
+		r.Metrics.Kurtosis,
+		r.Metrics.ProbabilisticSharpe,
+		r.Metrics.SharpeTrials,
+		r.Metrics.DeflatedSharpe,
+		r.Metrics.MinTrackRecordLength,
+	)
+
+	if stats := r.Metrics.RoundTrips; stats != nil && stats.Count > 0 {
+		summary += fmt.Sprintf(`
+Round Trips:
+- Count: %d
+- Expectancy: $%.2f (%.2f%%)
+- Edge Ratio (MFE/MAE): %.2f
+- Average MAE: %.2f%%
+- Average MFE: %.2f%%
+- Average Bars Held: %.1f
+`,
+			stats.Count,
+			stats.Expectancy,
+			stats.ExpectancyPct,
+			stats.EdgeRatio,
+			stats.AvgMAE,
+			stats.AvgMFE,
+			stats.AvgBarsHeld,
+		)
+
+		reasons := make([]string, 0, len(r.Metrics.RoundTripsByReason))
+		for reason := range r.Metrics.RoundTripsByReason {
+			reasons = append(reasons, reason)
+		}
+		sort.Strings(reasons)
+		summary += fmt.Sprintf("\n%-24s %6s %8s %12s %10s %8s %8s %8s\n", "Entry Reason", "Count", "Win %", "Expectancy", "Net P&L", "Edge", "MAE %", "MFE %")
+		for _, reason := range reasons {
+			s := r.Metrics.RoundTripsByReason[reason]
+			summary += fmt.Sprintf("%-24s %6d %7.1f%% %12.2f %10.2f %8.2f %8.2f %8.2f\n",
+				reason, s.Count, s.WinRate, s.Expectancy, s.NetPL, s.EdgeRatio, s.AvgMAE, s.AvgMFE)
+		}
+	}
+
+	if len(r.RoundTrips) > 0 {
+		summary += "\nAttribution:\n"
+		for _, group := range []struct {
+			name         string
+			attributions map[string]Attribution
+		}{
+			{"Symbol", r.Metrics.BySymbol},
+			{"Reason", r.Metrics.ByReason},
+			{"Strategy", r.Metrics.ByStrategy},
+		} {
+			summary += fmt.Sprintf("%-24s %6s %12s %10s %8s %10s %12s\n", group.name, "Trips", "Net P&L", "Fees", "Win %", "Exposure", "Contribution")
+			keys := make([]string, 0, len(group.attributions))
+			for key := range group.attributions {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				a := group.attributions[key]
+				summary += fmt.Sprintf("%-24s %6d %12.2f %10.2f %7.1f%% %9.1f%% %11.2f%%\n",
+					key, a.RoundTrips, a.NetPL, a.Fees, a.WinRate, a.Exposure, a.Contribution)
+			}
+			summary += "\n"
+		}
+	}
+
+	c := r.Metrics.Costs
+	summary += fmt.Sprintf(`Trading Costs:
+- Commission: $%.2f
+- SEC Fee: $%.2f
+- FINRA TAF: $%.2f
+- Slippage: $%.2f
+- Total: $%.2f (%.2f%% of initial capital)
+`,
+		c.Commission,
+		c.SecFee,
+		c.FinraTaf,
+		c.Slippage,
+		c.Total,
+		c.Drag,
+	)
+
+	if len(r.DrawdownPeriods) > 0 {
+		summary += "\nWorst Drawdowns:\n"
+		summary += fmt.Sprintf("%-4s %-10s %-10s %-10s %8s %10s %10s\n", "#", "Peak", "Trough", "Recovery", "Depth", "Duration", "Recover")
+		for i, period := range r.DrawdownPeriods {
+			recovery := "ongoing"
+			if !period.Ongoing {
+				recovery = period.Recovery.Format("2006-01-02")
+			}
+			summary += fmt.Sprintf("%-4d %-10s %-10s %-10s %7.2f%% %9.1fd %9.1fd\n",
+				i+1,
+				period.Peak.Format("2006-01-02"),
+				period.Trough.Format("2006-01-02"),
+				recovery,
+				period.Depth*100,
+				period.DurationDays,
+				period.RecoveryDays,
+			)
+		}
+	}
+
+	if b := r.Metrics.Benchmark; b != nil {
+		summary += fmt.Sprintf(`
+Benchmark (%s):
+- Benchmark Return: %.2f%%
+- Excess Return: %.2f%%
+- Alpha (annualized): %.2f%%
+- Beta: %.2f
+- Correlation: %.2f
+- Tracking Error: %.2f%%
+- Information Ratio: %.2f
+- Up Capture: %.1f%%
+- Down Capture: %.1f%%
+`,
+			b.Benchmark,
+			b.BenchmarkReturn,
+			b.ExcessReturn,
+			b.Alpha*100,
+			b.Beta,
+			b.Correlation,
+			b.TrackingError*100,
+			b.InformationRatio,
+			b.UpCapture*100,
+			b.DownCapture*100,
+		)
+	}
+	if mc := r.MonteCarlo; mc != nil {
+		summary += fmt.Sprintf("\nMonte Carlo (%d paths per method, %.0f%% intervals, ruin at %.0f%% drawdown):\n",
+			mc.Config.Simulations, mc.Config.ConfidenceLevel*100, mc.Config.RuinDrawdown*100)
+		summary += fmt.Sprintf("%-10s %-26s %-26s %-20s %7s %7s\n", "Method", "Final Return", "Max Drawdown", "Sharpe", "Ruin", "Loss")
+		for _, result := range mc.Results {
+			summary += fmt.Sprintf("%-10s %-26s %-26s %-20s %6.1f%% %6.1f%%\n",
+				result.Method,
+				fmt.Sprintf("%.2f%% [%.2f%%, %.2f%%]", result.FinalReturn.Median, result.FinalReturn.Lower, result.FinalReturn.Upper),
+				fmt.Sprintf("%.2f%% [%.2f%%, %.2f%%]", result.MaxDrawdown.Median, result.MaxDrawdown.Lower, result.MaxDrawdown.Upper),
+				fmt.Sprintf("%.2f [%.2f, %.2f]", result.SharpeRatio.Median, result.SharpeRatio.Lower, result.SharpeRatio.Upper),
+				result.ProbabilityOfRuin,
+				result.ProbabilityOfLoss,
+			)
+		}
+		for _, skipped := range mc.Skipped {
+			summary += fmt.Sprintf("%-10s skipped: %s\n", skipped.Method, skipped.Reason)
+		}
+	}
+	summary += "\nAll Trades:\n==========="
+
 	// Add detailed trade listing
 	if len(r.Trades) > 0 {
 		summary += "\n"
@@ -0,0 +1,150 @@
+package backtester
+
+import (
+	"math"
+	"time"
+)
+
+// Periods per year used to annualize daily return statistics
+const (
+	TradingDaysPerYear  = 252 // Exchange-traded instruments
+	CalendarDaysPerYear = 365 // Instruments that trade every day, such as crypto
+)
+
+// assetTypeCrypto is the instrument asset type of cryptocurrencies
+const assetTypeCrypto = "crypto"
+
+// defaultPeriodsPerYear returns CalendarDaysPerYear when every traded symbol is crypto
+// and TradingDaysPerYear otherwise
+func (e *Engine) defaultPeriodsPerYear() int {
+	if len(e.results.Bars) == 0 {
+		return TradingDaysPerYear
+	}
+	for symbol := range e.results.Bars {
+		if instrument := e.portfolio.GetInstrument(symbol); instrument == nil || instrument.AssetType != assetTypeCrypto {
+			return TradingDaysPerYear
+		}
+	}
+	return CalendarDaysPerYear
+}
+
+// periodsPerYear returns the number of daily periods used to annualize statistics
+func (r *Results) periodsPerYear() int {
+	if r.PeriodsPerYear > 0 {
+		return r.PeriodsPerYear
+	}
+	return TradingDaysPerYear
+}
+
+// DailyReturns returns the equity curve's return for each session, from the last equity
+// value of one session to the last of the next (the first from the initial capital)
+func (r *Results) DailyReturns() []float64 {
+	return periodReturns(r.dailyCloses(r.EquityCurve), r.InitialCapital)
+}
+
+// calculateReturnMetrics sets the annualized return, volatility, Sharpe, Sortino and
+// Calmar ratios from daily equity returns, net of the risk-free rate
+func (r *Results) calculateReturnMetrics() {
+	r.Metrics.MaxDrawdown = r.MaxDrawdown
+	r.Metrics.MaxDrawdownPct = r.MaxDrawdown * 100
+
+	returns := r.DailyReturns()
+	if len(returns) == 0 || r.InitialCapital <= 0 {
+		return
+	}
+
+	periods := float64(r.periodsPerYear())
+	final := r.EquityCurve[len(r.EquityCurve)-1].Value
+	if final > 0 {
+		r.Metrics.AnnualizedReturn = (math.Pow(final/r.InitialCapital, periods/float64(len(returns))) - 1) * 100
+	}
+
+	// Calmar Ratio (Annual Return / Max Drawdown)
+	if r.MaxDrawdown > 0 {
+		r.Metrics.CalmarRatio = r.Metrics.AnnualizedReturn / (r.MaxDrawdown * 100)
+	}
+
+	if len(returns) < 2 {
+		return
+	}
+
+	riskFree := r.RiskFreeRate / periods
+	excessMean := mean(returns) - riskFree
+	volatility := stdDev(returns)
+	r.Metrics.Volatility = volatility * math.Sqrt(periods) * 100
+	if volatility > 0 {
+		r.Metrics.SharpeRatio = excessMean / volatility * math.Sqrt(periods)
+	}
+
+	// Downside deviation below the risk-free rate, over all periods
+	downside := 0.0
+	for _, ret := range returns {
+		if shortfall := ret - riskFree; shortfall < 0 {
+			downside += shortfall * shortfall
+		}
+	}
+	if downside > 0 {
+		r.Metrics.SortinoRatio = excessMean / math.Sqrt(downside/float64(len(returns))) * math.Sqrt(periods)
+	}
+}
+
+// dailyCloses keeps the last point of each session day
+func (r *Results) dailyCloses(curve []EquityPoint) []EquityPoint {
+	location := r.sessionLocation
+	if location == nil {
+		location = time.UTC
+	}
+
+	var closes []EquityPoint
+	for i, point := range curve {
+		if i+1 < len(curve) {
+			current, next := point.Timestamp.In(location), curve[i+1].Timestamp.In(location)
+			if current.Year() == next.Year() && current.YearDay() == next.YearDay() {
+				continue
+			}
+		}
+		closes = append(closes, point)
+	}
+	return closes
+}
+
+// periodReturns returns the simple return of each point from the previous one, the first
+// measured from the starting value
+func periodReturns(points []EquityPoint, start float64) []float64 {
+	returns := make([]float64, 0, len(points))
+	previous := start
+	for _, point := range points {
+		r := 0.0
+		if previous > 0 {
+			r = point.Value/previous - 1
+		}
+		returns = append(returns, r)
+		previous = point.Value
+	}
+	return returns
+}
+
+// mean returns the arithmetic mean of values
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// stdDev returns the sample standard deviation of values
+func stdDev(values []float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+	m := mean(values)
+	sumSquares := 0.0
+	for _, v := range values {
+		sumSquares += (v - m) * (v - m)
+	}
+	return math.Sqrt(sumSquares / float64(len(values)-1))
+}
@@ -0,0 +1,98 @@
+package backtester
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestDailyReturnsUseSessionCloses(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+
+	// 02:00 UTC on March 2 is still March 1 in New York
+	curve := []EquityPoint{
+		{Timestamp: time.Date(2024, 3, 1, 15, 0, 0, 0, time.UTC), Value: 105},
+		{Timestamp: time.Date(2024, 3, 1, 20, 0, 0, 0, time.UTC), Value: 110},
+		{Timestamp: time.Date(2024, 3, 2, 2, 0, 0, 0, time.UTC), Value: 121},
+		{Timestamp: time.Date(2024, 3, 2, 15, 0, 0, 0, time.UTC), Value: 99},
+	}
+	tests := []struct {
+		name     string
+		location *time.Location
+		want     []float64
+	}{
+		{"UTC", nil, []float64{0.1, -0.1}},
+		{"New York", newYork, []float64{0.21, 99.0/121 - 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &Results{InitialCapital: 100, EquityCurve: curve, sessionLocation: tt.location}
+			assertSeries(t, "daily returns", r.DailyReturns(), tt.want, 1e-12)
+		})
+	}
+
+	if got := (&Results{InitialCapital: 100}).DailyReturns(); len(got) != 0 {
+		t.Errorf("daily returns without equity = %v, want none", got)
+	}
+}
+
+func TestCalculateReturnMetrics(t *testing.T) {
+	returns := []float64{0.01, -0.02, 0.03, 0.005}
+	r := &Results{
+		InitialCapital: 100,
+		MaxDrawdown:    0.02,
+		RiskFreeRate:   0.0252,
+		EquityCurve:    dailyCurve(100, returns),
+		Metrics:        &PerformanceMetrics{},
+	}
+	r.calculateReturnMetrics()
+
+	final := 100 * 1.01 * 0.98 * 1.03 * 1.005
+	annualized := (math.Pow(final/100, 252.0/4) - 1) * 100
+	riskFree := 0.0252 / 252
+	excessMean := mean(returns) - riskFree
+	volatility := stdDev(returns)
+	// Only the 2% loss falls below the risk-free rate
+	downside := math.Sqrt((-0.02 - riskFree) * (-0.02 - riskFree) / 4)
+
+	m := r.Metrics
+	assertClose(t, "max drawdown", m.MaxDrawdownPct, 2)
+	assertClose(t, "annualized return", m.AnnualizedReturn, annualized)
+	assertClose(t, "calmar ratio", m.CalmarRatio, annualized/2)
+	assertClose(t, "volatility", m.Volatility, volatility*math.Sqrt(252)*100)
+	assertClose(t, "sharpe ratio", m.SharpeRatio, excessMean/volatility*math.Sqrt(252))
+	assertClose(t, "sortino ratio", m.SortinoRatio, excessMean/downside*math.Sqrt(252))
+}
+
+func TestCalculateReturnMetricsAnnualizesWithPeriodsPerYear(t *testing.T) {
+	returns := []float64{0.01, -0.02, 0.03, 0.005}
+	r := &Results{InitialCapital: 100, PeriodsPerYear: CalendarDaysPerYear, EquityCurve: dailyCurve(100, returns), Metrics: &PerformanceMetrics{}}
+	r.calculateReturnMetrics()
+
+	final := 100 * 1.01 * 0.98 * 1.03 * 1.005
+	assertClose(t, "annualized return", r.Metrics.AnnualizedReturn, (math.Pow(final/100, 365.0/4)-1)*100)
+	assertClose(t, "volatility", r.Metrics.Volatility, stdDev(returns)*math.Sqrt(365)*100)
+	assertClose(t, "sharpe ratio", r.Metrics.SharpeRatio, mean(returns)/stdDev(returns)*math.Sqrt(365))
+}
+
+func TestCalculateReturnMetricsNeedsTwoReturnsForRatios(t *testing.T) {
+	r := &Results{InitialCapital: 100, MaxDrawdown: 0.1, EquityCurve: dailyCurve(100, []float64{0.02}), Metrics: &PerformanceMetrics{}}
+	r.calculateReturnMetrics()
+
+	m := r.Metrics
+	assertClose(t, "annualized return", m.AnnualizedReturn, (math.Pow(1.02, 252)-1)*100)
+	assertClose(t, "calmar ratio", m.CalmarRatio, m.AnnualizedReturn/10)
+	if m.Volatility != 0 || m.SharpeRatio != 0 || m.SortinoRatio != 0 {
+		t.Errorf("volatility, sharpe and sortino = %v, %v, %v from one return, want 0", m.Volatility, m.SharpeRatio, m.SortinoRatio)
+	}
+
+	// A steady gain has no volatility or downside
+	r = &Results{InitialCapital: 100, EquityCurve: dailyCurve(100, []float64{0.01, 0.01, 0.01}), Metrics: &PerformanceMetrics{}}
+	r.calculateReturnMetrics()
+	if r.Metrics.SharpeRatio != 0 || r.Metrics.SortinoRatio != 0 {
+		t.Errorf("sharpe and sortino = %v, %v for constant returns, want 0", r.Metrics.SharpeRatio, r.Metrics.SortinoRatio)
+	}
+}
@@ -0,0 +1,173 @@
+package backtester
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ridopark/JonBuhTrader/pkg/strategy"
+)
+
+// CircuitBreakerAction defines what the engine does when a risk limit is tripped
+type CircuitBreakerAction string
+
+const (
+	// CircuitBreakerFreeze stops accepting new orders but keeps open positions
+	CircuitBreakerFreeze CircuitBreakerAction = "freeze"
+
+	// CircuitBreakerFlatten closes all open positions and stops accepting new orders
+	CircuitBreakerFlatten CircuitBreakerAction = "flatten"
+)
+
+// CircuitBreakerScope defines how long trading stays halted once a risk limit is tripped
+type CircuitBreakerScope string
+
+const (
+	// CircuitBreakerSession halts trading until the next session starts
+	CircuitBreakerSession CircuitBreakerScope = "session"
+
+	// CircuitBreakerRun halts trading for the rest of the backtest
+	CircuitBreakerRun CircuitBreakerScope = "run"
+)
+
+// ParseCircuitBreakerAction parses a circuit breaker action name
+func ParseCircuitBreakerAction(value string) (CircuitBreakerAction, error) {
+	action := CircuitBreakerAction(strings.ToLower(strings.TrimSpace(value)))
+	switch action {
+	case CircuitBreakerFreeze, CircuitBreakerFlatten:
+		return action, nil
+	default:
+		return "", fmt.Errorf("unknown circuit breaker action %q (expected freeze or flatten)", value)
+	}
+}
+
+// ParseCircuitBreakerScope parses a circuit breaker scope name
+func ParseCircuitBreakerScope(value string) (CircuitBreakerScope, error) {
+	scope := CircuitBreakerScope(strings.ToLower(strings.TrimSpace(value)))
+	switch scope {
+	case CircuitBreakerSession, CircuitBreakerRun:
+		return scope, nil
+	default:
+		return "", fmt.Errorf("unknown circuit breaker scope %q (expected session or run)", value)
+	}
+}
+
+// Risk limit rule names reported in circuit breaker events
+const (
+	RuleMaxDailyLoss         = "max_daily_loss"
+	RuleMaxDrawdown          = "max_drawdown"
+	RuleMaxConsecutiveLosses = "max_consecutive_losses"
+	RuleMaxTradesPerDay      = "max_trades_per_day"
+)
+
+// RiskLimits configures portfolio-level circuit breakers. Zero values disable a limit.
+type RiskLimits struct {
+	MaxDailyLoss         float64 // Maximum loss per session in the base currency
+	MaxDrawdown          float64 // Maximum drawdown from peak as a decimal (0.2 = 20%)
+	MaxConsecutiveLosses int     // Maximum number of consecutive losing trades
+	MaxTradesPerDay      int     // Maximum number of trades per session
+	Action               CircuitBreakerAction
+	Scope                CircuitBreakerScope
+}
+
+// DefaultRiskLimits returns limits with every guard disabled that freeze trading for the session when enabled
+func DefaultRiskLimits() RiskLimits {
+	return RiskLimits{
+		Action: CircuitBreakerFreeze,
+		Scope:  CircuitBreakerSession,
+	}
+}
+
+// Enabled returns true if at least one limit is configured
+func (l RiskLimits) Enabled() bool {
+	return l.MaxDailyLoss > 0 || l.MaxDrawdown > 0 || l.MaxConsecutiveLosses > 0 || l.MaxTradesPerDay > 0
+}
+
+// circuitBreaker tracks the state needed to evaluate risk limits during a backtest
+type circuitBreaker struct {
+	limits            RiskLimits
+	halted            bool
+	consecutiveLosses int
+	sessionTrades     int
+}
+
+// newCircuitBreaker creates a circuit breaker for the given limits
+func newCircuitBreaker(limits RiskLimits) *circuitBreaker {
+	if limits.Action == "" {
+		limits.Action = CircuitBreakerFreeze
+	}
+	if limits.Scope == "" {
+		limits.Scope = CircuitBreakerSession
+	}
+	return &circuitBreaker{limits: limits}
+}
+
+// startSession resets per-session counters and returns true if a session-scoped halt was lifted.
+// The losing streak is also reset on resume so a single further loss does not trip the breaker again.
+func (cb *circuitBreaker) startSession() bool {
+	cb.sessionTrades = 0
+	if cb.halted && cb.limits.Scope == CircuitBreakerSession {
+		cb.halted = false
+		cb.consecutiveLosses = 0
+		return true
+	}
+	return false
+}
+
+// recordTrade updates trade counters with the outcome of an executed trade
+func (cb *circuitBreaker) recordTrade(realizedPL float64, closed bool) {
+	cb.sessionTrades++
+	if !closed {
+		return
+	}
+	if realizedPL < 0 {
+		cb.consecutiveLosses++
+	} else {
+		cb.consecutiveLosses = 0
+	}
+}
+
+// checkTrades evaluates the trade count limits
+func (cb *circuitBreaker) checkTrades(timestamp time.Time) *strategy.CircuitBreakerEvent {
+	if cb.halted {
+		return nil
+	}
+
+	limits := cb.limits
+	if limits.MaxConsecutiveLosses > 0 && cb.consecutiveLosses >= limits.MaxConsecutiveLosses {
+		return cb.trip(timestamp, RuleMaxConsecutiveLosses, float64(cb.consecutiveLosses), float64(limits.MaxConsecutiveLosses))
+	}
+	if limits.MaxTradesPerDay > 0 && cb.sessionTrades >= limits.MaxTradesPerDay {
+		return cb.trip(timestamp, RuleMaxTradesPerDay, float64(cb.sessionTrades), float64(limits.MaxTradesPerDay))
+	}
+	return nil
+}
+
+// checkPortfolio evaluates the loss and drawdown limits against the current portfolio state
+func (cb *circuitBreaker) checkPortfolio(timestamp time.Time, dayPL, drawdown float64) *strategy.CircuitBreakerEvent {
+	if cb.halted {
+		return nil
+	}
+
+	limits := cb.limits
+	if limits.MaxDailyLoss > 0 && -dayPL >= limits.MaxDailyLoss {
+		return cb.trip(timestamp, RuleMaxDailyLoss, -dayPL, limits.MaxDailyLoss)
+	}
+	if limits.MaxDrawdown > 0 && drawdown >= limits.MaxDrawdown {
+		return cb.trip(timestamp, RuleMaxDrawdown, drawdown, limits.MaxDrawdown)
+	}
+	return nil
+}
+
+// trip halts trading and returns the event describing the breach
+func (cb *circuitBreaker) trip(timestamp time.Time, rule string, value, limit float64) *strategy.CircuitBreakerEvent {
+	cb.halted = true
+	return &strategy.CircuitBreakerEvent{
+		Timestamp: timestamp,
+		Rule:      rule,
+		Value:     value,
+		Limit:     limit,
+		Action:    string(cb.limits.Action),
+		Scope:     string(cb.limits.Scope),
+	}
+}
@@ -0,0 +1,210 @@
+package backtester
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ridopark/JonBuhTrader/pkg/strategy"
+)
+
+func TestParseCircuitBreakerSettings(t *testing.T) {
+	if action, err := ParseCircuitBreakerAction(" Flatten "); err != nil || action != CircuitBreakerFlatten {
+		t.Errorf("ParseCircuitBreakerAction = %q, %v; want flatten", action, err)
+	}
+	if _, err := ParseCircuitBreakerAction("liquidate"); err == nil {
+		t.Error("expected an error for an unknown action")
+	}
+	if scope, err := ParseCircuitBreakerScope("RUN"); err != nil || scope != CircuitBreakerRun {
+		t.Errorf("ParseCircuitBreakerScope = %q, %v; want run", scope, err)
+	}
+	if _, err := ParseCircuitBreakerScope(""); err == nil {
+		t.Error("expected an error for an empty scope")
+	}
+}
+
+func TestCircuitBreakerTripsEachRule(t *testing.T) {
+	tests := []struct {
+		name   string
+		limits RiskLimits
+		// below moves the breaker to just under its limit, breach pushes it over
+		below  func(cb *circuitBreaker) *strategy.CircuitBreakerEvent
+		breach func(cb *circuitBreaker) *strategy.CircuitBreakerEvent
+		rule   string
+		value  float64
+		limit  float64
+	}{
+		{
+			name:   "daily loss",
+			limits: RiskLimits{MaxDailyLoss: 500},
+			below:  func(cb *circuitBreaker) *strategy.CircuitBreakerEvent { return cb.checkPortfolio(day1, -499, 0) },
+			breach: func(cb *circuitBreaker) *strategy.CircuitBreakerEvent { return cb.checkPortfolio(day1, -500, 0) },
+			rule:   RuleMaxDailyLoss, value: 500, limit: 500,
+		},
+		{
+			name:   "drawdown",
+			limits: RiskLimits{MaxDrawdown: 0.2},
+			below:  func(cb *circuitBreaker) *strategy.CircuitBreakerEvent { return cb.checkPortfolio(day1, 1000, 0.19) },
+			breach: func(cb *circuitBreaker) *strategy.CircuitBreakerEvent { return cb.checkPortfolio(day1, 1000, 0.25) },
+			rule:   RuleMaxDrawdown, value: 0.25, limit: 0.2,
+		},
+		{
+			name:   "consecutive losses",
+			limits: RiskLimits{MaxConsecutiveLosses: 2},
+			below: func(cb *circuitBreaker) *strategy.CircuitBreakerEvent {
+				cb.recordTrade(-10, true)
+				cb.recordTrade(-5, false) // Opening trades neither extend nor break the streak
+				return cb.checkTrades(day1)
+			},
+			breach: func(cb *circuitBreaker) *strategy.CircuitBreakerEvent {
+				cb.recordTrade(-10, true)
+				return cb.checkTrades(day1)
+			},
+			rule: RuleMaxConsecutiveLosses, value: 2, limit: 2,
+		},
+		{
+			name:   "trades per day",
+			limits: RiskLimits{MaxTradesPerDay: 3},
+			below: func(cb *circuitBreaker) *strategy.CircuitBreakerEvent {
+				cb.recordTrade(10, true)
+				cb.recordTrade(-10, false)
+				return cb.checkTrades(day1)
+			},
+			breach: func(cb *circuitBreaker) *strategy.CircuitBreakerEvent {
+				cb.recordTrade(10, true)
+				return cb.checkTrades(day1)
+			},
+			rule: RuleMaxTradesPerDay, value: 3, limit: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cb := newCircuitBreaker(tt.limits)
+			if event := tt.below(cb); event != nil {
+				t.Fatalf("tripped below the limit: %+v", event)
+			}
+			event := tt.breach(cb)
+			if event == nil {
+				t.Fatal("expected the breaker to trip")
+			}
+			if event.Rule != tt.rule || event.Value != tt.value || event.Limit != tt.limit || !event.Timestamp.Equal(day1) {
+				t.Errorf("event = %+v, want %s %v/%v", event, tt.rule, tt.value, tt.limit)
+			}
+			// Unset action and scope default to freezing for the session
+			if event.Action != string(CircuitBreakerFreeze) || event.Scope != string(CircuitBreakerSession) {
+				t.Errorf("event action/scope = %s/%s, want freeze/session", event.Action, event.Scope)
+			}
+			if !cb.halted {
+				t.Error("expected trading to be halted")
+			}
+			// A halted breaker does not trip again
+			if again := tt.breach(cb); again != nil {
+				t.Errorf("tripped twice: %+v", again)
+			}
+		})
+	}
+}
+
+func TestCircuitBreakerWinningTradeResetsLosingStreak(t *testing.T) {
+	cb := newCircuitBreaker(RiskLimits{MaxConsecutiveLosses: 2})
+	cb.recordTrade(-10, true)
+	cb.recordTrade(10, true)
+	cb.recordTrade(-10, true)
+	if event := cb.checkTrades(day1); event != nil {
+		t.Errorf("tripped after a winning trade broke the streak: %+v", event)
+	}
+}
+
+func TestCircuitBreakerScopeControlsReset(t *testing.T) {
+	tests := []struct {
+		scope   CircuitBreakerScope
+		resumed bool
+	}{
+		{CircuitBreakerSession, true},
+		{CircuitBreakerRun, false},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.scope), func(t *testing.T) {
+			cb := newCircuitBreaker(RiskLimits{MaxConsecutiveLosses: 1, MaxTradesPerDay: 5, Scope: tt.scope})
+			cb.recordTrade(-10, true)
+			if event := cb.checkTrades(day1); event == nil || event.Scope != string(tt.scope) {
+				t.Fatalf("event = %+v, want a %s-scoped trip", event, tt.scope)
+			}
+
+			if resumed := cb.startSession(); resumed != tt.resumed || cb.halted == tt.resumed {
+				t.Errorf("startSession = %v with halted %v, want resumed %v", resumed, cb.halted, tt.resumed)
+			}
+			if cb.sessionTrades != 0 {
+				t.Errorf("session trades = %d, want 0 after a new session", cb.sessionTrades)
+			}
+			if tt.resumed && cb.consecutiveLosses != 0 {
+				t.Errorf("losing streak = %d, want 0 after resuming", cb.consecutiveLosses)
+			}
+		})
+	}
+}
+
+func TestEngineCircuitBreakerHaltsTrading(t *testing.T) {
+	// A 20 point drop on 10 shares loses 200 on the second bar of March 1
+	timestamps := []time.Time{
+		time.Date(2024, 3, 1, 14, 0, 0, 0, time.UTC),
+		time.Date(2024, 3, 1, 15, 0, 0, 0, time.UTC),
+		time.Date(2024, 3, 1, 16, 0, 0, 0, time.UTC),
+		time.Date(2024, 3, 4, 14, 0, 0, 0, time.UTC),
+	}
+	closes := []float64{100, 80, 80, 80}
+
+	tests := []struct {
+		name   string
+		action CircuitBreakerAction
+		scope  CircuitBreakerScope
+		// Orders submitted on the last two bars that are rejected, and whether the trip flattens
+		rejected int
+		flat     bool
+	}{
+		{"freeze for session", CircuitBreakerFreeze, CircuitBreakerSession, 1, false},
+		{"freeze for run", CircuitBreakerFreeze, CircuitBreakerRun, 2, false},
+		{"flatten for session", CircuitBreakerFlatten, CircuitBreakerSession, 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &scriptedStrategy{onDataPoint: func(ctx strategy.Context, index int, dataPoint strategy.DataPoint) []strategy.Order {
+				if index == 0 {
+					return []strategy.Order{marketOrder(strategy.OrderSideBuy, 10)}
+				}
+				if index >= 2 {
+					return []strategy.Order{marketOrder(strategy.OrderSideBuy, 1)}
+				}
+				return nil
+			}}
+			engine := newFrictionlessEngine(s, priceFeed(timestamps, closes))
+			engine.SetRiskLimits(RiskLimits{MaxDailyLoss: 150, Action: tt.action, Scope: tt.scope})
+			if err := engine.Run(); err != nil {
+				t.Fatalf("Run: %v", err)
+			}
+
+			results := engine.GetResults()
+			if len(results.CircuitBreakerEvents) != 1 || len(s.events) != 1 {
+				t.Fatalf("events = %+v (strategy saw %d), want one", results.CircuitBreakerEvents, len(s.events))
+			}
+			event := results.CircuitBreakerEvents[0]
+			if event.Rule != RuleMaxDailyLoss || !event.Timestamp.Equal(timestamps[1]) {
+				t.Errorf("event = %+v, want %s on the second bar", event, RuleMaxDailyLoss)
+			}
+			if len(results.RejectedOrders) != tt.rejected {
+				t.Errorf("rejected %d orders, want %d", len(results.RejectedOrders), tt.rejected)
+			}
+
+			// The entry, any flatten, any resumed order and the end-of-run liquidation
+			wantTrades := 2 + (2 - tt.rejected)
+			if tt.flat {
+				wantTrades++
+			}
+			if len(results.Trades) != wantTrades {
+				t.Fatalf("recorded %d trades, want %d", len(results.Trades), wantTrades)
+			}
+			if flattened := results.Trades[1].Reason == "circuit_breaker_"+RuleMaxDailyLoss; flattened != tt.flat {
+				t.Errorf("second trade reason = %q, flattened %v; want %v", results.Trades[1].Reason, flattened, tt.flat)
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package backtester
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// DefaultRollingWindows are the rolling metric windows, in daily periods, used by default
+var DefaultRollingWindows = []int{63}
+
+// RollingPoint holds metrics over the window of daily periods ending at Timestamp
+type RollingPoint struct {
+	Timestamp  time.Time `json:"timestamp"`
+	Return     float64   `json:"return"`      // Compounded return over the window, in percent
+	Sharpe     float64   `json:"sharpe"`      // Annualized, net of the risk-free rate
+	Volatility float64   `json:"volatility"`  // Annualized, in percent
+	Beta       float64   `json:"beta"`        // Against the benchmark (0 without one)
+	WinRate    float64   `json:"win_rate"`    // Percentage of round trips closed in the window with positive net P&L
+	RoundTrips int       `json:"round_trips"` // Round trips closed in the window
+	Drawdown   float64   `json:"drawdown"`    // Maximum peak-to-trough decline within the window, in percent
+}
+
+// RollingMetrics is a time series of metrics over a fixed window
+type RollingMetrics struct {
+	Window int            `json:"window"` // Window length in daily periods
+	Points []RollingPoint `json:"points"`
+}
+
+// SetRollingWindows sets the windows, in daily periods, of the rolling metrics
+func (e *Engine) SetRollingWindows(windows []int) error {
+	for _, window := range windows {
+		if window < 2 {
+			return fmt.Errorf("rolling window must be at least 2 periods, got %d", window)
+		}
+	}
+	e.results.rollingWindows = windows
+	return nil
+}
+
+// calculateRollingMetrics computes the rolling metrics for each window from daily equity,
+// benchmark and round-trip results
+func (r *Results) calculateRollingMetrics() {
+	r.Rolling = nil
+	windows := r.rollingWindows
+	if windows == nil {
+		windows = DefaultRollingWindows
+	}
+
+	closes := r.dailyCloses(r.EquityCurve)
+	returns := periodReturns(closes, r.InitialCapital)
+	var benchmarkReturns []float64
+	if len(r.BenchmarkCurve) == len(r.EquityCurve) {
+		benchmarkReturns = periodReturns(r.dailyCloses(r.BenchmarkCurve), r.InitialCapital)
+	}
+
+	for _, window := range windows {
+		if window < 2 || window > len(returns) {
+			continue
+		}
+		rolling := RollingMetrics{Window: window}
+		for end := window - 1; end < len(returns); end++ {
+			start := end - window + 1
+			point := RollingPoint{Timestamp: closes[end].Timestamp}
+			r.measureWindow(&point, returns[start:end+1], windowSlice(benchmarkReturns, start, end))
+
+			// Drawdown and round trips are measured from the close before the window
+			startValue, startTime := r.InitialCapital, time.Time{}
+			if start > 0 {
+				startValue, startTime = closes[start-1].Value, closes[start-1].Timestamp
+			}
+			point.Drawdown = windowDrawdown(startValue, closes[start:end+1]) * 100
+			point.WinRate, point.RoundTrips = r.windowWinRate(startTime, closes[end].Timestamp)
+
+			rolling.Points = append(rolling.Points, point)
+		}
+		r.Rolling = append(r.Rolling, rolling)
+	}
+}
+
+// measureWindow sets the return, Sharpe, volatility and beta of a window of daily returns
+func (r *Results) measureWindow(point *RollingPoint, returns, benchmarkReturns []float64) {
+	growth := 1.0
+	for _, ret := range returns {
+		growth *= 1 + ret
+	}
+	point.Return = (growth - 1) * 100
+
+	periods := float64(r.periodsPerYear())
+	volatility := stdDev(returns)
+	point.Volatility = volatility * math.Sqrt(periods) * 100
+	if volatility > 0 {
+		point.Sharpe = (mean(returns) - r.RiskFreeRate/periods) / volatility * math.Sqrt(periods)
+	}
+
+	if len(benchmarkReturns) == len(returns) {
+		strategyMean, benchmarkMean := mean(returns), mean(benchmarkReturns)
+		var covariance, variance float64
+		for i := range returns {
+			db := benchmarkReturns[i] - benchmarkMean
+			covariance += (returns[i] - strategyMean) * db
+			variance += db * db
+		}
+		if variance > 0 {
+			point.Beta = covariance / variance
+		}
+	}
+}
+
+// windowWinRate returns the win rate and count of round trips closed after from and up to to
+func (r *Results) windowWinRate(from, to time.Time) (float64, int) {
+	wins, count := 0, 0
+	for _, rt := range r.RoundTrips {
+		if rt.ExitTime.After(from) && !rt.ExitTime.After(to) {
+			count++
+			if rt.NetPL > 0 {
+				wins++
+			}
+		}
+	}
+	if count == 0 {
+		return 0, 0
+	}
+	return float64(wins) / float64(count) * 100, count
+}
+
+// windowDrawdown returns the largest peak-to-trough decline of the values, starting from
+// startValue, as a decimal
+func windowDrawdown(startValue float64, points []EquityPoint) float64 {
+	peak, maxDrawdown := startValue, 0.0
+	for _, point := range points {
+		peak = math.Max(peak, point.Value)
+		if peak > 0 {
+			maxDrawdown = math.Max(maxDrawdown, 1-point.Value/peak)
+		}
+	}
+	return maxDrawdown
+}
+
+// windowSlice returns values[start:end+1], or nil if values is empty
+func windowSlice(values []float64, start, end int) []float64 {
+	if len(values) == 0 {
+		return nil
+	}
+	return values[start : end+1]
+}
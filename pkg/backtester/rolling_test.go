@@ -0,0 +1,90 @@
+package backtester
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestRollingMetricsWindowEdges(t *testing.T) {
+	returns := []float64{0.1, -0.2, 0.05, 0.1, -0.05}
+	curve := dailyCurve(100, returns) // Closes 110, 88, 92.4, 101.64, 96.558
+	benchmark := make([]float64, len(returns))
+	for i, ret := range returns {
+		benchmark[i] = ret / 2
+	}
+	r := &Results{
+		InitialCapital: 100,
+		EquityCurve:    curve,
+		BenchmarkCurve: dailyCurve(100, benchmark),
+		RoundTrips: []RoundTrip{
+			{ExitTime: curve[0].Timestamp, NetPL: 5},                // On the close before the second window starts
+			{ExitTime: curve[2].Timestamp, NetPL: -3},               // On the close ending the first window
+			{ExitTime: curve[3].Timestamp.Add(time.Hour), NetPL: 2}, // After the close ending the second window
+		},
+		rollingWindows: []int{1, 3, 5, 6},
+	}
+	r.calculateRollingMetrics()
+
+	// Windows shorter than 2 or longer than the run are skipped
+	if len(r.Rolling) != 2 || r.Rolling[0].Window != 3 || r.Rolling[1].Window != 5 {
+		t.Fatalf("rolling windows = %+v, want 3 and 5", r.Rolling)
+	}
+
+	points := r.Rolling[0].Points
+	if len(points) != 3 {
+		t.Fatalf("got %d points for the 3-day window, want 3", len(points))
+	}
+	for i, point := range points {
+		if !point.Timestamp.Equal(curve[i+2].Timestamp) {
+			t.Errorf("point %d timestamp = %v, want %v", i, point.Timestamp, curve[i+2].Timestamp)
+		}
+		window := returns[i : i+3]
+		assertClose(t, "beta", point.Beta, 2)
+		assertClose(t, "volatility", point.Volatility, stdDev(window)*math.Sqrt(252)*100)
+		assertClose(t, "sharpe", point.Sharpe, mean(window)/stdDev(window)*math.Sqrt(252))
+	}
+	assertClose(t, "first return", points[0].Return, (1.1*0.8*1.05-1)*100)
+	assertClose(t, "last return", points[2].Return, (1.05*1.1*0.95-1)*100)
+
+	// The first window's drawdown starts from the initial capital, later ones from the
+	// close before the window: 110 falls to 88 in the first two
+	assertClose(t, "first drawdown", points[0].Drawdown, 20)
+	assertClose(t, "second drawdown", points[1].Drawdown, 20)
+	assertClose(t, "third drawdown", points[2].Drawdown, (1-96.558/101.64)*100)
+
+	// Round trips count in a window when they close after the close before it and up to its last close
+	wantRoundTrips := []int{2, 1, 2}
+	wantWinRates := []float64{50, 0, 50}
+	for i, point := range points {
+		if point.RoundTrips != wantRoundTrips[i] {
+			t.Errorf("point %d has %d round trips, want %d", i, point.RoundTrips, wantRoundTrips[i])
+		}
+		assertClose(t, "win rate", point.WinRate, wantWinRates[i])
+	}
+
+	// A window as long as the run has one point covering every return
+	full := r.Rolling[1].Points
+	if len(full) != 1 || !full[0].Timestamp.Equal(curve[4].Timestamp) || full[0].RoundTrips != 3 {
+		t.Fatalf("5-day window points = %+v, want one point with every round trip", full)
+	}
+	assertClose(t, "full return", full[0].Return, (96.558/100-1)*100)
+	assertClose(t, "full drawdown", full[0].Drawdown, 20)
+}
+
+func TestRollingMetricsWithoutBenchmark(t *testing.T) {
+	r := &Results{
+		InitialCapital: 100,
+		EquityCurve:    dailyCurve(100, []float64{0.01, 0.02, -0.01}),
+		rollingWindows: []int{2},
+	}
+	r.calculateRollingMetrics()
+	if len(r.Rolling) != 1 || len(r.Rolling[0].Points) != 2 {
+		t.Fatalf("rolling = %+v, want two points", r.Rolling)
+	}
+	for _, point := range r.Rolling[0].Points {
+		if point.Beta != 0 || point.RoundTrips != 0 || point.WinRate != 0 {
+			t.Errorf("point = %+v, want no beta or round trips", point)
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package backtester
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/ridopark/JonBuhTrader/pkg/strategy"
+)
+
+// Round-trip directions
+const (
+	DirectionLong  = "LONG"
+	DirectionShort = "SHORT"
+)
+
+// unspecifiedKey groups round trips whose entry order had no reason or strategy
+const unspecifiedKey = "unspecified"
+
+// RoundTrip is a position opened and closed again, matched FIFO from the trade log.
+// Monetary values are in the instrument's currency, except BaseFees and BaseNetPL, which
+// are converted into the base currency at the exit fill's FX rate.
+type RoundTrip struct {
+	Symbol      string    `json:"symbol"`
+	Direction   string    `json:"direction"` // DirectionLong or DirectionShort
+	EntryTime   time.Time `json:"entry_time"`
+	ExitTime    time.Time `json:"exit_time"`
+	EntryPrice  float64   `json:"entry_price"`
+	ExitPrice   float64   `json:"exit_price"`
+	Quantity    float64   `json:"quantity"`
+	Fees        float64   `json:"fees"`        // Entry and exit commission, SEC fee, FINRA TAF and slippage for the quantity
+	GrossPL     float64   `json:"gross_pl"`    // Price change times quantity and contract multiplier
+	NetPL       float64   `json:"net_pl"`      // Gross P&L less fees
+	BaseFees    float64   `json:"base_fees"`   // Fees in the base currency
+	BaseNetPL   float64   `json:"base_net_pl"` // Net P&L in the base currency
+	ReturnPct   float64   `json:"return_pct"`  // Net P&L as a percentage of the entry notional
+	BarsHeld    int       `json:"bars_held"`   // Bars of the symbol after entry, up to and including the exit bar
+	MAE         float64   `json:"mae"`         // Maximum adverse excursion as a percentage of the entry price
+	MFE         float64   `json:"mfe"`         // Maximum favorable excursion as a percentage of the entry price
+	EntryReason string    `json:"entry_reason"`
+	ExitReason  string    `json:"exit_reason"`
+	Strategy    string    `json:"strategy"` // Strategy that opened the position
+}
+
+// RoundTripStats summarizes a group of round trips, which may trade in different
+// currencies; P&L is in the base currency
+type RoundTripStats struct {
+	Count           int     `json:"count"`
+	WinRate         float64 `json:"win_rate"` // Percentage of round trips with positive net P&L
+	NetPL           float64 `json:"net_pl"`
+	Expectancy      float64 `json:"expectancy"`     // Mean net P&L per round trip
+	ExpectancyPct   float64 `json:"expectancy_pct"` // Mean return per round trip, in percent
+	EdgeRatio       float64 `json:"edge_ratio"`     // Mean MFE over mean MAE (above 1 means entries move favorably more than adversely)
+	AvgMAE          float64 `json:"avg_mae"`        // In percent of entry price
+	AvgMFE          float64 `json:"avg_mfe"`        // In percent of entry price
+	AvgBarsHeld     float64 `json:"avg_bars_held"`
+	AvgHoldingHours float64 `json:"avg_holding_hours"`
+}
+
+// openLot is the unmatched part of an entry fill
+type openLot struct {
+	trade    strategy.TradeEvent
+	quantity float64 // Remaining quantity, always positive
+	fees     float64 // Remaining entry fees
+}
+
+// RoundTrips matches fills FIFO per symbol into round trips, including shorts, and
+// measures each against the symbol's bars. multipliers maps symbols to contract
+// multipliers; missing symbols use 1.
+func RoundTrips(trades []strategy.TradeEvent, bars map[string][]strategy.BarData, multipliers map[string]float64) []RoundTrip {
+	var roundTrips []RoundTrip
+	lots := make(map[string][]openLot)
+	long := make(map[string]bool) // Direction of each symbol's open lots
+
+	for _, trade := range trades {
+		if trade.Quantity <= 0 {
+			continue
+		}
+		symbol := trade.Symbol
+		buying := trade.Side == strategy.OrderSideBuy
+		fees := trade.Commission + trade.SecFee + trade.FinraTaf + trade.Slippage
+		remaining := trade.Quantity
+
+		// A fill against the open direction closes lots first
+		for len(lots[symbol]) > 0 && long[symbol] != buying && remaining > 0 {
+			lot := &lots[symbol][0]
+			quantity := math.Min(lot.quantity, remaining)
+			entryFees := lot.fees * quantity / lot.quantity
+			exitFees := fees * quantity / trade.Quantity
+
+			roundTrips = append(roundTrips, newRoundTrip(lot.trade, trade, quantity, entryFees+exitFees, bars[symbol], multiplier(multipliers, symbol)))
+
+			lot.fees -= entryFees
+			lot.quantity -= quantity
+			remaining -= quantity
+			if lot.quantity <= 1e-9 {
+				lots[symbol] = lots[symbol][1:]
+			}
+		}
+
+		// Anything left opens or adds to a position
+		if remaining > 1e-9 {
+			if len(lots[symbol]) == 0 {
+				long[symbol] = buying
+			}
+			lots[symbol] = append(lots[symbol], openLot{trade: trade, quantity: remaining, fees: fees * remaining / trade.Quantity})
+		}
+	}
+
+	sort.SliceStable(roundTrips, func(i, j int) bool {
+		return roundTrips[i].ExitTime.Before(roundTrips[j].ExitTime)
+	})
+	return roundTrips
+}
+
+// newRoundTrip measures a matched entry and exit
+func newRoundTrip(entry, exit strategy.TradeEvent, quantity, fees float64, bars []strategy.BarData, multiplier float64) RoundTrip {
+	direction, sign := DirectionLong, 1.0
+	if entry.Side == strategy.OrderSideSell {
+		direction, sign = DirectionShort, -1.0
+	}
+
+	rt := RoundTrip{
+		Symbol:      entry.Symbol,
+		Direction:   direction,
+		EntryTime:   entry.Timestamp,
+		ExitTime:    exit.Timestamp,
+		EntryPrice:  entry.Price,
+		ExitPrice:   exit.Price,
+		Quantity:    quantity,
+		Fees:        fees,
+		GrossPL:     sign * (exit.Price - entry.Price) * quantity * multiplier,
+		EntryReason: entry.Reason,
+		ExitReason:  exit.Reason,
+		Strategy:    entry.Strategy,
+	}
+	rt.NetPL = rt.GrossPL - fees
+	rt.BaseFees, rt.BaseNetPL = fees*fxRate(exit), rt.NetPL*fxRate(exit)
+	if notional := entry.Price * quantity * multiplier; notional > 0 {
+		rt.ReturnPct = rt.NetPL / notional * 100
+	}
+
+	// Excursions over the bars after entry, through the exit bar
+	start := sort.Search(len(bars), func(i int) bool { return bars[i].Timestamp.After(entry.Timestamp) })
+	high, low := math.Max(entry.Price, exit.Price), math.Min(entry.Price, exit.Price)
+	for i := start; i < len(bars) && !bars[i].Timestamp.After(exit.Timestamp); i++ {
+		high, low = math.Max(high, bars[i].High), math.Min(low, bars[i].Low)
+		rt.BarsHeld++
+	}
+	if entry.Price > 0 {
+		up, down := (high/entry.Price-1)*100, (1-low/entry.Price)*100
+		if direction == DirectionLong {
+			rt.MFE, rt.MAE = up, down
+		} else {
+			rt.MFE, rt.MAE = down, up
+		}
+	}
+	return rt
+}
+
+// SummarizeRoundTrips computes statistics for a group of round trips
+func SummarizeRoundTrips(roundTrips []RoundTrip) RoundTripStats {
+	stats := RoundTripStats{Count: len(roundTrips)}
+	if len(roundTrips) == 0 {
+		return stats
+	}
+
+	var wins int
+	var returns, mae, mfe, bars, hours float64
+	for _, rt := range roundTrips {
+		if rt.NetPL > 0 {
+			wins++
+		}
+		stats.NetPL += rt.BaseNetPL
+		returns += rt.ReturnPct
+		mae += rt.MAE
+		mfe += rt.MFE
+		bars += float64(rt.BarsHeld)
+		hours += rt.ExitTime.Sub(rt.EntryTime).Hours()
+	}
+
+	n := float64(len(roundTrips))
+	stats.WinRate = float64(wins) / n * 100
+	stats.Expectancy = stats.NetPL / n
+	stats.ExpectancyPct = returns / n
+	stats.AvgMAE = mae / n
+	stats.AvgMFE = mfe / n
+	stats.AvgBarsHeld = bars / n
+	stats.AvgHoldingHours = hours / n
+	if stats.AvgMAE > 0 {
+		stats.EdgeRatio = stats.AvgMFE / stats.AvgMAE
+	}
+	return stats
+}
+
+// calculateRoundTripStats summarizes the round trips overall and by entry reason
+func (r *Results) calculateRoundTripStats() {
+	overall := SummarizeRoundTrips(r.RoundTrips)
+	r.Metrics.RoundTrips = &overall
+
+	byReason := make(map[string][]RoundTrip)
+	for _, rt := range r.RoundTrips {
+		reason := attributionKey(rt.EntryReason)
+		byReason[reason] = append(byReason[reason], rt)
+	}
+	r.Metrics.RoundTripsByReason = make(map[string]RoundTripStats, len(byReason))
+	for reason, group := range byReason {
+		r.Metrics.RoundTripsByReason[reason] = SummarizeRoundTrips(group)
+	}
+}
+
+// fxRate returns the rate the trade converted its currency into the base currency at,
+// treating trades without a recorded rate as already in the base currency
+func fxRate(trade strategy.TradeEvent) float64 {
+	if trade.FXRate > 0 {
+		return trade.FXRate
+	}
+	return 1
+}
+
+// multiplier returns a symbol's contract multiplier, defaulting to 1
+func multiplier(multipliers map[string]float64, symbol string) float64 {
+	if m, ok := multipliers[symbol]; ok && m > 0 {
+		return m
+	}
+	return 1
+}
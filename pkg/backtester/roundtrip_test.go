@@ -0,0 +1,161 @@
+package backtester
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ridopark/JonBuhTrader/pkg/strategy"
+)
+
+// fill returns a testSymbol trade at hour index with the given commission
+func fill(index int, side strategy.OrderSide, quantity, price, commission float64, reason string) strategy.TradeEvent {
+	return strategy.TradeEvent{
+		Symbol:     testSymbol,
+		Side:       side,
+		Quantity:   quantity,
+		Price:      price,
+		Commission: commission,
+		Timestamp:  time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC).Add(time.Duration(index) * time.Hour),
+		Strategy:   "scripted",
+		Reason:     reason,
+	}
+}
+
+// hourlyBars returns testSymbol bars an hour apart from high, low and close triples
+func hourlyBars(hlc ...[3]float64) []strategy.BarData {
+	bars := make([]strategy.BarData, len(hlc))
+	for i, v := range hlc {
+		bars[i] = strategy.BarData{
+			Symbol:    testSymbol,
+			Timestamp: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC).Add(time.Duration(i) * time.Hour),
+			Open:      v[2],
+			High:      v[0],
+			Low:       v[1],
+			Close:     v[2],
+		}
+	}
+	return bars
+}
+
+func TestRoundTripsMatchFIFO(t *testing.T) {
+	bars := map[string][]strategy.BarData{testSymbol: hourlyBars(
+		[3]float64{101, 99, 100},
+		[3]float64{103, 98, 102},
+		[3]float64{108, 97, 106},
+		[3]float64{106, 104, 105},
+		[3]float64{105, 103, 104},
+		[3]float64{102, 100, 101},
+	)}
+	trades := []strategy.TradeEvent{
+		fill(0, strategy.OrderSideBuy, 10, 100, 1, "first"),
+		fill(1, strategy.OrderSideBuy, 10, 102, 2, "second"),
+		fill(2, strategy.OrderSideBuy, 0, 106, 0, "empty"), // Ignored
+		// Closes the first lot and half the second
+		fill(3, strategy.OrderSideSell, 15, 105, 3, "take profit"),
+		// Closes the rest of the second lot and opens a short with the other 5
+		fill(4, strategy.OrderSideSell, 10, 104, 1, "reverse"),
+		fill(5, strategy.OrderSideBuy, 5, 101, 0.5, "cover"),
+	}
+
+	got := RoundTrips(trades, bars, nil)
+	want := []RoundTrip{
+		{
+			Direction: DirectionLong, EntryPrice: 100, ExitPrice: 105, Quantity: 10,
+			Fees: 1 + 3*10.0/15, GrossPL: 50, BarsHeld: 3,
+			// Bars 1 to 3 range from 97 to 108
+			MAE: 3, MFE: 8,
+			EntryReason: "first", ExitReason: "take profit",
+		},
+		{
+			Direction: DirectionLong, EntryPrice: 102, ExitPrice: 105, Quantity: 5,
+			Fees: 2*5.0/10 + 3*5.0/15, GrossPL: 15, BarsHeld: 2,
+			MAE: (1 - 97.0/102) * 100, MFE: (108.0/102 - 1) * 100,
+			EntryReason: "second", ExitReason: "take profit",
+		},
+		{
+			Direction: DirectionLong, EntryPrice: 102, ExitPrice: 104, Quantity: 5,
+			Fees: 2*5.0/10 + 1*5.0/10, GrossPL: 10, BarsHeld: 3,
+			MAE: (1 - 97.0/102) * 100, MFE: (108.0/102 - 1) * 100,
+			EntryReason: "second", ExitReason: "reverse",
+		},
+		{
+			// Bar 5 stays between 100 and 102, below the entry at 104
+			Direction: DirectionShort, EntryPrice: 104, ExitPrice: 101, Quantity: 5,
+			Fees: 1*5.0/10 + 0.5, GrossPL: 15, BarsHeld: 1,
+			MAE: 0, MFE: (1 - 100.0/104) * 100,
+			EntryReason: "reverse", ExitReason: "cover",
+		},
+	}
+	entries := []int{0, 1, 1, 4}
+	exits := []int{3, 3, 4, 5}
+
+	if len(got) != len(want) {
+		t.Fatalf("got %d round trips, want %d: %+v", len(got), len(want), got)
+	}
+	for i, w := range want {
+		rt := got[i]
+		if rt.Symbol != testSymbol || rt.Direction != w.Direction || rt.EntryReason != w.EntryReason || rt.ExitReason != w.ExitReason || rt.Strategy != "scripted" {
+			t.Errorf("round trip %d = %s %s %s to %s by %s, want %s %s %s to %s by scripted",
+				i, rt.Symbol, rt.Direction, rt.EntryReason, rt.ExitReason, rt.Strategy, testSymbol, w.Direction, w.EntryReason, w.ExitReason)
+		}
+		if !rt.EntryTime.Equal(trades[entries[i]].Timestamp) || !rt.ExitTime.Equal(trades[exits[i]].Timestamp) {
+			t.Errorf("round trip %d held from %v to %v, want %v to %v", i, rt.EntryTime, rt.ExitTime, trades[entries[i]].Timestamp, trades[exits[i]].Timestamp)
+		}
+		if rt.BarsHeld != w.BarsHeld {
+			t.Errorf("round trip %d held %d bars, want %d", i, rt.BarsHeld, w.BarsHeld)
+		}
+		assertClose(t, "entry price", rt.EntryPrice, w.EntryPrice)
+		assertClose(t, "exit price", rt.ExitPrice, w.ExitPrice)
+		assertClose(t, "quantity", rt.Quantity, w.Quantity)
+		assertClose(t, "fees", rt.Fees, w.Fees)
+		assertClose(t, "gross P&L", rt.GrossPL, w.GrossPL)
+		assertClose(t, "net P&L", rt.NetPL, w.GrossPL-w.Fees)
+		assertClose(t, "return", rt.ReturnPct, (w.GrossPL-w.Fees)/(w.EntryPrice*w.Quantity)*100)
+		assertClose(t, "MAE", rt.MAE, w.MAE)
+		assertClose(t, "MFE", rt.MFE, w.MFE)
+	}
+
+	stats := SummarizeRoundTrips(got)
+	netPL := 0.0
+	for _, w := range want {
+		netPL += w.GrossPL - w.Fees
+	}
+	if stats.Count != 4 {
+		t.Errorf("count = %d, want 4", stats.Count)
+	}
+	assertClose(t, "win rate", stats.WinRate, 100)
+	assertClose(t, "net P&L", stats.NetPL, netPL)
+	assertClose(t, "expectancy", stats.Expectancy, netPL/4)
+	assertClose(t, "average bars held", stats.AvgBarsHeld, 9.0/4)
+}
+
+func TestRoundTripsKeepSymbolsApart(t *testing.T) {
+	other := func(trade strategy.TradeEvent) strategy.TradeEvent {
+		trade.Symbol = "ES"
+		return trade
+	}
+	// ES is a futures contract with a multiplier of 50, shorted at a loss
+	trades := []strategy.TradeEvent{
+		fill(0, strategy.OrderSideBuy, 10, 100, 0, "long"),
+		other(fill(1, strategy.OrderSideSell, 2, 5000, 0, "short")),
+		fill(2, strategy.OrderSideSell, 10, 99, 0, "stop"),
+		other(fill(3, strategy.OrderSideBuy, 2, 5010, 0, "cover")),
+	}
+
+	got := RoundTrips(trades, nil, map[string]float64{"ES": 50})
+	if len(got) != 2 {
+		t.Fatalf("got %d round trips, want 2: %+v", len(got), got)
+	}
+	if got[0].Symbol != testSymbol || got[0].Direction != DirectionLong || got[1].Symbol != "ES" || got[1].Direction != DirectionShort {
+		t.Errorf("round trips = %s %s and %s %s, want %s LONG and ES SHORT", got[0].Symbol, got[0].Direction, got[1].Symbol, got[1].Direction, testSymbol)
+	}
+	assertClose(t, "long P&L", got[0].NetPL, -10)
+	assertClose(t, "short P&L", got[1].NetPL, -10*2*50)
+	assertClose(t, "short return", got[1].ReturnPct, -1000.0/(5000*2*50)*100)
+	// Without bars, excursions span the entry and exit prices
+	if got[1].BarsHeld != 0 {
+		t.Errorf("short held %d bars without bars, want 0", got[1].BarsHeld)
+	}
+	assertClose(t, "short MAE", got[1].MAE, 0.2)
+	assertClose(t, "short MFE", got[1].MFE, 0)
+}
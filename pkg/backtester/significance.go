@@ -0,0 +1,102 @@
+package backtester
+
+import "math"
+
+// MinTrackRecordConfidence is the confidence at which the minimum track record length
+// rejects a Sharpe ratio of zero
+const MinTrackRecordConfidence = 0.95
+
+// eulerMascheroni is the Euler–Mascheroni constant, used for the expected maximum Sharpe
+// ratio of independent trials
+const eulerMascheroni = 0.5772156649015329
+
+// SetSharpeTrials sets the number of strategy configurations tried to arrive at this run,
+// which the deflated Sharpe ratio corrects for. Values below 1 mean a single trial.
+func (e *Engine) SetSharpeTrials(trials int) {
+	e.results.SharpeTrials = trials
+}
+
+// SetSharpeTrialVariance sets the variance of the daily (non-annualized) Sharpe ratios
+// across the trials, which scales the best Sharpe ratio expected by chance. Zero or less
+// uses the variance of this run's Sharpe estimate instead, which assumes the trials'
+// Sharpe ratios vary only by estimation error.
+func (e *Engine) SetSharpeTrialVariance(variance float64) {
+	e.results.SharpeTrialVariance = max(variance, 0)
+}
+
+// calculateSignificance sets the skewness and kurtosis of daily returns and the
+// probabilistic and deflated Sharpe ratios and minimum track record length of Bailey and
+// López de Prado, which account for the sample length and non-normal returns. The minimum
+// track record length is in daily periods.
+func (r *Results) calculateSignificance() {
+	returns := r.DailyReturns()
+	n := float64(len(returns))
+	if n < 3 {
+		return
+	}
+
+	volatility := stdDev(returns)
+	if volatility == 0 {
+		return
+	}
+	skewness, kurtosis := moments(returns)
+	r.Metrics.Skewness = skewness
+	r.Metrics.Kurtosis = kurtosis - 3
+
+	// Per-period Sharpe ratio and the variance of its estimate
+	sharpe := (mean(returns) - r.RiskFreeRate/float64(r.periodsPerYear())) / volatility
+	variance := (1 - skewness*sharpe + (kurtosis-1)/4*sharpe*sharpe) / (n - 1)
+	if variance <= 0 {
+		return
+	}
+
+	r.Metrics.ProbabilisticSharpe = normalCDF(sharpe/math.Sqrt(variance)) * 100
+
+	// Deflate against the maximum Sharpe ratio expected from skill-less trials, whose
+	// Sharpe ratios vary by the trial variance or, without one, by estimation error alone
+	trials := float64(max(r.SharpeTrials, 1))
+	r.Metrics.SharpeTrials = int(trials)
+	trialVariance := r.SharpeTrialVariance
+	if trialVariance <= 0 {
+		trialVariance = variance
+	}
+	expectedMax := 0.0
+	if trials > 1 {
+		expectedMax = math.Sqrt(trialVariance) * ((1-eulerMascheroni)*normalQuantile(1-1/trials) +
+			eulerMascheroni*normalQuantile(1-1/(trials*math.E)))
+	}
+	r.Metrics.DeflatedSharpe = normalCDF((sharpe-expectedMax)/math.Sqrt(variance)) * 100
+
+	if sharpe > 0 {
+		z := normalQuantile(MinTrackRecordConfidence)
+		r.Metrics.MinTrackRecordLength = 1 + (1-skewness*sharpe+(kurtosis-1)/4*sharpe*sharpe)*(z/sharpe)*(z/sharpe)
+	}
+}
+
+// moments returns the skewness and (non-excess) kurtosis of values
+func moments(values []float64) (float64, float64) {
+	m := mean(values)
+	var m2, m3, m4 float64
+	for _, v := range values {
+		d := v - m
+		m2 += d * d
+		m3 += d * d * d
+		m4 += d * d * d * d
+	}
+	n := float64(len(values))
+	m2, m3, m4 = m2/n, m3/n, m4/n
+	if m2 == 0 {
+		return 0, 0
+	}
+	return m3 / math.Pow(m2, 1.5), m4 / (m2 * m2)
+}
+
+// normalCDF is the standard normal cumulative distribution function
+func normalCDF(x float64) float64 {
+	return 0.5 * math.Erfc(-x/math.Sqrt2)
+}
+
+// normalQuantile is the inverse of the standard normal cumulative distribution function
+func normalQuantile(p float64) float64 {
+	return math.Sqrt2 * math.Erfinv(2*p-1)
+}
@@ -0,0 +1,107 @@
+package backtester
+
+import (
+	"math"
+	"testing"
+)
+
+// significanceReturns are 20 daily returns with a per-period Sharpe ratio of about 0.33
+var significanceReturns = []float64{
+	0.012, -0.004, 0.007, 0.015, -0.011, 0.003, 0.009, -0.002, 0.021, -0.008,
+	0.004, 0.006, -0.015, 0.011, 0.002, 0.008, -0.003, 0.013, 0.001, -0.006,
+}
+
+// significanceResults returns results for significanceReturns at a 2.52% risk-free rate
+func significanceResults(trials int, trialVariance float64) *Results {
+	return &Results{
+		InitialCapital:      100,
+		RiskFreeRate:        0.0252,
+		SharpeTrials:        trials,
+		SharpeTrialVariance: trialVariance,
+		EquityCurve:         dailyCurve(100, significanceReturns),
+		Metrics:             &PerformanceMetrics{},
+	}
+}
+
+// Expected values were computed independently with Python's statistics.NormalDist
+func TestSignificanceMatchesReference(t *testing.T) {
+	r := significanceResults(1, 0)
+	r.calculateSignificance()
+
+	m := r.Metrics
+	assertClose(t, "skewness", m.Skewness, -0.12371762093797774)
+	assertClose(t, "excess kurtosis", m.Kurtosis, -0.6220777970965807)
+	assertClose(t, "probabilistic sharpe", m.ProbabilisticSharpe, 91.61715499665829)
+	// A single trial is not deflated
+	assertClose(t, "deflated sharpe", m.DeflatedSharpe, m.ProbabilisticSharpe)
+	if m.SharpeTrials != 1 {
+		t.Errorf("sharpe trials = %d, want 1", m.SharpeTrials)
+	}
+	assertClose(t, "min track record length", m.MinTrackRecordLength, 28.001851785112237)
+}
+
+func TestDeflatedSharpe(t *testing.T) {
+	tests := []struct {
+		name          string
+		trialVariance float64
+		want          float64
+	}{
+		// Without a trial variance, trials vary by this run's estimate variance of 0.0567
+		{"estimate variance", 0, 42.276441002544296},
+		{"trial variance", 0.04, 52.29577087185206},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := significanceResults(10, tt.trialVariance)
+			r.calculateSignificance()
+			if r.Metrics.SharpeTrials != 10 {
+				t.Errorf("sharpe trials = %d, want 10", r.Metrics.SharpeTrials)
+			}
+			assertClose(t, "deflated sharpe", r.Metrics.DeflatedSharpe, tt.want)
+		})
+	}
+}
+
+func TestMinTrackRecordLengthGivesConfidence(t *testing.T) {
+	r := significanceResults(1, 0)
+	r.calculateSignificance()
+
+	// Over the minimum track record length, the Sharpe estimate's z-score reaches the
+	// 95% quantile
+	skewness, kurtosis := moments(significanceReturns)
+	sharpe := (mean(significanceReturns) - 0.0252/252) / stdDev(significanceReturns)
+	variance := (1 - skewness*sharpe + (kurtosis-1)/4*sharpe*sharpe) / (r.Metrics.MinTrackRecordLength - 1)
+	assertClose(t, "confidence", normalCDF(sharpe/math.Sqrt(variance)), MinTrackRecordConfidence)
+}
+
+func TestSignificanceNeedsVariedReturns(t *testing.T) {
+	for name, returns := range map[string][]float64{
+		"two returns":      {0.01, 0.02},
+		"constant returns": {0.01, 0.01, 0.01, 0.01},
+	} {
+		r := &Results{InitialCapital: 100, EquityCurve: dailyCurve(100, returns), Metrics: &PerformanceMetrics{}}
+		r.calculateSignificance()
+		m := r.Metrics
+		if m.Skewness != 0 || m.ProbabilisticSharpe != 0 || m.DeflatedSharpe != 0 || m.MinTrackRecordLength != 0 {
+			t.Errorf("%s: significance metrics = %+v, want none", name, m)
+		}
+	}
+
+	// A losing run has no track record length that proves a positive Sharpe ratio
+	losing := make([]float64, len(significanceReturns))
+	for i, ret := range significanceReturns {
+		losing[i] = -ret
+	}
+	r := &Results{InitialCapital: 100, EquityCurve: dailyCurve(100, losing), Metrics: &PerformanceMetrics{}}
+	r.calculateSignificance()
+	if r.Metrics.MinTrackRecordLength != 0 || r.Metrics.ProbabilisticSharpe >= 50 {
+		t.Errorf("losing run: min track record length %v and probabilistic sharpe %v, want 0 and below 50", r.Metrics.MinTrackRecordLength, r.Metrics.ProbabilisticSharpe)
+	}
+}
+
+func TestNormalQuantileInvertsCDF(t *testing.T) {
+	assertClose(t, "95% quantile", normalQuantile(0.95), 1.6448536269514722)
+	for _, p := range []float64{0.01, 0.3, 0.5, 0.9, 0.999} {
+		assertClose(t, "cdf of quantile", normalCDF(normalQuantile(p)), p)
+	}
+}
@@ -0,0 +1,340 @@
+package feed
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ridopark/JonBuhTrader/pkg/logging"
+	"github.com/ridopark/JonBuhTrader/pkg/strategy"
+	"github.com/rs/zerolog"
+)
+
+// RollMethod defines when a continuous contract rolls to the next contract
+type RollMethod string
+
+const (
+	// RollByCalendar rolls a fixed number of days before the front contract expires
+	RollByCalendar RollMethod = "calendar"
+
+	// RollByVolume rolls once the next contract trades more volume than the front contract
+	RollByVolume RollMethod = "volume"
+)
+
+// AdjustmentMethod defines how prices before a roll are adjusted to remove the roll gap
+type AdjustmentMethod string
+
+const (
+	// AdjustNone leaves historical prices untouched (gaps remain at each roll)
+	AdjustNone AdjustmentMethod = "none"
+
+	// AdjustDifference back-adjusts prior bars by adding the price difference at each roll
+	AdjustDifference AdjustmentMethod = "difference"
+
+	// AdjustRatio back-adjusts prior bars by multiplying by the price ratio at each roll
+	AdjustRatio AdjustmentMethod = "ratio"
+)
+
+// ContinuousContractConfig configures how a continuous futures series is built
+type ContinuousContractConfig struct {
+	Root                 string           // Symbol of the continuous series (e.g., "ES")
+	RollMethod           RollMethod       // When to roll to the next contract
+	RollDaysBeforeExpiry int              // Calendar roll: days before expiration to roll
+	Adjustment           AdjustmentMethod // Back-adjustment applied to bars before each roll
+}
+
+// DefaultContinuousContractConfig returns a calendar-rolled, difference-adjusted configuration
+func DefaultContinuousContractConfig(root string) ContinuousContractConfig {
+	return ContinuousContractConfig{
+		Root:                 root,
+		RollMethod:           RollByCalendar,
+		RollDaysBeforeExpiry: 5,
+		Adjustment:           AdjustDifference,
+	}
+}
+
+// RollEvent records a roll from one contract to the next
+type RollEvent struct {
+	Timestamp  time.Time
+	FromSymbol string
+	ToSymbol   string
+	FromPrice  float64 // Close of the expiring contract at the roll
+	ToPrice    float64 // Close of the new contract at the roll
+}
+
+// BuildContinuousContract stitches bars from consecutive futures contracts into a
+// single series named after the root symbol. Contracts must have expirations set.
+func BuildContinuousContract(config ContinuousContractConfig, contracts []strategy.Instrument, bars map[string][]strategy.BarData) ([]strategy.BarData, []RollEvent, error) {
+	if len(contracts) == 0 {
+		return nil, nil, fmt.Errorf("no contracts provided for continuous contract %s", config.Root)
+	}
+
+	sorted := make([]strategy.Instrument, len(contracts))
+	copy(sorted, contracts)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Expiration.Before(sorted[j].Expiration)
+	})
+	for _, contract := range sorted {
+		if contract.Expiration.IsZero() {
+			return nil, nil, fmt.Errorf("contract %s has no expiration", contract.Symbol)
+		}
+	}
+
+	// Index bars by timestamp per contract and collect the union of timestamps
+	barIndex := make([]map[int64]strategy.BarData, len(sorted))
+	timestampSet := make(map[int64]time.Time)
+	for i, contract := range sorted {
+		barIndex[i] = make(map[int64]strategy.BarData)
+		for _, bar := range bars[contract.Symbol] {
+			key := bar.Timestamp.UnixNano()
+			barIndex[i][key] = bar
+			timestampSet[key] = bar.Timestamp
+		}
+	}
+
+	timestamps := make([]time.Time, 0, len(timestampSet))
+	for _, timestamp := range timestampSet {
+		timestamps = append(timestamps, timestamp)
+	}
+	sort.Slice(timestamps, func(i, j int) bool {
+		return timestamps[i].Before(timestamps[j])
+	})
+
+	var series []strategy.BarData
+	var rolls []RollEvent
+	var rollIndexes []int // index in series of the first bar after each roll
+	active := 0
+
+	for _, timestamp := range timestamps {
+		key := timestamp.UnixNano()
+
+		// Roll forward while the active contract is due to roll and the next one has a price
+		for active < len(sorted)-1 {
+			current, hasCurrent := barIndex[active][key]
+			next, hasNext := barIndex[active+1][key]
+			expired := sorted[active].IsExpired(timestamp)
+
+			if !expired && !shouldRoll(config, sorted[active], timestamp, current, hasCurrent, next, hasNext) {
+				break
+			}
+			if !hasNext && !expired {
+				break // Wait until the next contract trades so the roll gap can be measured
+			}
+
+			event := RollEvent{
+				Timestamp:  timestamp,
+				FromSymbol: sorted[active].Symbol,
+				ToSymbol:   sorted[active+1].Symbol,
+				FromPrice:  current.Close,
+				ToPrice:    next.Close,
+			}
+			if !hasCurrent && len(series) > 0 {
+				event.FromPrice = series[len(series)-1].Close
+			}
+			if !hasNext {
+				event.ToPrice = event.FromPrice
+			}
+			active++
+
+			// Without a price before the roll no bars came from the expiring contract,
+			// so there is no gap to measure or history to adjust
+			if !hasCurrent && len(series) == 0 {
+				continue
+			}
+			rolls = append(rolls, event)
+			rollIndexes = append(rollIndexes, len(series))
+		}
+
+		bar, exists := barIndex[active][key]
+		if !exists {
+			continue
+		}
+		bar.Symbol = config.Root
+		series = append(series, bar)
+	}
+
+	applyBackAdjustment(config.Adjustment, series, rolls, rollIndexes)
+
+	return series, rolls, nil
+}
+
+// shouldRoll decides whether the active contract should roll at the given bar
+func shouldRoll(config ContinuousContractConfig, contract strategy.Instrument, timestamp time.Time, current strategy.BarData, hasCurrent bool, next strategy.BarData, hasNext bool) bool {
+	switch config.RollMethod {
+	case RollByVolume:
+		if !hasNext {
+			return false
+		}
+		return !hasCurrent || next.Volume > current.Volume
+	default:
+		rollDate := contract.Expiration.AddDate(0, 0, -config.RollDaysBeforeExpiry)
+		return !timestamp.Before(rollDate)
+	}
+}
+
+// applyBackAdjustment removes roll gaps by adjusting every bar before each roll
+func applyBackAdjustment(method AdjustmentMethod, series []strategy.BarData, rolls []RollEvent, rollIndexes []int) {
+	if method == AdjustNone || method == "" {
+		return
+	}
+
+	// Walk rolls from newest to oldest so adjustments accumulate backwards
+	difference := 0.0
+	ratio := 1.0
+	next := len(series)
+	for r := len(rolls) - 1; r >= -1; r-- {
+		start := 0
+		if r >= 0 {
+			start = rollIndexes[r]
+		}
+
+		for i := start; i < next; i++ {
+			bar := &series[i]
+			switch method {
+			case AdjustDifference:
+				bar.Open += difference
+				bar.High += difference
+				bar.Low += difference
+				bar.Close += difference
+			case AdjustRatio:
+				bar.Open *= ratio
+				bar.High *= ratio
+				bar.Low *= ratio
+				bar.Close *= ratio
+			}
+		}
+		next = start
+
+		// Rolls without prices on both sides have no measurable gap
+		if r >= 0 && rolls[r].FromPrice > 0 && rolls[r].ToPrice > 0 {
+			difference += rolls[r].ToPrice - rolls[r].FromPrice
+			ratio *= rolls[r].ToPrice / rolls[r].FromPrice
+		}
+	}
+}
+
+// ContinuousContractProvider wraps a historical data provider and serves
+// continuous futures series for configured root symbols
+type ContinuousContractProvider struct {
+	provider  HistoricalDataProvider
+	contracts FuturesContractProvider
+	configs   map[string]ContinuousContractConfig
+	rolls     map[string][]RollEvent
+	logger    zerolog.Logger
+}
+
+// NewContinuousContractProvider creates a provider that stitches futures contracts into continuous series
+func NewContinuousContractProvider(provider HistoricalDataProvider, contracts FuturesContractProvider) *ContinuousContractProvider {
+	return &ContinuousContractProvider{
+		provider:  provider,
+		contracts: contracts,
+		configs:   make(map[string]ContinuousContractConfig),
+		rolls:     make(map[string][]RollEvent),
+		logger:    logging.GetLogger("continuous-feed"),
+	}
+}
+
+// AddContinuousContract registers a root symbol to be served as a continuous series
+func (p *ContinuousContractProvider) AddContinuousContract(config ContinuousContractConfig) {
+	p.configs[config.Root] = config
+}
+
+// GetRollEvents returns the rolls applied the last time a root symbol was loaded
+func (p *ContinuousContractProvider) GetRollEvents(root string) []RollEvent {
+	return p.rolls[root]
+}
+
+// GetBars retrieves historical OHLCV data, stitching contracts for continuous symbols
+func (p *ContinuousContractProvider) GetBars(symbol string, timeframe string, start time.Time, end time.Time) ([]strategy.BarData, error) {
+	config, exists := p.configs[symbol]
+	if !exists {
+		return p.provider.GetBars(symbol, timeframe, start, end)
+	}
+
+	return p.buildSeries(config, func(contract strategy.Instrument) ([]strategy.BarData, error) {
+		// Skip contracts that expired before the requested window
+		if contract.Expiration.Before(start) {
+			return nil, nil
+		}
+		return p.provider.GetBars(contract.Symbol, timeframe, start, end)
+	})
+}
+
+// GetLastBar gets the most recent bar for a symbol
+func (p *ContinuousContractProvider) GetLastBar(symbol string, timeframe string) (*strategy.BarData, error) {
+	if _, exists := p.configs[symbol]; !exists {
+		return p.provider.GetLastBar(symbol, timeframe)
+	}
+
+	bars, err := p.GetBarsLimit(symbol, timeframe, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(bars) == 0 {
+		return nil, fmt.Errorf("no data found for symbol %s timeframe %s", symbol, timeframe)
+	}
+	return &bars[len(bars)-1], nil
+}
+
+// GetBarsLimit gets the last N bars for a symbol
+func (p *ContinuousContractProvider) GetBarsLimit(symbol string, timeframe string, limit int) ([]strategy.BarData, error) {
+	config, exists := p.configs[symbol]
+	if !exists {
+		return p.provider.GetBarsLimit(symbol, timeframe, limit)
+	}
+
+	series, err := p.buildSeries(config, func(contract strategy.Instrument) ([]strategy.BarData, error) {
+		return p.provider.GetBarsLimit(contract.Symbol, timeframe, limit)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(series) > limit {
+		series = series[len(series)-limit:]
+	}
+	return series, nil
+}
+
+// buildSeries loads bars for every contract of a root symbol and stitches them together
+func (p *ContinuousContractProvider) buildSeries(config ContinuousContractConfig, load func(strategy.Instrument) ([]strategy.BarData, error)) ([]strategy.BarData, error) {
+	contracts, err := p.contracts.GetFuturesContracts(config.Root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load contracts for %s: %w", config.Root, err)
+	}
+
+	bars := make(map[string][]strategy.BarData)
+	var loaded []strategy.Instrument
+	for _, contract := range contracts {
+		contractBars, err := load(contract)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load data for contract %s: %w", contract.Symbol, err)
+		}
+		if len(contractBars) == 0 {
+			continue
+		}
+		bars[contract.Symbol] = contractBars
+		loaded = append(loaded, contract)
+	}
+
+	series, rolls, err := BuildContinuousContract(config, loaded, bars)
+	if err != nil {
+		return nil, err
+	}
+	p.rolls[config.Root] = rolls
+
+	for _, roll := range rolls {
+		p.logger.Info().
+			Str("root", config.Root).
+			Time("timestamp", roll.Timestamp).
+			Str("from", roll.FromSymbol).
+			Str("to", roll.ToSymbol).
+			Float64("gap", roll.ToPrice-roll.FromPrice).
+			Msg("Continuous contract rolled")
+	}
+
+	return series, nil
+}
+
+// Verify that ContinuousContractProvider implements the HistoricalDataProvider interface
+var _ HistoricalDataProvider = (*ContinuousContractProvider)(nil)
@@ -0,0 +1,171 @@
+package feed
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/ridopark/JonBuhTrader/pkg/strategy"
+)
+
+var rollStart = time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+// contract returns a futures contract expiring the given number of days after rollStart
+func contract(symbol string, expiresInDays int) strategy.Instrument {
+	return strategy.Instrument{
+		Symbol:     symbol,
+		AssetType:  strategy.AssetTypeFuture,
+		Expiration: rollStart.AddDate(0, 0, expiresInDays),
+	}
+}
+
+// dailyBars returns one bar per day starting at rollStart plus the given offset
+func dailyBars(symbol string, offset int, prices []float64, volumes []float64) []strategy.BarData {
+	bars := make([]strategy.BarData, len(prices))
+	for i, price := range prices {
+		bars[i] = strategy.BarData{
+			Symbol:    symbol,
+			Timestamp: rollStart.AddDate(0, 0, offset+i),
+			Open:      price,
+			High:      price + 1,
+			Low:       price - 1,
+			Close:     price,
+		}
+		if volumes != nil {
+			bars[i].Volume = volumes[i]
+		}
+	}
+	return bars
+}
+
+func closes(series []strategy.BarData) []float64 {
+	values := make([]float64, len(series))
+	for i, bar := range series {
+		values[i] = bar.Close
+	}
+	return values
+}
+
+func assertCloses(t *testing.T, series []strategy.BarData, want []float64) {
+	t.Helper()
+	got := closes(series)
+	if len(got) != len(want) {
+		t.Fatalf("closes = %v, want %v", got, want)
+	}
+	for i := range want {
+		if math.Abs(got[i]-want[i]) > 1e-9 {
+			t.Fatalf("closes = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestBuildContinuousContractBackAdjusts(t *testing.T) {
+	// H expires on day 5 and rolls 2 days earlier, on day 3, into M trading 10 higher
+	contracts := []strategy.Instrument{contract("ESM4", 30), contract("ESH4", 5)}
+	bars := map[string][]strategy.BarData{
+		"ESH4": dailyBars("ESH4", 0, []float64{100, 101, 102, 104, 105}, nil),
+		"ESM4": dailyBars("ESM4", 0, []float64{110, 111, 112, 114, 116, 118}, nil),
+	}
+
+	tests := []struct {
+		name       string
+		adjustment AdjustmentMethod
+		want       []float64
+	}{
+		{"none", AdjustNone, []float64{100, 101, 102, 114, 116, 118}},
+		{"difference", AdjustDifference, []float64{110, 111, 112, 114, 116, 118}},
+		{"ratio", AdjustRatio, []float64{100 * 114.0 / 104, 101 * 114.0 / 104, 102 * 114.0 / 104, 114, 116, 118}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := ContinuousContractConfig{Root: "ES", RollMethod: RollByCalendar, RollDaysBeforeExpiry: 2, Adjustment: tt.adjustment}
+			series, rolls, err := BuildContinuousContract(config, contracts, bars)
+			if err != nil {
+				t.Fatalf("BuildContinuousContract: %v", err)
+			}
+			if len(rolls) != 1 {
+				t.Fatalf("rolls = %+v, want one roll", rolls)
+			}
+			roll := rolls[0]
+			if roll.FromSymbol != "ESH4" || roll.ToSymbol != "ESM4" || roll.FromPrice != 104 || roll.ToPrice != 114 || !roll.Timestamp.Equal(rollStart.AddDate(0, 0, 3)) {
+				t.Errorf("roll = %+v, want ESH4 104 -> ESM4 114 on day 3", roll)
+			}
+			for _, bar := range series {
+				if bar.Symbol != "ES" {
+					t.Fatalf("bar symbol = %q, want ES", bar.Symbol)
+				}
+			}
+			assertCloses(t, series, tt.want)
+			if tt.adjustment == AdjustDifference && series[0].High != 111 {
+				t.Errorf("adjusted high = %v, want 111", series[0].High)
+			}
+		})
+	}
+}
+
+func TestBuildContinuousContractRollsByVolume(t *testing.T) {
+	contracts := []strategy.Instrument{contract("ESH4", 30), contract("ESM4", 120)}
+	bars := map[string][]strategy.BarData{
+		"ESH4": dailyBars("ESH4", 0, []float64{100, 101, 102, 103}, []float64{900, 800, 400, 300}),
+		"ESM4": dailyBars("ESM4", 0, []float64{105, 106, 108, 109}, []float64{100, 500, 600, 900}),
+	}
+	config := ContinuousContractConfig{Root: "ES", RollMethod: RollByVolume, Adjustment: AdjustDifference}
+
+	series, rolls, err := BuildContinuousContract(config, contracts, bars)
+	if err != nil {
+		t.Fatalf("BuildContinuousContract: %v", err)
+	}
+	// M outtrades H on day 2, where the gap is 108 - 102
+	if len(rolls) != 1 || !rolls[0].Timestamp.Equal(rollStart.AddDate(0, 0, 2)) {
+		t.Fatalf("rolls = %+v, want one roll on day 2", rolls)
+	}
+	assertCloses(t, series, []float64{106, 107, 108, 109})
+}
+
+func TestBuildContinuousContractWaitsForNextContract(t *testing.T) {
+	// M only starts trading on day 4, after the calendar roll date
+	contracts := []strategy.Instrument{contract("ESH4", 5), contract("ESM4", 30)}
+	bars := map[string][]strategy.BarData{
+		"ESH4": dailyBars("ESH4", 0, []float64{100, 101, 102, 103, 104}, nil),
+		"ESM4": dailyBars("ESM4", 4, []float64{110, 111}, nil),
+	}
+	config := ContinuousContractConfig{Root: "ES", RollMethod: RollByCalendar, RollDaysBeforeExpiry: 3, Adjustment: AdjustDifference}
+
+	series, rolls, err := BuildContinuousContract(config, contracts, bars)
+	if err != nil {
+		t.Fatalf("BuildContinuousContract: %v", err)
+	}
+	if len(rolls) != 1 || rolls[0].FromPrice != 104 || rolls[0].ToPrice != 110 {
+		t.Fatalf("rolls = %+v, want 104 -> 110 on day 4", rolls)
+	}
+	assertCloses(t, series, []float64{106, 107, 108, 109, 110, 111})
+}
+
+func TestBuildContinuousContractSkipsRollWithoutFromPrice(t *testing.T) {
+	// H has no bars at all, so the series starts on M with nothing to adjust
+	contracts := []strategy.Instrument{contract("ESH4", 5), contract("ESM4", 30)}
+	bars := map[string][]strategy.BarData{
+		"ESM4": dailyBars("ESM4", 0, []float64{110, 111, 112, 113, 114, 115}, nil),
+	}
+	config := ContinuousContractConfig{Root: "ES", RollMethod: RollByCalendar, RollDaysBeforeExpiry: 2, Adjustment: AdjustDifference}
+
+	series, rolls, err := BuildContinuousContract(config, contracts, bars)
+	if err != nil {
+		t.Fatalf("BuildContinuousContract: %v", err)
+	}
+	if len(rolls) != 0 {
+		t.Errorf("rolls = %+v, want none without a price before the roll", rolls)
+	}
+	assertCloses(t, series, []float64{113, 114, 115})
+}
+
+func TestBuildContinuousContractRequiresExpirations(t *testing.T) {
+	config := DefaultContinuousContractConfig("ES")
+	if _, _, err := BuildContinuousContract(config, nil, nil); err == nil {
+		t.Error("expected an error without contracts")
+	}
+	contracts := []strategy.Instrument{{Symbol: "ESH4", AssetType: strategy.AssetTypeFuture}}
+	if _, _, err := BuildContinuousContract(config, contracts, nil); err == nil {
+		t.Error("expected an error for a contract without an expiration")
+	}
+}
@@ -41,3 +41,29 @@ type HistoricalDataProvider interface {
 	// GetBarsLimit gets the last N bars for a symbol
 	GetBarsLimit(symbol string, timeframe string, limit int) ([]strategy.BarData, error)
 }
+
+// FXRate represents the price of one unit of Base currency expressed in Quote currency
+type FXRate struct {
+	Base      string
+	Quote     string
+	Timestamp time.Time
+	Rate      float64
+}
+
+// InstrumentProvider defines the interface for instrument reference data sources
+type InstrumentProvider interface {
+	// GetInstrument retrieves reference data for a symbol
+	GetInstrument(symbol string) (*strategy.Instrument, error)
+}
+
+// FuturesContractProvider defines the interface for futures contract reference data sources
+type FuturesContractProvider interface {
+	// GetFuturesContracts retrieves all contracts for a root symbol ordered by expiration
+	GetFuturesContracts(root string) ([]strategy.Instrument, error)
+}
+
+// FXRateProvider defines the interface for historical FX rate sources
+type FXRateProvider interface {
+	// GetFXRates retrieves the rate series for base/quote between start and end, oldest first
+	GetFXRates(base string, quote string, start time.Time, end time.Time) ([]FXRate, error)
+}
@@ -0,0 +1,122 @@
+package indicators
+
+import "github.com/ridopark/JonBuhTrader/pkg/strategy"
+
+// SMA is a simple moving average
+type SMA struct {
+	period int
+	window *Window
+	sum    float64
+}
+
+// NewSMA creates a simple moving average over period values
+func NewSMA(period int) *SMA {
+	period = validPeriod(period)
+	return &SMA{period: period, window: NewWindow(period)}
+}
+
+// Add feeds the next value
+func (s *SMA) Add(value float64) {
+	evicted, full := s.window.Add(value)
+	s.sum += value
+	if full {
+		s.sum -= evicted
+	}
+}
+
+// Update feeds the bar's close
+func (s *SMA) Update(bar strategy.BarData) {
+	s.Add(bar.Close)
+}
+
+// Value returns the average of the last period values
+func (s *SMA) Value() float64 {
+	if !s.Ready() {
+		return 0
+	}
+	return s.sum / float64(s.period)
+}
+
+// Ready returns true once period values have been added
+func (s *SMA) Ready() bool {
+	return s.window.Full()
+}
+
+// WarmupPeriod returns the number of values needed before the average is ready
+func (s *SMA) WarmupPeriod() int {
+	return s.period
+}
+
+// Reset clears all state
+func (s *SMA) Reset() {
+	s.window.Reset()
+	s.sum = 0
+}
+
+// EMA is an exponential moving average seeded with the simple average of the first period values
+type EMA struct {
+	period int
+	alpha  float64
+	count  int
+	sum    float64
+	value  float64
+}
+
+// NewEMA creates an exponential moving average with smoothing factor 2/(period+1)
+func NewEMA(period int) *EMA {
+	period = validPeriod(period)
+	return &EMA{period: period, alpha: 2.0 / (float64(period) + 1.0)}
+}
+
+// NewWilderMA creates Wilder's moving average, an EMA with smoothing factor 1/period
+func NewWilderMA(period int) *EMA {
+	period = validPeriod(period)
+	return &EMA{period: period, alpha: 1.0 / float64(period)}
+}
+
+// Add feeds the next value
+func (e *EMA) Add(value float64) {
+	e.count++
+	if e.count < e.period {
+		e.sum += value
+		return
+	}
+	if e.count == e.period {
+		e.value = (e.sum + value) / float64(e.period)
+		return
+	}
+	e.value += e.alpha * (value - e.value)
+}
+
+// Update feeds the bar's close
+func (e *EMA) Update(bar strategy.BarData) {
+	e.Add(bar.Close)
+}
+
+// Value returns the current average
+func (e *EMA) Value() float64 {
+	return e.value
+}
+
+// Ready returns true once period values have been added
+func (e *EMA) Ready() bool {
+	return e.count >= e.period
+}
+
+// WarmupPeriod returns the number of values needed before the average is ready
+func (e *EMA) WarmupPeriod() int {
+	return e.period
+}
+
+// Reset clears all state
+func (e *EMA) Reset() {
+	e.count = 0
+	e.sum = 0
+	e.value = 0
+}
+
+// Verify that the moving averages implement the SeriesIndicator interface
+var (
+	_ SeriesIndicator = (*SMA)(nil)
+	_ SeriesIndicator = (*EMA)(nil)
+)
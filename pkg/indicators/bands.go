@@ -0,0 +1,323 @@
+package indicators
+
+import (
+	"math"
+
+	"github.com/ridopark/JonBuhTrader/pkg/strategy"
+)
+
+// StdDev is the rolling population standard deviation of a series. It keeps a
+// running mean and sum of squared deviations (Welford's method) rather than raw
+// sums of squares, which lose precision when the mean is large relative to the spread.
+type StdDev struct {
+	period int
+	window *Window
+	mean   float64
+	m2     float64 // Sum of squared deviations from the mean
+}
+
+// NewStdDev creates a rolling standard deviation over period values
+func NewStdDev(period int) *StdDev {
+	period = validPeriod(period)
+	return &StdDev{period: period, window: NewWindow(period)}
+}
+
+// Add feeds the next value
+func (s *StdDev) Add(value float64) {
+	evicted, full := s.window.Add(value)
+	if !full {
+		delta := value - s.mean
+		s.mean += delta / float64(s.window.Len())
+		s.m2 += delta * (value - s.mean)
+		return
+	}
+
+	// Replace the evicted value in a window of constant size
+	previousMean := s.mean
+	delta := value - evicted
+	s.mean += delta / float64(s.period)
+	s.m2 = math.Max(0, s.m2+delta*(value-s.mean+evicted-previousMean))
+}
+
+// Update feeds the bar's close
+func (s *StdDev) Update(bar strategy.BarData) {
+	s.Add(bar.Close)
+}
+
+// Mean returns the average of the values in the window
+func (s *StdDev) Mean() float64 {
+	if !s.Ready() {
+		return 0
+	}
+	return s.mean
+}
+
+// Value returns the standard deviation of the values in the window
+func (s *StdDev) Value() float64 {
+	if !s.Ready() {
+		return 0
+	}
+	return math.Sqrt(s.m2 / float64(s.period))
+}
+
+// Ready returns true once period values have been added
+func (s *StdDev) Ready() bool {
+	return s.window.Full()
+}
+
+// WarmupPeriod returns the number of values needed before the deviation is ready
+func (s *StdDev) WarmupPeriod() int {
+	return s.period
+}
+
+// Reset clears all state
+func (s *StdDev) Reset() {
+	s.window.Reset()
+	s.mean = 0
+	s.m2 = 0
+}
+
+// ZScore is the number of standard deviations the latest value sits from its rolling mean
+type ZScore struct {
+	stdDev *StdDev
+	last   float64
+}
+
+// NewZScore creates a rolling z-score over period values
+func NewZScore(period int) *ZScore {
+	return &ZScore{stdDev: NewStdDev(period)}
+}
+
+// Add feeds the next value
+func (z *ZScore) Add(value float64) {
+	z.stdDev.Add(value)
+	z.last = value
+}
+
+// Update feeds the bar's close
+func (z *ZScore) Update(bar strategy.BarData) {
+	z.Add(bar.Close)
+}
+
+// Value returns the z-score of the latest value (0 if the window has no variance)
+func (z *ZScore) Value() float64 {
+	deviation := z.stdDev.Value()
+	if deviation == 0 {
+		return 0
+	}
+	return (z.last - z.stdDev.Mean()) / deviation
+}
+
+// Ready returns true once period values have been added
+func (z *ZScore) Ready() bool {
+	return z.stdDev.Ready()
+}
+
+// WarmupPeriod returns the number of values needed before the z-score is ready
+func (z *ZScore) WarmupPeriod() int {
+	return z.stdDev.WarmupPeriod()
+}
+
+// Reset clears all state
+func (z *ZScore) Reset() {
+	z.stdDev.Reset()
+	z.last = 0
+}
+
+// BollingerBands are bands placed a number of standard deviations around a simple
+// moving average. Value returns the middle band.
+type BollingerBands struct {
+	multiplier float64
+	stdDev     *StdDev
+	last       float64
+}
+
+// NewBollingerBands creates Bollinger Bands over period values, multiplier standard deviations wide
+func NewBollingerBands(period int, multiplier float64) *BollingerBands {
+	return &BollingerBands{multiplier: multiplier, stdDev: NewStdDev(period)}
+}
+
+// Add feeds the next value
+func (b *BollingerBands) Add(value float64) {
+	b.stdDev.Add(value)
+	b.last = value
+}
+
+// Update feeds the bar's close
+func (b *BollingerBands) Update(bar strategy.BarData) {
+	b.Add(bar.Close)
+}
+
+// Value returns the middle band
+func (b *BollingerBands) Value() float64 {
+	return b.stdDev.Mean()
+}
+
+// Upper returns the upper band
+func (b *BollingerBands) Upper() float64 {
+	return b.Value() + b.multiplier*b.stdDev.Value()
+}
+
+// Lower returns the lower band
+func (b *BollingerBands) Lower() float64 {
+	return b.Value() - b.multiplier*b.stdDev.Value()
+}
+
+// PercentB returns where the last value sits relative to the bands (0 = lower, 1 = upper)
+func (b *BollingerBands) PercentB() float64 {
+	width := b.Upper() - b.Lower()
+	if width == 0 {
+		return 0.5
+	}
+	return (b.last - b.Lower()) / width
+}
+
+// Bandwidth returns the distance between the bands relative to the middle band
+func (b *BollingerBands) Bandwidth() float64 {
+	middle := b.Value()
+	if middle == 0 {
+		return 0
+	}
+	return (b.Upper() - b.Lower()) / middle
+}
+
+// Ready returns true once period values have been added
+func (b *BollingerBands) Ready() bool {
+	return b.stdDev.Ready()
+}
+
+// WarmupPeriod returns the number of values needed before the bands are ready
+func (b *BollingerBands) WarmupPeriod() int {
+	return b.stdDev.WarmupPeriod()
+}
+
+// Reset clears all state
+func (b *BollingerBands) Reset() {
+	b.stdDev.Reset()
+	b.last = 0
+}
+
+// KeltnerChannels are bands placed a multiple of the ATR around an EMA of the
+// close. Value returns the middle line.
+type KeltnerChannels struct {
+	multiplier float64
+	ema        *EMA
+	atr        *ATR
+}
+
+// NewKeltnerChannels creates Keltner Channels from an EMA over emaPeriod bars
+// and bands multiplier ATRs over atrPeriod bars wide
+func NewKeltnerChannels(emaPeriod, atrPeriod int, multiplier float64) *KeltnerChannels {
+	return &KeltnerChannels{
+		multiplier: multiplier,
+		ema:        NewEMA(emaPeriod),
+		atr:        NewATR(atrPeriod),
+	}
+}
+
+// Update feeds the next bar
+func (k *KeltnerChannels) Update(bar strategy.BarData) {
+	k.ema.Add(bar.Close)
+	k.atr.Update(bar)
+}
+
+// Value returns the middle line
+func (k *KeltnerChannels) Value() float64 {
+	if !k.Ready() {
+		return 0
+	}
+	return k.ema.Value()
+}
+
+// Upper returns the upper channel
+func (k *KeltnerChannels) Upper() float64 {
+	return k.Value() + k.multiplier*k.atr.Value()
+}
+
+// Lower returns the lower channel
+func (k *KeltnerChannels) Lower() float64 {
+	return k.Value() - k.multiplier*k.atr.Value()
+}
+
+// Ready returns true once both the EMA and ATR are ready
+func (k *KeltnerChannels) Ready() bool {
+	return k.ema.Ready() && k.atr.Ready()
+}
+
+// WarmupPeriod returns the number of bars needed before the channels are ready
+func (k *KeltnerChannels) WarmupPeriod() int {
+	return max(k.ema.WarmupPeriod(), k.atr.WarmupPeriod())
+}
+
+// Reset clears all state
+func (k *KeltnerChannels) Reset() {
+	k.ema.Reset()
+	k.atr.Reset()
+}
+
+// DonchianChannels track the highest high and lowest low over a period. Value
+// returns the middle line.
+type DonchianChannels struct {
+	period  int
+	highest *rollingExtreme
+	lowest  *rollingExtreme
+}
+
+// NewDonchianChannels creates Donchian Channels over period bars
+func NewDonchianChannels(period int) *DonchianChannels {
+	period = validPeriod(period)
+	return &DonchianChannels{
+		period:  period,
+		highest: newRollingMax(period),
+		lowest:  newRollingMin(period),
+	}
+}
+
+// Update feeds the next bar
+func (d *DonchianChannels) Update(bar strategy.BarData) {
+	d.highest.Add(bar.High)
+	d.lowest.Add(bar.Low)
+}
+
+// Value returns the middle line, halfway between the channels
+func (d *DonchianChannels) Value() float64 {
+	if !d.Ready() {
+		return 0
+	}
+	return (d.Upper() + d.Lower()) / 2
+}
+
+// Upper returns the highest high over the period
+func (d *DonchianChannels) Upper() float64 {
+	return d.highest.Value()
+}
+
+// Lower returns the lowest low over the period
+func (d *DonchianChannels) Lower() float64 {
+	return d.lowest.Value()
+}
+
+// Ready returns true once period bars have been seen
+func (d *DonchianChannels) Ready() bool {
+	return d.highest.Ready()
+}
+
+// WarmupPeriod returns the number of bars needed before the channels are ready
+func (d *DonchianChannels) WarmupPeriod() int {
+	return d.period
+}
+
+// Reset clears all state
+func (d *DonchianChannels) Reset() {
+	d.highest.Reset()
+	d.lowest.Reset()
+}
+
+// Verify that the band indicators implement the indicator interfaces
+var (
+	_ SeriesIndicator = (*StdDev)(nil)
+	_ SeriesIndicator = (*ZScore)(nil)
+	_ SeriesIndicator = (*BollingerBands)(nil)
+	_ Indicator       = (*KeltnerChannels)(nil)
+	_ Indicator       = (*DonchianChannels)(nil)
+)
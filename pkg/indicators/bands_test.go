@@ -0,0 +1,80 @@
+package indicators
+
+import (
+	"math"
+	"testing"
+)
+
+func TestStdDevMatchesTALib(t *testing.T) {
+	stdDev := NewStdDev(10)
+	got := referenceTail(t, stdDev, stdDev.Value, 5)
+	assertValues(t, "StdDev(10)", got, []float64{1.798606, 2.048810, 2.243752, 2.284250, 2.220905})
+}
+
+func TestStdDevKeepsPrecisionAtLargeMagnitudes(t *testing.T) {
+	// Raw sums of squares near 1e18 cannot resolve a spread of a few units
+	stdDev := NewStdDev(4)
+	for i := 0; i < 1000; i++ {
+		stdDev.Add(1e9 + float64(i%4))
+	}
+	// The window always holds 1e9 + {0, 1, 2, 3} in some order
+	want := math.Sqrt(1.25)
+	if got := stdDev.Value(); math.Abs(got-want) > 1e-6 {
+		t.Errorf("StdDev = %.9f, want %.9f", got, want)
+	}
+	if got := stdDev.Mean(); math.Abs(got-(1e9+1.5)) > 1e-6 {
+		t.Errorf("Mean = %.6f, want %.6f", got, 1e9+1.5)
+	}
+
+	// A constant window has no deviation once the varying values are evicted
+	for i := 0; i < 4; i++ {
+		stdDev.Add(1e9)
+	}
+	if got := stdDev.Value(); got != 0 {
+		t.Errorf("StdDev of a constant window = %v, want 0", got)
+	}
+}
+
+func TestBollingerBandsMatchTALib(t *testing.T) {
+	bands := NewBollingerBands(20, 2)
+	middle := referenceTail(t, bands, bands.Value, 5)
+	assertValues(t, "middle", middle, []float64{44.676000, 44.916500, 45.215000, 45.607500, 46.049500})
+	// The outer bands are checked on the last bar
+	if got, want := bands.Upper(), 52.051361; math.Abs(got-want) > 1e-6 {
+		t.Errorf("upper = %.6f, want %.6f", got, want)
+	}
+	if got, want := bands.Lower(), 40.047639; math.Abs(got-want) > 1e-6 {
+		t.Errorf("lower = %.6f, want %.6f", got, want)
+	}
+	if got, want := bands.PercentB(), (51.73-40.047639)/(52.051361-40.047639); math.Abs(got-want) > 1e-6 {
+		t.Errorf("%%B = %.6f, want %.6f", got, want)
+	}
+	if got, want := bands.Bandwidth(), (52.051361-40.047639)/46.049500; math.Abs(got-want) > 1e-6 {
+		t.Errorf("bandwidth = %.6f, want %.6f", got, want)
+	}
+}
+
+func TestKeltnerChannelsMatchTALib(t *testing.T) {
+	// TA-Lib has no Keltner Channels; references combine its EMA(20) and ATR(10)
+	channels := NewKeltnerChannels(20, 10, 2)
+	upper := referenceTail(t, channels, channels.Upper, 5)
+	assertValues(t, "upper", upper, []float64{48.745780, 49.074072, 49.619737, 50.242544, 50.751757})
+	if got, want := channels.Value(), 47.258869; math.Abs(got-want) > 1e-6 {
+		t.Errorf("middle = %.6f, want %.6f", got, want)
+	}
+	if got, want := channels.Lower(), 43.765981; math.Abs(got-want) > 1e-6 {
+		t.Errorf("lower = %.6f, want %.6f", got, want)
+	}
+}
+
+func TestDonchianChannelsMatchTALib(t *testing.T) {
+	channels := NewDonchianChannels(20)
+	upper := referenceTail(t, channels, channels.Upper, 5)
+	assertValues(t, "upper", upper, []float64{49.39, 49.62, 51.34, 52.28, 52.59})
+	if got := channels.Lower(); got != 41.39 {
+		t.Errorf("lower = %v, want 41.39", got)
+	}
+	if got := channels.Value(); math.Abs(got-(52.59+41.39)/2) > 1e-9 {
+		t.Errorf("middle = %v, want %v", got, (52.59+41.39)/2)
+	}
+}
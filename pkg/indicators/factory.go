@@ -0,0 +1,388 @@
+package indicators
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ridopark/JonBuhTrader/pkg/strategy"
+)
+
+// constructor describes how to build an indicator from spec parameters
+type constructor struct {
+	params []string // Parameter names in order
+	build  func(p params) (Indicator, error)
+}
+
+// params wraps spec parameters with validating accessors
+type params struct {
+	name   string
+	names  []string
+	values []float64
+}
+
+// period returns the i-th parameter as a positive whole number
+func (p params) period(i int) (int, error) {
+	value := p.values[i]
+	if value < 1 || value != math.Trunc(value) {
+		return 0, fmt.Errorf("invalid %s %s %g: must be a positive whole number", p.name, p.names[i], value)
+	}
+	return int(value), nil
+}
+
+// positive returns the i-th parameter, which must be greater than zero
+func (p params) positive(i int) (float64, error) {
+	value := p.values[i]
+	if value <= 0 {
+		return 0, fmt.Errorf("invalid %s %s %g: must be positive", p.name, p.names[i], value)
+	}
+	return value, nil
+}
+
+// periods returns every parameter as a positive whole number
+func (p params) periods() ([]int, error) {
+	periods := make([]int, len(p.values))
+	for i := range p.values {
+		period, err := p.period(i)
+		if err != nil {
+			return nil, err
+		}
+		periods[i] = period
+	}
+	return periods, nil
+}
+
+// constructors maps indicator names to their constructors. Parameters follow
+// the order of the matching strategy.Context method.
+var constructors = map[string]constructor{
+	"sma":          {[]string{"period"}, periodsOnly(func(n []int) Indicator { return NewSMA(n[0]) })},
+	"ema":          {[]string{"period"}, periodsOnly(func(n []int) Indicator { return NewEMA(n[0]) })},
+	"rsi":          {[]string{"period"}, periodsOnly(func(n []int) Indicator { return NewRSI(n[0]) })},
+	"macd":         {[]string{"fast period", "slow period", "signal period"}, periodsOnly(func(n []int) Indicator { return NewMACD(n[0], n[1], n[2]) })},
+	"adx":          {[]string{"period"}, periodsOnly(func(n []int) Indicator { return NewADX(n[0]) })},
+	"atr":          {[]string{"period"}, periodsOnly(func(n []int) Indicator { return NewATR(n[0]) })},
+	"stddev":       {[]string{"period"}, periodsOnly(func(n []int) Indicator { return NewStdDev(n[0]) })},
+	"zscore":       {[]string{"period"}, periodsOnly(func(n []int) Indicator { return NewZScore(n[0]) })},
+	"donchian":     {[]string{"period"}, periodsOnly(func(n []int) Indicator { return NewDonchianChannels(n[0]) })},
+	"rolling_vwap": {[]string{"period"}, periodsOnly(func(n []int) Indicator { return NewRollingVWAP(n[0]) })},
+	"obv":          {nil, periodsOnly(func(n []int) Indicator { return NewOBV() })},
+	"mfi":          {[]string{"period"}, periodsOnly(func(n []int) Indicator { return NewMFI(n[0]) })},
+	"ad":           {nil, periodsOnly(func(n []int) Indicator { return NewAccumulationDistribution() })},
+	"cmf":          {[]string{"period"}, periodsOnly(func(n []int) Indicator { return NewChaikinMoneyFlow(n[0]) })},
+	"stochastic":   {[]string{"k period", "k smoothing", "d period"}, periodsOnly(func(n []int) Indicator { return NewStochastic(n[0], n[1], n[2]) })},
+	"stoch_rsi":    {[]string{"rsi period", "stochastic period", "k smoothing", "d period"}, periodsOnly(func(n []int) Indicator { return NewStochasticRSI(n[0], n[1], n[2], n[3]) })},
+	"williams_r":   {[]string{"period"}, periodsOnly(func(n []int) Indicator { return NewWilliamsR(n[0]) })},
+	"cci":          {[]string{"period"}, periodsOnly(func(n []int) Indicator { return NewCCI(n[0]) })},
+	"roc":          {[]string{"period"}, periodsOnly(func(n []int) Indicator { return NewROC(n[0]) })},
+	"aroon":        {[]string{"period"}, periodsOnly(func(n []int) Indicator { return NewAroon(n[0]) })},
+	"ichimoku":     {[]string{"tenkan period", "kijun period", "senkou B period", "displacement"}, periodsOnly(func(n []int) Indicator { return NewIchimoku(n[0], n[1], n[2], n[3]) })},
+	"supertrend": {[]string{"period", "multiplier"}, func(p params) (Indicator, error) {
+		period, err := p.period(0)
+		if err != nil {
+			return nil, err
+		}
+		multiplier, err := p.positive(1)
+		if err != nil {
+			return nil, err
+		}
+		return NewSuperTrend(period, multiplier), nil
+	}},
+	"psar": {[]string{"step", "max step"}, func(p params) (Indicator, error) {
+		step, err := p.positive(0)
+		if err != nil {
+			return nil, err
+		}
+		if p.values[1] < step {
+			return nil, fmt.Errorf("invalid psar max step %g: must be at least the step %g", p.values[1], step)
+		}
+		return NewParabolicSAR(step, p.values[1]), nil
+	}},
+	"bollinger": {[]string{"period", "multiplier"}, func(p params) (Indicator, error) {
+		period, err := p.period(0)
+		if err != nil {
+			return nil, err
+		}
+		multiplier, err := p.positive(1)
+		if err != nil {
+			return nil, err
+		}
+		return NewBollingerBands(period, multiplier), nil
+	}},
+	"keltner": {[]string{"ema period", "atr period", "multiplier"}, func(p params) (Indicator, error) {
+		emaPeriod, err := p.period(0)
+		if err != nil {
+			return nil, err
+		}
+		atrPeriod, err := p.period(1)
+		if err != nil {
+			return nil, err
+		}
+		multiplier, err := p.positive(2)
+		if err != nil {
+			return nil, err
+		}
+		return NewKeltnerChannels(emaPeriod, atrPeriod, multiplier), nil
+	}},
+	"volume_profile": {[]string{"period", "bins", "value area"}, func(p params) (Indicator, error) {
+		period, err := p.period(0)
+		if err != nil {
+			return nil, err
+		}
+		bins, err := p.period(1)
+		if err != nil {
+			return nil, err
+		}
+		valueArea, err := p.positive(2)
+		if err != nil {
+			return nil, err
+		}
+		if valueArea > 1 {
+			return nil, fmt.Errorf("invalid volume_profile value area %g: must be at most 1", valueArea)
+		}
+		return NewVolumeProfile(period, bins, valueArea), nil
+	}},
+}
+
+// periodsOnly adapts a constructor whose parameters are all periods
+func periodsOnly(build func(periods []int) Indicator) func(p params) (Indicator, error) {
+	return func(p params) (Indicator, error) {
+		periods, err := p.periods()
+		if err != nil {
+			return nil, err
+		}
+		return build(periods), nil
+	}
+}
+
+// Names returns the indicator names accepted by New, sorted alphabetically
+func Names() []string {
+	names := make([]string, 0, len(constructors)+1)
+	for name := range constructors {
+		names = append(names, name)
+	}
+	names = append(names, "vwap")
+	sort.Strings(names)
+	return names
+}
+
+// New creates the indicator described by spec. Location is the session time
+// zone used by session-anchored indicators (VWAP); nil means UTC.
+func New(spec strategy.IndicatorSpec, location *time.Location) (Indicator, error) {
+	name := strings.ToLower(spec.Name)
+	if name == "vwap" {
+		if len(spec.Params) != 0 {
+			return nil, fmt.Errorf("invalid vwap parameters %v: expected none", spec.Params)
+		}
+		if location == nil {
+			location = time.UTC
+		}
+		return NewVWAP(location), nil
+	}
+
+	c, ok := constructors[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown indicator %q", spec.Name)
+	}
+	if len(spec.Params) != len(c.params) {
+		return nil, fmt.Errorf("invalid %s parameters %v: expected %d (%s)", name, spec.Params, len(c.params), strings.Join(c.params, ", "))
+	}
+
+	indicator, err := c.build(params{name: name, names: c.params, values: spec.Params})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := OutputValue(indicator, spec.Output); err != nil {
+		return nil, err
+	}
+	return indicator, nil
+}
+
+// OutputValue returns the named output of an indicator; an empty name returns Value
+func OutputValue(indicator Indicator, output string) (float64, error) {
+	output = strings.ToLower(output)
+	if output == "" || output == "value" {
+		return indicator.Value(), nil
+	}
+
+	var value float64
+	found := true
+	switch ind := indicator.(type) {
+	case *MACD:
+		switch output {
+		case "macd":
+			value = ind.Value()
+		case "signal":
+			value = ind.Signal()
+		case "histogram":
+			value = ind.Histogram()
+		default:
+			found = false
+		}
+	case *ADX:
+		switch output {
+		case "adx":
+			value = ind.Value()
+		case "plus_di":
+			value = ind.PlusDI()
+		case "minus_di":
+			value = ind.MinusDI()
+		default:
+			found = false
+		}
+	case *SuperTrend:
+		switch output {
+		case "direction":
+			value = float64(ind.Direction())
+		case "upper":
+			value = ind.UpperBand()
+		case "lower":
+			value = ind.LowerBand()
+		default:
+			found = false
+		}
+	case *ParabolicSAR:
+		switch output {
+		case "is_long":
+			value = 0
+			if ind.IsLong() {
+				value = 1
+			}
+		default:
+			found = false
+		}
+	case *BollingerBands:
+		switch output {
+		case "upper":
+			value = ind.Upper()
+		case "middle":
+			value = ind.Value()
+		case "lower":
+			value = ind.Lower()
+		case "percent_b":
+			value = ind.PercentB()
+		case "bandwidth":
+			value = ind.Bandwidth()
+		default:
+			found = false
+		}
+	case *KeltnerChannels:
+		value, found = channelOutput(output, ind.Upper(), ind.Value(), ind.Lower())
+	case *DonchianChannels:
+		value, found = channelOutput(output, ind.Upper(), ind.Value(), ind.Lower())
+	case *VolumeProfile:
+		switch output {
+		case "poc":
+			value = ind.Value()
+		case "value_area_high":
+			value = ind.ValueAreaHigh()
+		case "value_area_low":
+			value = ind.ValueAreaLow()
+		default:
+			found = false
+		}
+	case *Stochastic:
+		value, found = stochasticOutput(output, ind.Value(), ind.D())
+	case *StochasticRSI:
+		value, found = stochasticOutput(output, ind.Value(), ind.D())
+	case *Aroon:
+		switch output {
+		case "up":
+			value = ind.Up()
+		case "down":
+			value = ind.Down()
+		case "oscillator":
+			value = ind.Value()
+		default:
+			found = false
+		}
+	case *Ichimoku:
+		switch output {
+		case "tenkan":
+			value = ind.Tenkan()
+		case "kijun":
+			value = ind.Kijun()
+		case "senkou_a":
+			value = ind.SenkouA()
+		case "senkou_b":
+			value = ind.SenkouB()
+		case "chikou":
+			value = ind.Chikou()
+		default:
+			found = false
+		}
+	default:
+		found = false
+	}
+
+	if !found {
+		return 0, fmt.Errorf("unknown output %q for indicator %T", output, indicator)
+	}
+	return value, nil
+}
+
+// Output names of the multi-value indicators, as accepted by OutputValue
+var (
+	macdOutputs       = []string{"macd", "signal", "histogram"}
+	adxOutputs        = []string{"adx", "plus_di", "minus_di"}
+	superTrendOutputs = []string{"direction", "upper", "lower"}
+	sarOutputs        = []string{"is_long"}
+	bollingerOutputs  = []string{"upper", "middle", "lower", "percent_b", "bandwidth"}
+	channelOutputs    = []string{"upper", "middle", "lower"}
+	profileOutputs    = []string{"poc", "value_area_high", "value_area_low"}
+	stochasticOutputs = []string{"k", "d"}
+	aroonOutputs      = []string{"up", "down", "oscillator"}
+	ichimokuOutputs   = []string{"tenkan", "kijun", "senkou_a", "senkou_b", "chikou"}
+)
+
+// Outputs returns the named outputs of an indicator besides its main value
+func Outputs(indicator Indicator) []string {
+	switch indicator.(type) {
+	case *MACD:
+		return macdOutputs
+	case *ADX:
+		return adxOutputs
+	case *SuperTrend:
+		return superTrendOutputs
+	case *ParabolicSAR:
+		return sarOutputs
+	case *BollingerBands:
+		return bollingerOutputs
+	case *KeltnerChannels, *DonchianChannels:
+		return channelOutputs
+	case *VolumeProfile:
+		return profileOutputs
+	case *Stochastic, *StochasticRSI:
+		return stochasticOutputs
+	case *Aroon:
+		return aroonOutputs
+	case *Ichimoku:
+		return ichimokuOutputs
+	}
+	return nil
+}
+
+// channelOutput selects the upper, middle or lower line of a channel
+func channelOutput(output string, upper, middle, lower float64) (float64, bool) {
+	switch output {
+	case "upper":
+		return upper, true
+	case "middle":
+		return middle, true
+	case "lower":
+		return lower, true
+	}
+	return 0, false
+}
+
+// stochasticOutput selects %K or %D of a stochastic oscillator
+func stochasticOutput(output string, k, d float64) (float64, bool) {
+	switch output {
+	case "k":
+		return k, true
+	case "d":
+		return d, true
+	}
+	return 0, false
+}
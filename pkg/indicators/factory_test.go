@@ -0,0 +1,35 @@
+package indicators
+
+import "testing"
+
+func TestOutputsAreAcceptedByOutputValue(t *testing.T) {
+	multiValue := []Indicator{
+		NewMACD(12, 26, 9),
+		NewADX(14),
+		NewSuperTrend(10, 3),
+		NewParabolicSAR(0.02, 0.2),
+		NewBollingerBands(20, 2),
+		NewKeltnerChannels(20, 10, 2),
+		NewDonchianChannels(20),
+		NewVolumeProfile(20, 10, 0.7),
+		NewStochastic(14, 3, 3),
+		NewStochasticRSI(14, 14, 3, 3),
+		NewAroon(14),
+		NewIchimoku(9, 26, 52, 26),
+	}
+	for _, indicator := range multiValue {
+		outputs := Outputs(indicator)
+		if len(outputs) == 0 {
+			t.Errorf("%T lists no outputs", indicator)
+		}
+		for _, output := range outputs {
+			if _, err := OutputValue(indicator, output); err != nil {
+				t.Errorf("%T output %q: %v", indicator, output, err)
+			}
+		}
+	}
+
+	if outputs := Outputs(NewSMA(10)); outputs != nil {
+		t.Errorf("SMA outputs = %v, want none", outputs)
+	}
+}
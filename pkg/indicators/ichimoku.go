@@ -0,0 +1,125 @@
+package indicators
+
+import "github.com/ridopark/JonBuhTrader/pkg/strategy"
+
+// Ichimoku is the Ichimoku Kinko Hyo cloud. The leading spans are plotted
+// displacement-1 bars ahead (the common charting convention), so SenkouA and
+// SenkouB return the cloud under the current bar, computed from earlier bars.
+// Value returns the Kijun-sen.
+type Ichimoku struct {
+	tenkanHigh   *rollingExtreme
+	tenkanLow    *rollingExtreme
+	kijunHigh    *rollingExtreme
+	kijunLow     *rollingExtreme
+	senkouBHigh  *rollingExtreme
+	senkouBLow   *rollingExtreme
+	displacement int
+	leadingA     *Window // Senkou Span A values awaiting display, oldest first
+	leadingB     *Window // Senkou Span B values awaiting display, oldest first
+	lastClose    float64
+}
+
+// NewIchimoku creates an Ichimoku cloud with the given conversion line, base line
+// and leading span B periods and displacement (typically 9, 26, 52 and 26)
+func NewIchimoku(tenkanPeriod, kijunPeriod, senkouBPeriod, displacement int) *Ichimoku {
+	displacement = validPeriod(displacement)
+	return &Ichimoku{
+		tenkanHigh:   newRollingMax(tenkanPeriod),
+		tenkanLow:    newRollingMin(tenkanPeriod),
+		kijunHigh:    newRollingMax(kijunPeriod),
+		kijunLow:     newRollingMin(kijunPeriod),
+		senkouBHigh:  newRollingMax(senkouBPeriod),
+		senkouBLow:   newRollingMin(senkouBPeriod),
+		displacement: displacement,
+		leadingA:     NewWindow(displacement),
+		leadingB:     NewWindow(displacement),
+	}
+}
+
+// Update feeds the next bar
+func (ic *Ichimoku) Update(bar strategy.BarData) {
+	for _, extreme := range []*rollingExtreme{ic.tenkanHigh, ic.kijunHigh, ic.senkouBHigh} {
+		extreme.Add(bar.High)
+	}
+	for _, extreme := range []*rollingExtreme{ic.tenkanLow, ic.kijunLow, ic.senkouBLow} {
+		extreme.Add(bar.Low)
+	}
+	ic.lastClose = bar.Close
+
+	if ic.tenkanHigh.Ready() && ic.kijunHigh.Ready() {
+		ic.leadingA.Add((ic.Tenkan() + ic.Kijun()) / 2)
+	}
+	if ic.senkouBHigh.Ready() {
+		ic.leadingB.Add((ic.senkouBHigh.Value() + ic.senkouBLow.Value()) / 2)
+	}
+}
+
+// Tenkan returns the conversion line, the midpoint of the tenkan period's range
+func (ic *Ichimoku) Tenkan() float64 {
+	return (ic.tenkanHigh.Value() + ic.tenkanLow.Value()) / 2
+}
+
+// Kijun returns the base line, the midpoint of the kijun period's range
+func (ic *Ichimoku) Kijun() float64 {
+	return (ic.kijunHigh.Value() + ic.kijunLow.Value()) / 2
+}
+
+// SenkouA returns leading span A of the cloud under the current bar
+func (ic *Ichimoku) SenkouA() float64 {
+	if !ic.leadingA.Full() {
+		return 0
+	}
+	return ic.leadingA.At(0)
+}
+
+// SenkouB returns leading span B of the cloud under the current bar
+func (ic *Ichimoku) SenkouB() float64 {
+	if !ic.leadingB.Full() {
+		return 0
+	}
+	return ic.leadingB.At(0)
+}
+
+// FutureSenkouA returns leading span A computed from the current bar (the cloud displacement-1 bars ahead)
+func (ic *Ichimoku) FutureSenkouA() float64 {
+	return ic.leadingA.Last()
+}
+
+// FutureSenkouB returns leading span B computed from the current bar (the cloud displacement-1 bars ahead)
+func (ic *Ichimoku) FutureSenkouB() float64 {
+	return ic.leadingB.Last()
+}
+
+// Chikou returns the lagging span: the current close, plotted displacement-1 bars back
+func (ic *Ichimoku) Chikou() float64 {
+	return ic.lastClose
+}
+
+// Value returns the Kijun-sen
+func (ic *Ichimoku) Value() float64 {
+	return ic.Kijun()
+}
+
+// Ready returns true once the cloud under the current bar is available
+func (ic *Ichimoku) Ready() bool {
+	return ic.leadingA.Full() && ic.leadingB.Full()
+}
+
+// WarmupPeriod returns the number of bars needed before the cloud is available
+func (ic *Ichimoku) WarmupPeriod() int {
+	longest := max(ic.tenkanHigh.period, ic.kijunHigh.period, ic.senkouBHigh.period)
+	return longest + ic.displacement - 1
+}
+
+// Reset clears all state
+func (ic *Ichimoku) Reset() {
+	for _, extreme := range []*rollingExtreme{ic.tenkanHigh, ic.tenkanLow, ic.kijunHigh, ic.kijunLow, ic.senkouBHigh, ic.senkouBLow} {
+		extreme.Reset()
+	}
+	ic.leadingA.Reset()
+	ic.leadingB.Reset()
+	ic.lastClose = 0
+}
+
+// Verify that Ichimoku implements the Indicator interface
+var _ Indicator = (*Ichimoku)(nil)
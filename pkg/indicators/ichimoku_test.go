@@ -0,0 +1,61 @@
+package indicators
+
+import (
+	"testing"
+
+	"github.com/ridopark/JonBuhTrader/pkg/strategy"
+)
+
+func TestIchimoku(t *testing.T) {
+	// Tenkan over 2 bars, Kijun over 3, Senkou B over 4, with the cloud displaced 1 bar ahead
+	ichimoku := NewIchimoku(2, 3, 4, 2)
+	if got := ichimoku.WarmupPeriod(); got != 5 {
+		t.Fatalf("WarmupPeriod = %d, want 5", got)
+	}
+	bars := []strategy.BarData{
+		{High: 10, Low: 8, Close: 9},
+		{High: 12, Low: 9, Close: 11},
+		{High: 14, Low: 11, Close: 13},
+		{High: 13, Low: 10, Close: 12},
+		{High: 16, Low: 12, Close: 15},
+		{High: 15, Low: 13, Close: 14},
+	}
+
+	// Span A is 11.25, 11.75, 13 and 13.5 from bars 3 to 6 and span B is 11, 12.5 and 13 from
+	// bars 4 to 6; each is displayed on the following bar
+	tests := []struct {
+		tenkan, kijun    float64
+		senkouA, senkouB float64
+		futureA, futureB float64
+		chikou           float64
+		ready            bool
+	}{
+		{tenkan: 12, kijun: 11.5, senkouA: 11.25, futureA: 11.75, futureB: 11, chikou: 12},
+		{tenkan: 13, kijun: 13, senkouA: 11.75, senkouB: 11, futureA: 13, futureB: 12.5, chikou: 15, ready: true},
+		{tenkan: 14, kijun: 13, senkouA: 13, senkouB: 12.5, futureA: 13.5, futureB: 13, chikou: 14, ready: true},
+	}
+	for i, bar := range bars {
+		ichimoku.Update(bar)
+		if i < 3 {
+			if ichimoku.Ready() || ichimoku.SenkouA() != 0 || ichimoku.SenkouB() != 0 {
+				t.Fatalf("cloud available after %d bars", i+1)
+			}
+			continue
+		}
+		tt := tests[i-3]
+		got := []float64{ichimoku.Tenkan(), ichimoku.Kijun(), ichimoku.SenkouA(), ichimoku.SenkouB(), ichimoku.FutureSenkouA(), ichimoku.FutureSenkouB(), ichimoku.Chikou()}
+		want := []float64{tt.tenkan, tt.kijun, tt.senkouA, tt.senkouB, tt.futureA, tt.futureB, tt.chikou}
+		assertValues(t, "tenkan, kijun, senkou A/B, future A/B, chikou", got, want)
+		if ichimoku.Ready() != tt.ready {
+			t.Errorf("Ready() = %v after %d bars, want %v", ichimoku.Ready(), i+1, tt.ready)
+		}
+		if ichimoku.Value() != ichimoku.Kijun() {
+			t.Errorf("Value = %v, want the Kijun-sen %v", ichimoku.Value(), ichimoku.Kijun())
+		}
+	}
+
+	ichimoku.Reset()
+	if ichimoku.Ready() || ichimoku.Chikou() != 0 {
+		t.Error("expected Reset to clear the cloud")
+	}
+}
@@ -0,0 +1,57 @@
+// Package indicators provides incremental technical indicators that are
+// updated one bar (or value) at a time in constant time.
+package indicators
+
+import "github.com/ridopark/JonBuhTrader/pkg/strategy"
+
+// Indicator is a technical indicator that is updated incrementally with each new bar
+type Indicator interface {
+	// Update feeds the next bar into the indicator
+	Update(bar strategy.BarData)
+
+	// Value returns the current indicator value (0 until Ready)
+	Value() float64
+
+	// Ready returns true once enough data has been seen to produce a value
+	Ready() bool
+
+	// WarmupPeriod returns the number of updates needed before the indicator is ready
+	WarmupPeriod() int
+
+	// Reset clears all state so the indicator can be reused
+	Reset()
+}
+
+// SeriesIndicator is an indicator computed from a single series of values.
+// Update feeds the bar's close; Add can be used to feed any other series,
+// which allows indicators to be chained (e.g., an EMA of an RSI).
+type SeriesIndicator interface {
+	Indicator
+
+	// Add feeds the next value of the series into the indicator
+	Add(value float64)
+}
+
+// Last feeds every value into the indicator and returns its final value and readiness
+func Last(indicator SeriesIndicator, values []float64) (float64, bool) {
+	for _, value := range values {
+		indicator.Add(value)
+	}
+	return indicator.Value(), indicator.Ready()
+}
+
+// Replay feeds every bar into the indicator and returns its final value and readiness
+func Replay(indicator Indicator, bars []strategy.BarData) (float64, bool) {
+	for _, bar := range bars {
+		indicator.Update(bar)
+	}
+	return indicator.Value(), indicator.Ready()
+}
+
+// validPeriod returns period, or 1 if period is not positive
+func validPeriod(period int) int {
+	if period < 1 {
+		return 1
+	}
+	return period
+}
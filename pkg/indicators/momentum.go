@@ -0,0 +1,410 @@
+package indicators
+
+import (
+	"math"
+
+	"github.com/ridopark/JonBuhTrader/pkg/strategy"
+)
+
+// stochasticValue returns where value sits within [low, high] on a 0-100 scale
+func stochasticValue(value, high, low float64) float64 {
+	if high == low {
+		return 50
+	}
+	return 100 * (value - low) / (high - low)
+}
+
+// Stochastic is the full stochastic oscillator. Value returns %K; D returns %D.
+// A kSmoothing of 1 gives the fast stochastic and 3 the slow stochastic.
+type Stochastic struct {
+	kPeriod int
+	highest *rollingExtreme
+	lowest  *rollingExtreme
+	k       *SMA
+	d       *SMA
+}
+
+// NewStochastic creates a full stochastic with a kPeriod look-back, %K smoothed
+// over kSmoothing bars and %D averaged over dPeriod bars
+func NewStochastic(kPeriod, kSmoothing, dPeriod int) *Stochastic {
+	kPeriod = validPeriod(kPeriod)
+	return &Stochastic{
+		kPeriod: kPeriod,
+		highest: newRollingMax(kPeriod),
+		lowest:  newRollingMin(kPeriod),
+		k:       NewSMA(kSmoothing),
+		d:       NewSMA(dPeriod),
+	}
+}
+
+// NewFastStochastic creates a fast stochastic (unsmoothed %K)
+func NewFastStochastic(kPeriod, dPeriod int) *Stochastic {
+	return NewStochastic(kPeriod, 1, dPeriod)
+}
+
+// NewSlowStochastic creates a slow stochastic (%K smoothed over 3 bars)
+func NewSlowStochastic(kPeriod, dPeriod int) *Stochastic {
+	return NewStochastic(kPeriod, 3, dPeriod)
+}
+
+// Update feeds the next bar
+func (s *Stochastic) Update(bar strategy.BarData) {
+	s.highest.Add(bar.High)
+	s.lowest.Add(bar.Low)
+	if !s.highest.Ready() {
+		return
+	}
+
+	s.k.Add(stochasticValue(bar.Close, s.highest.Value(), s.lowest.Value()))
+	if s.k.Ready() {
+		s.d.Add(s.k.Value())
+	}
+}
+
+// Value returns %K
+func (s *Stochastic) Value() float64 {
+	if !s.Ready() {
+		return 0
+	}
+	return s.k.Value()
+}
+
+// D returns %D, the moving average of %K
+func (s *Stochastic) D() float64 {
+	if !s.Ready() {
+		return 0
+	}
+	return s.d.Value()
+}
+
+// Ready returns true once %D is available
+func (s *Stochastic) Ready() bool {
+	return s.d.Ready()
+}
+
+// WarmupPeriod returns the number of bars needed before %D is available
+func (s *Stochastic) WarmupPeriod() int {
+	return s.kPeriod + s.k.WarmupPeriod() + s.d.WarmupPeriod() - 2
+}
+
+// Reset clears all state
+func (s *Stochastic) Reset() {
+	s.highest.Reset()
+	s.lowest.Reset()
+	s.k.Reset()
+	s.d.Reset()
+}
+
+// StochasticRSI applies the stochastic oscillator to RSI values. Value returns %K; D returns %D.
+type StochasticRSI struct {
+	rsi     *RSI
+	period  int
+	highest *rollingExtreme
+	lowest  *rollingExtreme
+	k       *SMA
+	d       *SMA
+}
+
+// NewStochasticRSI creates a Stochastic RSI from an RSI over rsiPeriod values, a
+// stochastic look-back of stochPeriod RSI values, %K smoothing and %D period
+func NewStochasticRSI(rsiPeriod, stochPeriod, kSmoothing, dPeriod int) *StochasticRSI {
+	stochPeriod = validPeriod(stochPeriod)
+	return &StochasticRSI{
+		rsi:     NewRSI(rsiPeriod),
+		period:  stochPeriod,
+		highest: newRollingMax(stochPeriod),
+		lowest:  newRollingMin(stochPeriod),
+		k:       NewSMA(kSmoothing),
+		d:       NewSMA(dPeriod),
+	}
+}
+
+// Add feeds the next value
+func (s *StochasticRSI) Add(value float64) {
+	s.rsi.Add(value)
+	if !s.rsi.Ready() {
+		return
+	}
+
+	rsi := s.rsi.Value()
+	s.highest.Add(rsi)
+	s.lowest.Add(rsi)
+	if !s.highest.Ready() {
+		return
+	}
+
+	s.k.Add(stochasticValue(rsi, s.highest.Value(), s.lowest.Value()))
+	if s.k.Ready() {
+		s.d.Add(s.k.Value())
+	}
+}
+
+// Update feeds the bar's close
+func (s *StochasticRSI) Update(bar strategy.BarData) {
+	s.Add(bar.Close)
+}
+
+// Value returns %K between 0 and 100
+func (s *StochasticRSI) Value() float64 {
+	if !s.Ready() {
+		return 0
+	}
+	return s.k.Value()
+}
+
+// D returns %D, the moving average of %K
+func (s *StochasticRSI) D() float64 {
+	if !s.Ready() {
+		return 0
+	}
+	return s.d.Value()
+}
+
+// Ready returns true once %D is available
+func (s *StochasticRSI) Ready() bool {
+	return s.d.Ready()
+}
+
+// WarmupPeriod returns the number of values needed before %D is available
+func (s *StochasticRSI) WarmupPeriod() int {
+	return s.rsi.WarmupPeriod() + s.period + s.k.WarmupPeriod() + s.d.WarmupPeriod() - 3
+}
+
+// Reset clears all state
+func (s *StochasticRSI) Reset() {
+	s.rsi.Reset()
+	s.highest.Reset()
+	s.lowest.Reset()
+	s.k.Reset()
+	s.d.Reset()
+}
+
+// WilliamsR is Williams %R, the close relative to the high-low range on a -100 to 0 scale
+type WilliamsR struct {
+	period  int
+	highest *rollingExtreme
+	lowest  *rollingExtreme
+	value   float64
+}
+
+// NewWilliamsR creates a Williams %R over period bars
+func NewWilliamsR(period int) *WilliamsR {
+	period = validPeriod(period)
+	return &WilliamsR{
+		period:  period,
+		highest: newRollingMax(period),
+		lowest:  newRollingMin(period),
+	}
+}
+
+// Update feeds the next bar
+func (w *WilliamsR) Update(bar strategy.BarData) {
+	w.highest.Add(bar.High)
+	w.lowest.Add(bar.Low)
+	if w.highest.Ready() {
+		w.value = stochasticValue(bar.Close, w.highest.Value(), w.lowest.Value()) - 100
+	}
+}
+
+// Value returns %R between -100 and 0
+func (w *WilliamsR) Value() float64 {
+	return w.value
+}
+
+// Ready returns true once period bars have been seen
+func (w *WilliamsR) Ready() bool {
+	return w.highest.Ready()
+}
+
+// WarmupPeriod returns the number of bars needed before %R is ready
+func (w *WilliamsR) WarmupPeriod() int {
+	return w.period
+}
+
+// Reset clears all state
+func (w *WilliamsR) Reset() {
+	w.highest.Reset()
+	w.lowest.Reset()
+	w.value = 0
+}
+
+// CCI is the Commodity Channel Index. The mean deviation is recomputed over the
+// window on each update, so updates cost O(period).
+type CCI struct {
+	period int
+	window *Window
+	sum    float64
+	value  float64
+}
+
+// NewCCI creates a Commodity Channel Index over period bars
+func NewCCI(period int) *CCI {
+	period = validPeriod(period)
+	return &CCI{period: period, window: NewWindow(period)}
+}
+
+// Update feeds the next bar
+func (c *CCI) Update(bar strategy.BarData) {
+	typical := typicalPrice(bar)
+	if evicted, full := c.window.Add(typical); full {
+		c.sum -= evicted
+	}
+	c.sum += typical
+	if !c.window.Full() {
+		return
+	}
+
+	mean := c.sum / float64(c.period)
+	deviation := 0.0
+	for i := 0; i < c.period; i++ {
+		deviation += math.Abs(c.window.At(i) - mean)
+	}
+	deviation /= float64(c.period)
+
+	c.value = 0
+	if deviation > 0 {
+		c.value = (typical - mean) / (0.015 * deviation)
+	}
+}
+
+// Value returns the CCI
+func (c *CCI) Value() float64 {
+	return c.value
+}
+
+// Ready returns true once period bars have been seen
+func (c *CCI) Ready() bool {
+	return c.window.Full()
+}
+
+// WarmupPeriod returns the number of bars needed before the CCI is ready
+func (c *CCI) WarmupPeriod() int {
+	return c.period
+}
+
+// Reset clears all state
+func (c *CCI) Reset() {
+	c.window.Reset()
+	c.sum = 0
+	c.value = 0
+}
+
+// ROC is the Rate of Change, the percentage change over period values
+type ROC struct {
+	period int
+	window *Window
+}
+
+// NewROC creates a Rate of Change over period values
+func NewROC(period int) *ROC {
+	period = validPeriod(period)
+	return &ROC{period: period, window: NewWindow(period + 1)}
+}
+
+// Add feeds the next value
+func (r *ROC) Add(value float64) {
+	r.window.Add(value)
+}
+
+// Update feeds the bar's close
+func (r *ROC) Update(bar strategy.BarData) {
+	r.Add(bar.Close)
+}
+
+// Value returns the percentage change from period values ago
+func (r *ROC) Value() float64 {
+	if !r.Ready() {
+		return 0
+	}
+	previous := r.window.At(0)
+	if previous == 0 {
+		return 0
+	}
+	return 100 * (r.window.Last() - previous) / previous
+}
+
+// Ready returns true once period+1 values have been seen
+func (r *ROC) Ready() bool {
+	return r.window.Full()
+}
+
+// WarmupPeriod returns the number of values needed before the ROC is ready
+func (r *ROC) WarmupPeriod() int {
+	return r.period + 1
+}
+
+// Reset clears all state
+func (r *ROC) Reset() {
+	r.window.Reset()
+}
+
+// Aroon measures how recently the highest high and lowest low of the period
+// occurred. Value returns the oscillator (Aroon Up minus Aroon Down).
+type Aroon struct {
+	period  int
+	highest *rollingExtreme
+	lowest  *rollingExtreme
+}
+
+// NewAroon creates an Aroon indicator over period bars
+func NewAroon(period int) *Aroon {
+	period = validPeriod(period)
+	return &Aroon{
+		period:  period,
+		highest: newRollingMax(period + 1),
+		lowest:  newRollingMin(period + 1),
+	}
+}
+
+// Update feeds the next bar
+func (a *Aroon) Update(bar strategy.BarData) {
+	a.highest.Add(bar.High)
+	a.lowest.Add(bar.Low)
+}
+
+// Up returns Aroon Up between 0 and 100
+func (a *Aroon) Up() float64 {
+	if !a.Ready() {
+		return 0
+	}
+	return 100 * float64(a.period-a.highest.BarsSince()) / float64(a.period)
+}
+
+// Down returns Aroon Down between 0 and 100
+func (a *Aroon) Down() float64 {
+	if !a.Ready() {
+		return 0
+	}
+	return 100 * float64(a.period-a.lowest.BarsSince()) / float64(a.period)
+}
+
+// Value returns the Aroon oscillator between -100 and 100
+func (a *Aroon) Value() float64 {
+	return a.Up() - a.Down()
+}
+
+// Ready returns true once period+1 bars have been seen
+func (a *Aroon) Ready() bool {
+	return a.highest.Ready()
+}
+
+// WarmupPeriod returns the number of bars needed before Aroon is ready
+func (a *Aroon) WarmupPeriod() int {
+	return a.period + 1
+}
+
+// Reset clears all state
+func (a *Aroon) Reset() {
+	a.highest.Reset()
+	a.lowest.Reset()
+}
+
+// Verify that the momentum indicators implement the indicator interfaces
+var (
+	_ Indicator       = (*Stochastic)(nil)
+	_ SeriesIndicator = (*StochasticRSI)(nil)
+	_ Indicator       = (*WilliamsR)(nil)
+	_ Indicator       = (*CCI)(nil)
+	_ SeriesIndicator = (*ROC)(nil)
+	_ Indicator       = (*Aroon)(nil)
+)
@@ -0,0 +1,103 @@
+package indicators
+
+import (
+	"testing"
+
+	"github.com/ridopark/JonBuhTrader/pkg/strategy"
+)
+
+func TestStochasticMatchesTALib(t *testing.T) {
+	tests := []struct {
+		name      string
+		indicator func() *Stochastic
+		wantK     []float64
+		wantD     []float64
+	}{
+		{
+			name:      "slow",
+			indicator: func() *Stochastic { return NewSlowStochastic(14, 3) },
+			wantK:     []float64{91.876704, 93.068169, 91.932212, 93.218137, 91.743622},
+			wantD:     []float64{91.786677, 92.809299, 92.292361, 92.739506, 92.297990},
+		},
+		{
+			name:      "fast",
+			indicator: func() *Stochastic { return NewFastStochastic(5, 3) },
+			wantK:     []float64{75.240385, 92.482916, 87.500000, 81.873727, 80.586907},
+			wantD:     []float64{86.353483, 87.052916, 85.074433, 87.285548, 83.320212},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k := tt.indicator()
+			assertValues(t, "%K", referenceTail(t, k, k.Value, 5), tt.wantK)
+			d := tt.indicator()
+			assertValues(t, "%D", referenceTail(t, d, d.D, 5), tt.wantD)
+		})
+	}
+}
+
+func TestStochasticRSIMatchesTALib(t *testing.T) {
+	// TA-Lib's STOCHRSI returns the fast stochastic of RSI, so %K is unsmoothed
+	k := NewStochasticRSI(14, 5, 1, 3)
+	assertValues(t, "StochRSI %K", referenceTail(t, k, k.Value, 12), []float64{
+		0, 41.223480, 100, 100, 100, 100, 100, 80.762442, 100, 100, 100, 100,
+	})
+	d := NewStochasticRSI(14, 5, 1, 3)
+	assertValues(t, "StochRSI %D", referenceTail(t, d, d.D, 12), []float64{
+		50.826058, 34.299813, 47.074493, 80.407827, 100, 100, 100, 93.587481, 93.587481, 93.587481, 100, 100,
+	})
+}
+
+func TestWilliamsRMatchesTALib(t *testing.T) {
+	williamsR := NewWilliamsR(14)
+	got := referenceTail(t, williamsR, williamsR.Value, 5)
+	assertValues(t, "WilliamsR(14)", got, []float64{-12.875000, -4.024390, -7.303974, -9.017224, -8.447937})
+}
+
+func TestCCIMatchesTALib(t *testing.T) {
+	cci := NewCCI(20)
+	got := referenceTail(t, cci, cci.Value, 5)
+	assertValues(t, "CCI(20)", got, []float64{181.696681, 163.473319, 178.971892, 166.369283, 150.716884})
+}
+
+func TestROCMatchesTALib(t *testing.T) {
+	roc := NewROC(10)
+	got := referenceTail(t, roc, roc.Value, 5)
+	assertValues(t, "ROC(10)", got, []float64{6.332454, 9.314704, 15.794286, 18.083640, 17.248413})
+}
+
+func TestAroonMatchesTALib(t *testing.T) {
+	aroon := NewAroon(14)
+	assertValues(t, "Aroon oscillator", referenceTail(t, aroon, aroon.Value, 5), []float64{92.857143, 100, 100, 100, 78.571429})
+	assertValues(t, "Aroon up", []float64{aroon.Up()}, []float64{100})
+	assertValues(t, "Aroon down", []float64{aroon.Down()}, []float64{21.428571})
+}
+
+func TestMomentumFlatRange(t *testing.T) {
+	// A window without a range places the close mid-range rather than dividing by zero
+	stochastic := NewFastStochastic(3, 1)
+	williamsR := NewWilliamsR(3)
+	cci := NewCCI(3)
+	for i := 0; i < 3; i++ {
+		bar := strategy.BarData{High: 10, Low: 10, Close: 10}
+		stochastic.Update(bar)
+		williamsR.Update(bar)
+		cci.Update(bar)
+	}
+	if got := stochastic.Value(); got != 50 {
+		t.Errorf("flat stochastic = %v, want 50", got)
+	}
+	if got := williamsR.Value(); got != -50 {
+		t.Errorf("flat Williams %%R = %v, want -50", got)
+	}
+	if got := cci.Value(); got != 0 {
+		t.Errorf("flat CCI = %v, want 0", got)
+	}
+
+	roc := NewROC(1)
+	roc.Add(0)
+	roc.Add(10)
+	if got := roc.Value(); got != 0 {
+		t.Errorf("ROC from zero = %v, want 0", got)
+	}
+}
@@ -0,0 +1,161 @@
+package indicators
+
+import "github.com/ridopark/JonBuhTrader/pkg/strategy"
+
+// RSI is Wilder's Relative Strength Index
+type RSI struct {
+	period    int
+	gains     *EMA
+	losses    *EMA
+	prevValue float64
+	hasPrev   bool
+}
+
+// NewRSI creates a Relative Strength Index over period changes
+func NewRSI(period int) *RSI {
+	period = validPeriod(period)
+	return &RSI{
+		period: period,
+		gains:  NewWilderMA(period),
+		losses: NewWilderMA(period),
+	}
+}
+
+// Add feeds the next value
+func (r *RSI) Add(value float64) {
+	if !r.hasPrev {
+		r.prevValue = value
+		r.hasPrev = true
+		return
+	}
+
+	change := value - r.prevValue
+	r.prevValue = value
+	if change > 0 {
+		r.gains.Add(change)
+		r.losses.Add(0)
+	} else {
+		r.gains.Add(0)
+		r.losses.Add(-change)
+	}
+}
+
+// Update feeds the bar's close
+func (r *RSI) Update(bar strategy.BarData) {
+	r.Add(bar.Close)
+}
+
+// Value returns the RSI between 0 and 100
+func (r *RSI) Value() float64 {
+	if !r.Ready() {
+		return 0
+	}
+
+	avgGain := r.gains.Value()
+	avgLoss := r.losses.Value()
+	if avgLoss == 0 {
+		if avgGain == 0 {
+			return 50 // No movement at all
+		}
+		return 100
+	}
+	return 100 - 100/(1+avgGain/avgLoss)
+}
+
+// Ready returns true once period changes have been seen
+func (r *RSI) Ready() bool {
+	return r.gains.Ready()
+}
+
+// WarmupPeriod returns the number of values needed before the RSI is ready
+func (r *RSI) WarmupPeriod() int {
+	return r.period + 1
+}
+
+// Reset clears all state
+func (r *RSI) Reset() {
+	r.gains.Reset()
+	r.losses.Reset()
+	r.prevValue = 0
+	r.hasPrev = false
+}
+
+// MACD is the Moving Average Convergence Divergence indicator. Value returns the
+// MACD line; Signal and Histogram return the other two outputs.
+type MACD struct {
+	fast   *EMA
+	slow   *EMA
+	signal *EMA
+	line   float64
+}
+
+// NewMACD creates a MACD from fast and slow EMAs with a signal EMA of the MACD line
+func NewMACD(fastPeriod, slowPeriod, signalPeriod int) *MACD {
+	return &MACD{
+		fast:   NewEMA(fastPeriod),
+		slow:   NewEMA(slowPeriod),
+		signal: NewEMA(signalPeriod),
+	}
+}
+
+// Add feeds the next value
+func (m *MACD) Add(value float64) {
+	m.fast.Add(value)
+	m.slow.Add(value)
+	if !m.fast.Ready() || !m.slow.Ready() {
+		return
+	}
+
+	m.line = m.fast.Value() - m.slow.Value()
+	m.signal.Add(m.line)
+}
+
+// Update feeds the bar's close
+func (m *MACD) Update(bar strategy.BarData) {
+	m.Add(bar.Close)
+}
+
+// Value returns the MACD line (fast EMA minus slow EMA)
+func (m *MACD) Value() float64 {
+	if !m.Ready() {
+		return 0
+	}
+	return m.line
+}
+
+// Signal returns the signal line (EMA of the MACD line)
+func (m *MACD) Signal() float64 {
+	if !m.Ready() {
+		return 0
+	}
+	return m.signal.Value()
+}
+
+// Histogram returns the MACD line minus the signal line
+func (m *MACD) Histogram() float64 {
+	return m.Value() - m.Signal()
+}
+
+// Ready returns true once the signal line has been seeded
+func (m *MACD) Ready() bool {
+	return m.signal.Ready()
+}
+
+// WarmupPeriod returns the number of values needed before the signal line is ready
+func (m *MACD) WarmupPeriod() int {
+	return max(m.fast.WarmupPeriod(), m.slow.WarmupPeriod()) + m.signal.WarmupPeriod() - 1
+}
+
+// Reset clears all state
+func (m *MACD) Reset() {
+	m.fast.Reset()
+	m.slow.Reset()
+	m.signal.Reset()
+	m.line = 0
+}
+
+// Verify that the oscillators implement the SeriesIndicator interface
+var (
+	_ SeriesIndicator = (*RSI)(nil)
+	_ SeriesIndicator = (*MACD)(nil)
+)
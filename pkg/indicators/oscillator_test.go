@@ -0,0 +1,23 @@
+package indicators
+
+import "testing"
+
+func TestMACDMatchesTALib(t *testing.T) {
+	// TA-Lib seeds the fast EMA where the slow EMA starts rather than at the first value,
+	// so the comparison runs over a series long enough for that seed to decay
+	tests := []struct {
+		name   string
+		output func(macd *MACD) func() float64
+		want   []float64
+	}{
+		{"MACD", func(macd *MACD) func() float64 { return macd.Value }, []float64{1.277115, 0.798160, 0.233514, -0.364995, -0.902209}},
+		{"signal", func(macd *MACD) func() float64 { return macd.Signal }, []float64{2.379956, 2.063597, 1.697580, 1.285065, 0.847610}},
+		{"histogram", func(macd *MACD) func() float64 { return macd.Histogram }, []float64{-1.102841, -1.265437, -1.464066, -1.650060, -1.749819}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			macd := NewMACD(12, 26, 9)
+			assertValues(t, "MACD(12, 26, 9) "+tt.name, barsTail(t, longBars(), macd, tt.output(macd), 5), tt.want)
+		})
+	}
+}
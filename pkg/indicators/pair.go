@@ -0,0 +1,309 @@
+package indicators
+
+import "math"
+
+// EngleGrangerCriticalValue is the approximate 5% critical value of the Engle-Granger
+// test for two series. Statistics below it reject the hypothesis of no cointegration.
+const EngleGrangerCriticalValue = -3.34
+
+// PairIndicator is an indicator computed from two aligned series, such as an
+// asset and a benchmark or the two legs of a pair trade
+type PairIndicator interface {
+	// AddPair feeds the next x and y values, which must share a timestamp
+	AddPair(x, y float64)
+
+	// Value returns the current indicator value (0 until Ready)
+	Value() float64
+
+	// Ready returns true once enough pairs have been seen to produce a value
+	Ready() bool
+
+	// WarmupPeriod returns the number of pairs needed before the indicator is ready
+	WarmupPeriod() int
+
+	// Reset clears all state so the indicator can be reused
+	Reset()
+}
+
+// pairWindow keeps rolling means and co-moments of two series over the last period
+// pairs. Welford updates keep their precision when the values are large relative to
+// their spread.
+type pairWindow struct {
+	period int
+	x      *Window
+	y      *Window
+	meanX  float64
+	meanY  float64
+	m2X    float64 // Sum of squared deviations of x from its mean
+	m2Y    float64 // Sum of squared deviations of y from its mean
+	cXY    float64 // Sum of products of the deviations of x and y
+}
+
+// newPairWindow creates a pair window over period pairs
+func newPairWindow(period int) *pairWindow {
+	period = validPeriod(period)
+	return &pairWindow{period: period, x: NewWindow(period), y: NewWindow(period)}
+}
+
+// add feeds the next pair
+func (p *pairWindow) add(x, y float64) {
+	oldX, full := p.x.Add(x)
+	oldY, _ := p.y.Add(y)
+	if !full {
+		n := float64(p.x.Len())
+		dx := x - p.meanX
+		dy := y - p.meanY
+		p.meanX += dx / n
+		p.meanY += dy / n
+		p.m2X += dx * (x - p.meanX)
+		p.m2Y += dy * (y - p.meanY)
+		p.cXY += dx * (y - p.meanY)
+		return
+	}
+
+	// Replace the evicted pair in a single step
+	n := float64(p.period)
+	previousX, previousY := p.meanX, p.meanY
+	p.meanX += (x - oldX) / n
+	p.meanY += (y - oldY) / n
+	p.m2X += (x-previousX)*(x-p.meanX) - (oldX-previousX)*(oldX-p.meanX)
+	p.m2Y += (y-previousY)*(y-p.meanY) - (oldY-previousY)*(oldY-p.meanY)
+	p.cXY += (x-previousX)*(y-p.meanY) - (oldX-previousX)*(oldY-p.meanY)
+}
+
+// moments returns the means, population variances and covariance of the window
+func (p *pairWindow) moments() (meanX, meanY, varX, varY, cov float64) {
+	n := float64(p.period)
+	return p.meanX, p.meanY, math.Max(0, p.m2X/n), math.Max(0, p.m2Y/n), p.cXY / n
+}
+
+// ready returns true once period pairs have been added
+func (p *pairWindow) ready() bool {
+	return p.x.Full()
+}
+
+// reset clears all state
+func (p *pairWindow) reset() {
+	p.x.Reset()
+	p.y.Reset()
+	p.meanX, p.meanY, p.m2X, p.m2Y, p.cXY = 0, 0, 0, 0, 0
+}
+
+// Correlation is the rolling Pearson correlation of two series
+type Correlation struct {
+	window *pairWindow
+}
+
+// NewCorrelation creates a rolling correlation over period pairs
+func NewCorrelation(period int) *Correlation {
+	return &Correlation{window: newPairWindow(period)}
+}
+
+// AddPair feeds the next pair of values
+func (c *Correlation) AddPair(x, y float64) {
+	c.window.add(x, y)
+}
+
+// Value returns the correlation between -1 and 1 (0 if either series is flat)
+func (c *Correlation) Value() float64 {
+	if !c.Ready() {
+		return 0
+	}
+	_, _, varX, varY, cov := c.window.moments()
+	if varX == 0 || varY == 0 {
+		return 0
+	}
+	return math.Max(-1, math.Min(1, cov/math.Sqrt(varX*varY)))
+}
+
+// Ready returns true once period pairs have been added
+func (c *Correlation) Ready() bool {
+	return c.window.ready()
+}
+
+// WarmupPeriod returns the number of pairs needed before the correlation is ready
+func (c *Correlation) WarmupPeriod() int {
+	return c.window.period
+}
+
+// Reset clears all state
+func (c *Correlation) Reset() {
+	c.window.reset()
+}
+
+// Beta is the rolling sensitivity of an asset to a benchmark: cov(asset, benchmark) / var(benchmark).
+// Pairs are fed as (benchmark, asset), normally as periodic returns.
+type Beta struct {
+	window *pairWindow
+}
+
+// NewBeta creates a rolling beta over period pairs
+func NewBeta(period int) *Beta {
+	return &Beta{window: newPairWindow(period)}
+}
+
+// AddPair feeds the next benchmark and asset values
+func (b *Beta) AddPair(benchmark, asset float64) {
+	b.window.add(benchmark, asset)
+}
+
+// Value returns the beta (0 if the benchmark is flat)
+func (b *Beta) Value() float64 {
+	if !b.Ready() {
+		return 0
+	}
+	_, _, varX, _, cov := b.window.moments()
+	if varX == 0 {
+		return 0
+	}
+	return cov / varX
+}
+
+// Ready returns true once period pairs have been added
+func (b *Beta) Ready() bool {
+	return b.window.ready()
+}
+
+// WarmupPeriod returns the number of pairs needed before the beta is ready
+func (b *Beta) WarmupPeriod() int {
+	return b.window.period
+}
+
+// Reset clears all state
+func (b *Beta) Reset() {
+	b.window.reset()
+}
+
+// HedgeRatio is a rolling ordinary least squares regression of y on x, as used to
+// size the legs of a pair trade. Value returns the slope (units of x per unit of y);
+// the spread is y - (Intercept + HedgeRatio * x). Spread statistics are recomputed
+// over the window on each query, so they cost O(period).
+type HedgeRatio struct {
+	window *pairWindow
+}
+
+// NewHedgeRatio creates a rolling hedge ratio over period pairs
+func NewHedgeRatio(period int) *HedgeRatio {
+	return &HedgeRatio{window: newPairWindow(period)}
+}
+
+// AddPair feeds the next x and y values
+func (h *HedgeRatio) AddPair(x, y float64) {
+	h.window.add(x, y)
+}
+
+// Value returns the hedge ratio (0 if x is flat)
+func (h *HedgeRatio) Value() float64 {
+	slope, _ := h.coefficients()
+	return slope
+}
+
+// Intercept returns the regression intercept
+func (h *HedgeRatio) Intercept() float64 {
+	_, intercept := h.coefficients()
+	return intercept
+}
+
+// Spread returns the latest residual of y against the fitted line
+func (h *HedgeRatio) Spread() float64 {
+	if !h.Ready() {
+		return 0
+	}
+	spreads := h.spreads()
+	return spreads[len(spreads)-1]
+}
+
+// SpreadZScore returns the latest spread in standard deviations of the spreads in the window
+func (h *HedgeRatio) SpreadZScore() float64 {
+	if !h.Ready() {
+		return 0
+	}
+	spreads := h.spreads()
+	sumSq := 0.0
+	for _, spread := range spreads {
+		sumSq += spread * spread
+	}
+	// OLS residuals with an intercept have zero mean
+	deviation := math.Sqrt(sumSq / float64(len(spreads)))
+	if deviation == 0 {
+		return 0
+	}
+	return spreads[len(spreads)-1] / deviation
+}
+
+// CointegrationStatistic returns the Engle-Granger statistic: the Dickey-Fuller t-statistic
+// of the spreads in the window. More negative values indicate a more mean-reverting spread;
+// compare against EngleGrangerCriticalValue.
+func (h *HedgeRatio) CointegrationStatistic() float64 {
+	if !h.Ready() || h.window.period < 3 {
+		return 0
+	}
+	spreads := h.spreads()
+
+	// Regress the change in spread on the previous spread (no constant)
+	sumLagSq, sumLagDelta := 0.0, 0.0
+	for i := 1; i < len(spreads); i++ {
+		sumLagSq += spreads[i-1] * spreads[i-1]
+		sumLagDelta += spreads[i-1] * (spreads[i] - spreads[i-1])
+	}
+	if sumLagSq == 0 {
+		return 0
+	}
+	gamma := sumLagDelta / sumLagSq
+
+	sumResidualSq := 0.0
+	for i := 1; i < len(spreads); i++ {
+		residual := spreads[i] - spreads[i-1] - gamma*spreads[i-1]
+		sumResidualSq += residual * residual
+	}
+	variance := sumResidualSq / float64(len(spreads)-2)
+	if variance == 0 {
+		return math.Inf(-1)
+	}
+	return gamma / math.Sqrt(variance/sumLagSq)
+}
+
+// Ready returns true once period pairs have been added
+func (h *HedgeRatio) Ready() bool {
+	return h.window.ready()
+}
+
+// WarmupPeriod returns the number of pairs needed before the hedge ratio is ready
+func (h *HedgeRatio) WarmupPeriod() int {
+	return h.window.period
+}
+
+// Reset clears all state
+func (h *HedgeRatio) Reset() {
+	h.window.reset()
+}
+
+// coefficients returns the regression slope and intercept
+func (h *HedgeRatio) coefficients() (float64, float64) {
+	if !h.Ready() {
+		return 0, 0
+	}
+	meanX, meanY, varX, _, cov := h.window.moments()
+	if varX == 0 {
+		return 0, meanY
+	}
+	slope := cov / varX
+	return slope, meanY - slope*meanX
+}
+
+// spreads returns the residuals of the pairs in the window, oldest first
+func (h *HedgeRatio) spreads() []float64 {
+	slope, intercept := h.coefficients()
+	spreads := make([]float64, h.window.period)
+	for i := range spreads {
+		spreads[i] = h.window.y.At(i) - intercept - slope*h.window.x.At(i)
+	}
+	return spreads
+}
+
+// Verify that the pair indicators implement the PairIndicator interface
+var (
+	_ PairIndicator = (*Correlation)(nil)
+	_ PairIndicator = (*Beta)(nil)
+	_ PairIndicator = (*HedgeRatio)(nil)
+)
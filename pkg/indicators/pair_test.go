@@ -0,0 +1,114 @@
+package indicators
+
+import (
+	"math"
+	"testing"
+)
+
+// referencePairTail feeds (close, open) pairs of the reference bars, or their returns,
+// to a pair indicator and returns its value after each of the last n pairs
+func referencePairTail(t *testing.T, indicator PairIndicator, useReturns bool, n int) []float64 {
+	t.Helper()
+	bars := referenceBars()
+	var values []float64
+	pairs := 0
+	for i, bar := range bars {
+		x, y := bar.Close, bar.Open
+		if useReturns {
+			if i == 0 {
+				continue
+			}
+			x = bar.Close/bars[i-1].Close - 1
+			y = bar.Open/bars[i-1].Open - 1
+		}
+		indicator.AddPair(x, y)
+		pairs++
+		if want := pairs >= indicator.WarmupPeriod(); indicator.Ready() != want {
+			t.Fatalf("Ready() = %v after %d pairs with warmup %d", indicator.Ready(), pairs, indicator.WarmupPeriod())
+		}
+		if i >= len(bars)-n {
+			values = append(values, indicator.Value())
+		}
+	}
+	return values
+}
+
+func TestCorrelationMatchesTALib(t *testing.T) {
+	correlation := NewCorrelation(10)
+	got := referencePairTail(t, correlation, false, 5)
+	assertValues(t, "Correlation(10)", got, []float64{0.920737, 0.938935, 0.965014, 0.967974, 0.968370})
+}
+
+func TestBetaMatchesTALib(t *testing.T) {
+	// TA-Lib's BETA converts both price series to returns itself
+	beta := NewBeta(10)
+	got := referencePairTail(t, beta, true, 5)
+	assertValues(t, "Beta(10)", got, []float64{0.666747, 0.663679, 0.773725, 0.207292, 0.202744})
+}
+
+func TestHedgeRatioMatchesTALib(t *testing.T) {
+	// References are TA-Lib's CORREL scaled by the ratio of STDDEVs, with the
+	// intercept from the two SMAs
+	hedgeRatio := NewHedgeRatio(10)
+	got := referencePairTail(t, hedgeRatio, false, 5)
+	assertValues(t, "HedgeRatio(10)", got, []float64{0.916780, 0.908598, 0.958587, 0.977576, 0.930409})
+	assertValues(t, "Intercept", []float64{hedgeRatio.Intercept()}, []float64{2.785685})
+
+	// Spread statistics of the last window, from a two-pass least squares fit
+	assertValues(t, "Spread", []float64{hedgeRatio.Spread()}, []float64{0.184267})
+	assertValues(t, "SpreadZScore", []float64{hedgeRatio.SpreadZScore()}, []float64{0.346086})
+	assertValues(t, "CointegrationStatistic", []float64{hedgeRatio.CointegrationStatistic()}, []float64{-3.010069})
+}
+
+func TestHedgeRatioExactFit(t *testing.T) {
+	hedgeRatio := NewHedgeRatio(4)
+	correlation := NewCorrelation(4)
+	for _, x := range []float64{1, 2, 4, 3, 5} {
+		hedgeRatio.AddPair(x, 2*x+1)
+		correlation.AddPair(x, 2*x+1)
+	}
+	if hedgeRatio.Value() != 2 || hedgeRatio.Intercept() != 1 {
+		t.Errorf("fit = %v x + %v, want 2 x + 1", hedgeRatio.Value(), hedgeRatio.Intercept())
+	}
+	if hedgeRatio.Spread() != 0 || hedgeRatio.SpreadZScore() != 0 {
+		t.Errorf("spread = %v (z %v), want 0 for an exact fit", hedgeRatio.Spread(), hedgeRatio.SpreadZScore())
+	}
+	if got := correlation.Value(); got != 1 {
+		t.Errorf("correlation = %v, want 1", got)
+	}
+
+	// A flat x has no hedge ratio; the intercept falls back to the mean of y
+	flat := NewHedgeRatio(3)
+	for _, y := range []float64{1, 2, 6} {
+		flat.AddPair(10, y)
+	}
+	if flat.Value() != 0 || flat.Intercept() != 3 {
+		t.Errorf("flat fit = %v x + %v, want 0 x + 3", flat.Value(), flat.Intercept())
+	}
+}
+
+func TestPairWindowKeepsPrecisionAtLargeMagnitudes(t *testing.T) {
+	// Raw sums of squares and products near 1e18 cannot resolve a spread of a few units
+	correlation := NewCorrelation(4)
+	beta := NewBeta(4)
+	for i := 0; i < 1000; i++ {
+		x := 1e9 + float64(i%4)
+		y := 1e9 - 2*float64(i%4)
+		correlation.AddPair(x, y)
+		beta.AddPair(x, y)
+	}
+	if got := correlation.Value(); math.Abs(got+1) > 1e-6 {
+		t.Errorf("correlation = %.9f, want -1", got)
+	}
+	if got := beta.Value(); math.Abs(got+2) > 1e-6 {
+		t.Errorf("beta = %.9f, want -2", got)
+	}
+
+	// A period of one has no spread, and the window empties cleanly between pairs
+	single := NewBeta(1)
+	single.AddPair(1e9, 5)
+	single.AddPair(2e9, 7)
+	if got := single.Value(); got != 0 {
+		t.Errorf("single-pair beta = %v, want 0", got)
+	}
+}
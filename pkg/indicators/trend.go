@@ -0,0 +1,339 @@
+package indicators
+
+import (
+	"math"
+
+	"github.com/ridopark/JonBuhTrader/pkg/strategy"
+)
+
+// ADX is Wilder's Average Directional Index. Value returns the ADX; PlusDI and
+// MinusDI return the directional indicators it is derived from.
+type ADX struct {
+	period    int
+	trueRange *EMA
+	plusDM    *EMA
+	minusDM   *EMA
+	average   *EMA
+	prev      strategy.BarData
+	hasPrev   bool
+	plusDI    float64
+	minusDI   float64
+}
+
+// NewADX creates an Average Directional Index over period bars
+func NewADX(period int) *ADX {
+	period = validPeriod(period)
+	return &ADX{
+		period:    period,
+		trueRange: NewWilderMA(period),
+		plusDM:    NewWilderMA(period),
+		minusDM:   NewWilderMA(period),
+		average:   NewWilderMA(period),
+	}
+}
+
+// Update feeds the next bar
+func (a *ADX) Update(bar strategy.BarData) {
+	if !a.hasPrev {
+		a.prev = bar
+		a.hasPrev = true
+		return
+	}
+
+	tr := math.Max(bar.High-bar.Low, math.Max(math.Abs(bar.High-a.prev.Close), math.Abs(bar.Low-a.prev.Close)))
+	upMove := bar.High - a.prev.High
+	downMove := a.prev.Low - bar.Low
+
+	plusDM := 0.0
+	minusDM := 0.0
+	if upMove > downMove && upMove > 0 {
+		plusDM = upMove
+	}
+	if downMove > upMove && downMove > 0 {
+		minusDM = downMove
+	}
+
+	a.trueRange.Add(tr)
+	a.plusDM.Add(plusDM)
+	a.minusDM.Add(minusDM)
+	a.prev = bar
+
+	if !a.trueRange.Ready() {
+		return
+	}
+
+	a.plusDI = 0
+	a.minusDI = 0
+	if atr := a.trueRange.Value(); atr > 0 {
+		a.plusDI = 100 * a.plusDM.Value() / atr
+		a.minusDI = 100 * a.minusDM.Value() / atr
+	}
+
+	dx := 0.0
+	if sum := a.plusDI + a.minusDI; sum > 0 {
+		dx = 100 * math.Abs(a.plusDI-a.minusDI) / sum
+	}
+	a.average.Add(dx)
+}
+
+// Value returns the ADX between 0 and 100
+func (a *ADX) Value() float64 {
+	return a.average.Value()
+}
+
+// PlusDI returns the positive directional indicator
+func (a *ADX) PlusDI() float64 {
+	return a.plusDI
+}
+
+// MinusDI returns the negative directional indicator
+func (a *ADX) MinusDI() float64 {
+	return a.minusDI
+}
+
+// Ready returns true once period DX values have been averaged
+func (a *ADX) Ready() bool {
+	return a.average.Ready()
+}
+
+// WarmupPeriod returns the number of bars needed before the ADX is ready
+func (a *ADX) WarmupPeriod() int {
+	return 2 * a.period
+}
+
+// Reset clears all state
+func (a *ADX) Reset() {
+	a.trueRange.Reset()
+	a.plusDM.Reset()
+	a.minusDM.Reset()
+	a.average.Reset()
+	a.prev = strategy.BarData{}
+	a.hasPrev = false
+	a.plusDI = 0
+	a.minusDI = 0
+}
+
+// SuperTrend is an ATR-based trailing stop that flips between a lower band in
+// uptrends and an upper band in downtrends
+type SuperTrend struct {
+	multiplier  float64
+	atr         *ATR
+	upperBand   float64
+	lowerBand   float64
+	direction   int // 1 for uptrend, -1 for downtrend
+	prevClose   float64
+	initialized bool
+}
+
+// NewSuperTrend creates a SuperTrend using an ATR over period bars scaled by multiplier
+func NewSuperTrend(period int, multiplier float64) *SuperTrend {
+	return &SuperTrend{
+		multiplier: multiplier,
+		atr:        NewATR(period),
+	}
+}
+
+// Update feeds the next bar
+func (s *SuperTrend) Update(bar strategy.BarData) {
+	s.atr.Update(bar)
+	defer func() { s.prevClose = bar.Close }()
+
+	if !s.atr.Ready() {
+		return
+	}
+
+	hl2 := (bar.High + bar.Low) / 2
+	basicUpper := hl2 + s.multiplier*s.atr.Value()
+	basicLower := hl2 - s.multiplier*s.atr.Value()
+
+	if !s.initialized {
+		s.upperBand = basicUpper
+		s.lowerBand = basicLower
+		s.direction = -1
+		s.initialized = true
+		return
+	}
+
+	// Bands only tighten while the trend holds
+	upper := s.upperBand
+	if basicUpper < s.upperBand || s.prevClose > s.upperBand {
+		upper = basicUpper
+	}
+	lower := s.lowerBand
+	if basicLower > s.lowerBand || s.prevClose < s.lowerBand {
+		lower = basicLower
+	}
+
+	if s.direction < 0 {
+		if bar.Close > upper {
+			s.direction = 1
+		}
+	} else if bar.Close < lower {
+		s.direction = -1
+	}
+
+	s.upperBand = upper
+	s.lowerBand = lower
+}
+
+// Value returns the SuperTrend line: the lower band in uptrends, the upper band in downtrends
+func (s *SuperTrend) Value() float64 {
+	if !s.initialized {
+		return 0
+	}
+	if s.direction > 0 {
+		return s.lowerBand
+	}
+	return s.upperBand
+}
+
+// Direction returns 1 in an uptrend, -1 in a downtrend and 0 before the indicator is ready
+func (s *SuperTrend) Direction() int {
+	return s.direction
+}
+
+// UpperBand returns the final upper band
+func (s *SuperTrend) UpperBand() float64 {
+	return s.upperBand
+}
+
+// LowerBand returns the final lower band
+func (s *SuperTrend) LowerBand() float64 {
+	return s.lowerBand
+}
+
+// Ready returns true once the ATR is ready
+func (s *SuperTrend) Ready() bool {
+	return s.initialized
+}
+
+// WarmupPeriod returns the number of bars needed before the SuperTrend is ready
+func (s *SuperTrend) WarmupPeriod() int {
+	return s.atr.WarmupPeriod()
+}
+
+// Reset clears all state
+func (s *SuperTrend) Reset() {
+	s.atr.Reset()
+	s.upperBand = 0
+	s.lowerBand = 0
+	s.direction = 0
+	s.prevClose = 0
+	s.initialized = false
+}
+
+// ParabolicSAR is Wilder's Parabolic Stop and Reverse
+type ParabolicSAR struct {
+	step        float64
+	maxStep     float64
+	count       int
+	long        bool
+	sar         float64
+	extreme     float64
+	accel       float64
+	prevHigh    float64
+	prevLow     float64
+	prev2High   float64
+	prev2Low    float64
+	initialized bool
+}
+
+// NewParabolicSAR creates a Parabolic SAR whose acceleration factor starts at
+// step, increases by step on each new extreme and is capped at maxStep
+func NewParabolicSAR(step, maxStep float64) *ParabolicSAR {
+	return &ParabolicSAR{step: step, maxStep: maxStep}
+}
+
+// Update feeds the next bar
+func (p *ParabolicSAR) Update(bar strategy.BarData) {
+	p.count++
+	defer func() {
+		p.prev2High, p.prev2Low = p.prevHigh, p.prevLow
+		p.prevHigh, p.prevLow = bar.High, bar.Low
+	}()
+
+	if p.count == 1 {
+		return
+	}
+
+	if !p.initialized {
+		// Start in the direction of the dominant directional movement
+		upMove := bar.High - p.prevHigh
+		downMove := p.prevLow - bar.Low
+		p.long = !(downMove > 0 && downMove > upMove)
+		if p.long {
+			p.sar = p.prevLow
+			p.extreme = bar.High
+		} else {
+			p.sar = p.prevHigh
+			p.extreme = bar.Low
+		}
+		p.accel = p.step
+		p.initialized = true
+		// As in TA-Lib, the first move is bounded by this bar alone
+		p.prevHigh, p.prevLow = bar.High, bar.Low
+		return
+	}
+
+	sar := p.sar + p.accel*(p.extreme-p.sar)
+
+	if p.long {
+		// SAR may not move above the prior two lows
+		sar = math.Min(sar, math.Min(p.prevLow, p.prev2Low))
+		if bar.Low < sar {
+			p.long = false
+			sar = p.extreme
+			p.extreme = bar.Low
+			p.accel = p.step
+		} else if bar.High > p.extreme {
+			p.extreme = bar.High
+			p.accel = math.Min(p.accel+p.step, p.maxStep)
+		}
+	} else {
+		// SAR may not move below the prior two highs
+		sar = math.Max(sar, math.Max(p.prevHigh, p.prev2High))
+		if bar.High > sar {
+			p.long = true
+			sar = p.extreme
+			p.extreme = bar.High
+			p.accel = p.step
+		} else if bar.Low < p.extreme {
+			p.extreme = bar.Low
+			p.accel = math.Min(p.accel+p.step, p.maxStep)
+		}
+	}
+
+	p.sar = sar
+}
+
+// Value returns the stop and reverse level for the current bar
+func (p *ParabolicSAR) Value() float64 {
+	return p.sar
+}
+
+// IsLong returns true while the SAR is below price (uptrend)
+func (p *ParabolicSAR) IsLong() bool {
+	return p.long
+}
+
+// Ready returns true once two bars have been seen
+func (p *ParabolicSAR) Ready() bool {
+	return p.initialized
+}
+
+// WarmupPeriod returns the number of bars needed before the SAR is ready
+func (p *ParabolicSAR) WarmupPeriod() int {
+	return 2
+}
+
+// Reset clears all state
+func (p *ParabolicSAR) Reset() {
+	*p = ParabolicSAR{step: p.step, maxStep: p.maxStep}
+}
+
+// Verify that the trend indicators implement the Indicator interface
+var (
+	_ Indicator = (*ADX)(nil)
+	_ Indicator = (*SuperTrend)(nil)
+	_ Indicator = (*ParabolicSAR)(nil)
+)
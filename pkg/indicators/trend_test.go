@@ -0,0 +1,64 @@
+package indicators
+
+import "testing"
+
+func TestADXMatchesTALib(t *testing.T) {
+	// TA-Lib seeds its Wilder sums with period-1 values rather than Wilder's period,
+	// so the comparison runs over a series long enough for that seed to decay
+	tests := []struct {
+		name   string
+		output func(adx *ADX) func() float64
+		want   []float64
+	}{
+		{"ADX(14)", func(adx *ADX) func() float64 { return adx.Value }, []float64{37.937306, 36.972306, 36.942412, 37.420308, 38.104960}},
+		{"+DI(14)", func(adx *ADX) func() float64 { return adx.PlusDI }, []float64{16.879968, 15.486300, 13.861122, 12.557513, 11.725105}},
+		{"-DI(14)", func(adx *ADX) func() float64 { return adx.MinusDI }, []float64{23.230839, 25.497544, 29.832975, 31.998708, 32.525108}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adx := NewADX(14)
+			assertValues(t, tt.name, barsTail(t, longBars(), adx, tt.output(adx), 5), tt.want)
+		})
+	}
+}
+
+func TestSuperTrendMatchesTALibATR(t *testing.T) {
+	// TA-Lib has no SuperTrend; references apply the standard band rules to its ATR(3)
+	// and MEDPRICE. The series starts down, ratchets the upper band and flips up once.
+	supertrend := NewSuperTrend(3, 2)
+	got := referenceTail(t, supertrend, supertrend.Value, 38)
+	assertValues(t, "SuperTrend(3, 2)", got, []float64{
+		50.986667, 49.931111, 49.154074, 47.592716, 46.676811, 46.676811, 46.676811, 46.083499, 46.083499, 46.083499,
+		46.083499, 46.083499, 46.083499, 46.083499, 46.083499, 46.083499, 46.083499, 45.731801, 45.727868, 45.496912,
+		45.009608, 45.009608, 45.009608, 41.807197, 41.807197, 41.807197, 41.807197, 41.807197, 41.807197, 42.886838,
+		42.999559, 43.444706, 44.756471, 45.650980, 45.830654, 46.973769, 47.267513, 47.791675,
+	})
+	if supertrend.Direction() != 1 || supertrend.Value() != supertrend.LowerBand() {
+		t.Errorf("direction = %d, want an uptrend on the lower band", supertrend.Direction())
+	}
+
+	// The flip happens on the 26th bar
+	flipped := NewSuperTrend(3, 2)
+	for i, bar := range referenceBars() {
+		flipped.Update(bar)
+		want := 1
+		if i < 25 {
+			want = -1
+		}
+		if flipped.Ready() && flipped.Direction() != want {
+			t.Fatalf("direction after %d bars = %d, want %d", i+1, flipped.Direction(), want)
+		}
+	}
+}
+
+func TestParabolicSARMatchesTALib(t *testing.T) {
+	// The series starts short and reverses three times
+	sar := NewParabolicSAR(0.02, 0.2)
+	got := referenceTail(t, sar, sar.Value, 39)
+	assertValues(t, "SAR(0.02, 0.2)", got, []float64{
+		50.390000, 50.343600, 50.204656, 49.903777, 49.457875, 48.839087, 48.117997, 47.483437, 46.925025, 46.242521,
+		45.549318, 44.967027, 44.477903, 41.910000, 41.986200, 42.150352, 42.307938, 42.459220, 42.604452, 46.090000,
+		46.015600, 45.855776, 45.587829, 45.335960, 41.390000, 41.487600, 41.583248, 41.676983, 41.768843, 41.858867,
+		41.947089, 42.033547, 42.236206, 42.580233, 43.101015, 43.729913, 44.436723, 45.403182, 46.503473,
+	})
+}
@@ -0,0 +1,71 @@
+package indicators
+
+import (
+	"math"
+
+	"github.com/ridopark/JonBuhTrader/pkg/strategy"
+)
+
+// TrueRange tracks the previous close needed to compute the true range of each bar
+type TrueRange struct {
+	prevClose float64
+	hasPrev   bool
+}
+
+// Next returns the true range of the bar and remembers its close. The first
+// bar's true range is its high-low range.
+func (t *TrueRange) Next(bar strategy.BarData) float64 {
+	tr := bar.High - bar.Low
+	if t.hasPrev {
+		tr = math.Max(tr, math.Max(math.Abs(bar.High-t.prevClose), math.Abs(bar.Low-t.prevClose)))
+	}
+	t.prevClose = bar.Close
+	t.hasPrev = true
+	return tr
+}
+
+// Reset clears all state
+func (t *TrueRange) Reset() {
+	t.prevClose = 0
+	t.hasPrev = false
+}
+
+// ATR is Wilder's Average True Range
+type ATR struct {
+	trueRange TrueRange
+	average   *EMA
+}
+
+// NewATR creates an Average True Range over period bars
+func NewATR(period int) *ATR {
+	return &ATR{average: NewWilderMA(period)}
+}
+
+// Update feeds the next bar
+func (a *ATR) Update(bar strategy.BarData) {
+	a.average.Add(a.trueRange.Next(bar))
+}
+
+// Value returns the average true range
+func (a *ATR) Value() float64 {
+	return a.average.Value()
+}
+
+// Ready returns true once period bars have been seen
+func (a *ATR) Ready() bool {
+	return a.average.Ready()
+}
+
+// WarmupPeriod returns the number of bars needed before the ATR is ready
+func (a *ATR) WarmupPeriod() int {
+	return a.average.WarmupPeriod()
+}
+
+// Reset clears all state
+func (a *ATR) Reset() {
+	a.trueRange.Reset()
+	a.average.Reset()
+}
+
+// Verify that ATR implements the Indicator interface
+var _ Indicator = (*ATR)(nil)
@@ -0,0 +1,28 @@
+package indicators
+
+import (
+	"math"
+	"testing"
+)
+
+func TestATRMatchesTALib(t *testing.T) {
+	atr := NewATR(14)
+	got := referenceTail(t, atr, atr.Value, 5)
+	assertValues(t, "ATR(14)", got, []float64{1.636851, 1.624219, 1.654632, 1.704301, 1.719708})
+}
+
+func TestTrueRangeUsesPreviousClose(t *testing.T) {
+	var trueRange TrueRange
+	bars := referenceBars()
+	// The first bar has no previous close, so its range is high - low
+	if got := trueRange.Next(bars[0]); math.Abs(got-1.71) > 1e-9 {
+		t.Errorf("first true range = %v, want 1.71", got)
+	}
+	// The sixth bar trades entirely below the fifth close of 44.85
+	for _, bar := range bars[1:5] {
+		trueRange.Next(bar)
+	}
+	if got := trueRange.Next(bars[5]); math.Abs(got-(44.85-43.27)) > 1e-9 {
+		t.Errorf("gap true range = %v, want %v", got, 44.85-43.27)
+	}
+}
@@ -0,0 +1,516 @@
+package indicators
+
+import (
+	"math"
+	"time"
+
+	"github.com/ridopark/JonBuhTrader/pkg/strategy"
+)
+
+// typicalPrice returns the average of a bar's high, low and close
+func typicalPrice(bar strategy.BarData) float64 {
+	return (bar.High + bar.Low + bar.Close) / 3
+}
+
+// moneyFlowMultiplier returns where the close sits in the bar's range, from -1 (low) to 1 (high)
+func moneyFlowMultiplier(bar strategy.BarData) float64 {
+	rangeSize := bar.High - bar.Low
+	if rangeSize == 0 {
+		return 0
+	}
+	return ((bar.Close - bar.Low) - (bar.High - bar.Close)) / rangeSize
+}
+
+// VWAP is the volume-weighted average price anchored to the start of each trading session
+type VWAP struct {
+	location    *time.Location
+	session     time.Time
+	priceVolume float64
+	volume      float64
+	hasSession  bool
+}
+
+// NewVWAP creates a session-anchored VWAP. Sessions start at midnight in the given location (UTC if nil).
+func NewVWAP(location *time.Location) *VWAP {
+	if location == nil {
+		location = time.UTC
+	}
+	return &VWAP{location: location}
+}
+
+// Update feeds the next bar, resetting the average when a new session starts
+func (v *VWAP) Update(bar strategy.BarData) {
+	local := bar.Timestamp.In(v.location)
+	session := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, v.location)
+	if !v.hasSession || !session.Equal(v.session) {
+		v.session = session
+		v.hasSession = true
+		v.priceVolume = 0
+		v.volume = 0
+	}
+
+	v.priceVolume += typicalPrice(bar) * bar.Volume
+	v.volume += bar.Volume
+}
+
+// Value returns the VWAP of the current session
+func (v *VWAP) Value() float64 {
+	if v.volume == 0 {
+		return 0
+	}
+	return v.priceVolume / v.volume
+}
+
+// Ready returns true once the current session has traded volume
+func (v *VWAP) Ready() bool {
+	return v.volume > 0
+}
+
+// WarmupPeriod returns the number of bars needed before the VWAP is ready
+func (v *VWAP) WarmupPeriod() int {
+	return 1
+}
+
+// Reset clears all state
+func (v *VWAP) Reset() {
+	*v = VWAP{location: v.location}
+}
+
+// RollingVWAP is the volume-weighted average price over the last period bars
+type RollingVWAP struct {
+	period      int
+	priceVolume *Window
+	volume      *Window
+	sumPV       float64
+	sumVolume   float64
+}
+
+// NewRollingVWAP creates a VWAP over a rolling window of period bars
+func NewRollingVWAP(period int) *RollingVWAP {
+	period = validPeriod(period)
+	return &RollingVWAP{
+		period:      period,
+		priceVolume: NewWindow(period),
+		volume:      NewWindow(period),
+	}
+}
+
+// Update feeds the next bar
+func (r *RollingVWAP) Update(bar strategy.BarData) {
+	pv := typicalPrice(bar) * bar.Volume
+	if evicted, full := r.priceVolume.Add(pv); full {
+		r.sumPV -= evicted
+	}
+	if evicted, full := r.volume.Add(bar.Volume); full {
+		r.sumVolume -= evicted
+	}
+	r.sumPV += pv
+	r.sumVolume += bar.Volume
+}
+
+// Value returns the VWAP over the window
+func (r *RollingVWAP) Value() float64 {
+	if !r.Ready() || r.sumVolume <= 0 {
+		return 0
+	}
+	return r.sumPV / r.sumVolume
+}
+
+// Ready returns true once period bars have been seen
+func (r *RollingVWAP) Ready() bool {
+	return r.volume.Full()
+}
+
+// WarmupPeriod returns the number of bars needed before the VWAP is ready
+func (r *RollingVWAP) WarmupPeriod() int {
+	return r.period
+}
+
+// Reset clears all state
+func (r *RollingVWAP) Reset() {
+	r.priceVolume.Reset()
+	r.volume.Reset()
+	r.sumPV = 0
+	r.sumVolume = 0
+}
+
+// OBV is On-Balance Volume, a running total of volume signed by the direction of the close.
+// Being cumulative, its level depends on where the series starts; use its changes.
+type OBV struct {
+	value     float64
+	prevClose float64
+	count     int
+}
+
+// NewOBV creates an On-Balance Volume indicator
+func NewOBV() *OBV {
+	return &OBV{}
+}
+
+// Update feeds the next bar
+func (o *OBV) Update(bar strategy.BarData) {
+	if o.count > 0 {
+		switch {
+		case bar.Close > o.prevClose:
+			o.value += bar.Volume
+		case bar.Close < o.prevClose:
+			o.value -= bar.Volume
+		}
+	}
+	o.prevClose = bar.Close
+	o.count++
+}
+
+// Value returns the running OBV total
+func (o *OBV) Value() float64 {
+	return o.value
+}
+
+// Ready returns true once a bar has been seen
+func (o *OBV) Ready() bool {
+	return o.count > 0
+}
+
+// WarmupPeriod returns the number of bars needed before the OBV is ready
+func (o *OBV) WarmupPeriod() int {
+	return 1
+}
+
+// Reset clears all state
+func (o *OBV) Reset() {
+	*o = OBV{}
+}
+
+// MFI is the Money Flow Index, a volume-weighted RSI of the typical price
+type MFI struct {
+	period      int
+	positive    *Window
+	negative    *Window
+	sumPositive float64
+	sumNegative float64
+	prevTypical float64
+	hasPrevious bool
+}
+
+// NewMFI creates a Money Flow Index over period bars
+func NewMFI(period int) *MFI {
+	period = validPeriod(period)
+	return &MFI{
+		period:   period,
+		positive: NewWindow(period),
+		negative: NewWindow(period),
+	}
+}
+
+// Update feeds the next bar
+func (m *MFI) Update(bar strategy.BarData) {
+	typical := typicalPrice(bar)
+	if !m.hasPrevious {
+		m.prevTypical = typical
+		m.hasPrevious = true
+		return
+	}
+
+	flow := typical * bar.Volume
+	positive, negative := 0.0, 0.0
+	if typical > m.prevTypical {
+		positive = flow
+	} else if typical < m.prevTypical {
+		negative = flow
+	}
+	m.prevTypical = typical
+
+	if evicted, full := m.positive.Add(positive); full {
+		m.sumPositive -= evicted
+	}
+	if evicted, full := m.negative.Add(negative); full {
+		m.sumNegative -= evicted
+	}
+	m.sumPositive += positive
+	m.sumNegative += negative
+}
+
+// Value returns the MFI between 0 and 100
+func (m *MFI) Value() float64 {
+	if !m.Ready() {
+		return 0
+	}
+	if m.sumNegative <= 0 {
+		if m.sumPositive <= 0 {
+			return 50
+		}
+		return 100
+	}
+	return 100 - 100/(1+m.sumPositive/m.sumNegative)
+}
+
+// Ready returns true once period money flows have been seen
+func (m *MFI) Ready() bool {
+	return m.positive.Full()
+}
+
+// WarmupPeriod returns the number of bars needed before the MFI is ready
+func (m *MFI) WarmupPeriod() int {
+	return m.period + 1
+}
+
+// Reset clears all state
+func (m *MFI) Reset() {
+	m.positive.Reset()
+	m.negative.Reset()
+	m.sumPositive = 0
+	m.sumNegative = 0
+	m.prevTypical = 0
+	m.hasPrevious = false
+}
+
+// AccumulationDistribution is the Accumulation/Distribution line, a running total
+// of volume weighted by where each bar closes within its range
+type AccumulationDistribution struct {
+	value float64
+	count int
+}
+
+// NewAccumulationDistribution creates an Accumulation/Distribution line
+func NewAccumulationDistribution() *AccumulationDistribution {
+	return &AccumulationDistribution{}
+}
+
+// Update feeds the next bar
+func (a *AccumulationDistribution) Update(bar strategy.BarData) {
+	a.value += moneyFlowMultiplier(bar) * bar.Volume
+	a.count++
+}
+
+// Value returns the running A/D total
+func (a *AccumulationDistribution) Value() float64 {
+	return a.value
+}
+
+// Ready returns true once a bar has been seen
+func (a *AccumulationDistribution) Ready() bool {
+	return a.count > 0
+}
+
+// WarmupPeriod returns the number of bars needed before the line is ready
+func (a *AccumulationDistribution) WarmupPeriod() int {
+	return 1
+}
+
+// Reset clears all state
+func (a *AccumulationDistribution) Reset() {
+	*a = AccumulationDistribution{}
+}
+
+// ChaikinMoneyFlow is the money flow volume over a period divided by the volume over the period
+type ChaikinMoneyFlow struct {
+	period     int
+	flowVolume *Window
+	volume     *Window
+	sumFlow    float64
+	sumVolume  float64
+}
+
+// NewChaikinMoneyFlow creates a Chaikin Money Flow over period bars
+func NewChaikinMoneyFlow(period int) *ChaikinMoneyFlow {
+	period = validPeriod(period)
+	return &ChaikinMoneyFlow{
+		period:     period,
+		flowVolume: NewWindow(period),
+		volume:     NewWindow(period),
+	}
+}
+
+// Update feeds the next bar
+func (c *ChaikinMoneyFlow) Update(bar strategy.BarData) {
+	flow := moneyFlowMultiplier(bar) * bar.Volume
+	if evicted, full := c.flowVolume.Add(flow); full {
+		c.sumFlow -= evicted
+	}
+	if evicted, full := c.volume.Add(bar.Volume); full {
+		c.sumVolume -= evicted
+	}
+	c.sumFlow += flow
+	c.sumVolume += bar.Volume
+}
+
+// Value returns the CMF between -1 and 1
+func (c *ChaikinMoneyFlow) Value() float64 {
+	if !c.Ready() || c.sumVolume <= 0 {
+		return 0
+	}
+	return c.sumFlow / c.sumVolume
+}
+
+// Ready returns true once period bars have been seen
+func (c *ChaikinMoneyFlow) Ready() bool {
+	return c.volume.Full()
+}
+
+// WarmupPeriod returns the number of bars needed before the CMF is ready
+func (c *ChaikinMoneyFlow) WarmupPeriod() int {
+	return c.period
+}
+
+// Reset clears all state
+func (c *ChaikinMoneyFlow) Reset() {
+	c.flowVolume.Reset()
+	c.volume.Reset()
+	c.sumFlow = 0
+	c.sumVolume = 0
+}
+
+// VolumeProfile distributes the volume of the last period bars across price bins to
+// find the point of control (price with the most volume) and the value area (the
+// price range holding a given share of the volume). Updates are constant time; the
+// profile is built when it is queried. Value returns the point of control.
+type VolumeProfile struct {
+	period    int
+	bins      int
+	valueArea float64
+	bars      []strategy.BarData
+	next      int
+	count     int
+
+	// Cached result of the last profile build
+	built bool
+	poc   float64
+	high  float64
+	low   float64
+}
+
+// NewVolumeProfile creates a volume profile over period bars with the given number of
+// price bins. valueArea is the share of volume in the value area (typically 0.7).
+func NewVolumeProfile(period, bins int, valueArea float64) *VolumeProfile {
+	period = validPeriod(period)
+	if valueArea <= 0 || valueArea > 1 {
+		valueArea = 0.7
+	}
+	return &VolumeProfile{
+		period:    period,
+		bins:      validPeriod(bins),
+		valueArea: valueArea,
+		bars:      make([]strategy.BarData, period),
+	}
+}
+
+// Update feeds the next bar
+func (v *VolumeProfile) Update(bar strategy.BarData) {
+	v.bars[v.next] = bar
+	v.next = (v.next + 1) % v.period
+	if v.count < v.period {
+		v.count++
+	}
+	v.built = false
+}
+
+// Value returns the point of control
+func (v *VolumeProfile) Value() float64 {
+	v.build()
+	return v.poc
+}
+
+// ValueAreaHigh returns the top of the value area
+func (v *VolumeProfile) ValueAreaHigh() float64 {
+	v.build()
+	return v.high
+}
+
+// ValueAreaLow returns the bottom of the value area
+func (v *VolumeProfile) ValueAreaLow() float64 {
+	v.build()
+	return v.low
+}
+
+// build computes the profile for the bars in the window
+func (v *VolumeProfile) build() {
+	if v.built || !v.Ready() {
+		return
+	}
+	v.built = true
+
+	low, high := math.Inf(1), math.Inf(-1)
+	for _, bar := range v.bars[:v.count] {
+		low = math.Min(low, bar.Low)
+		high = math.Max(high, bar.High)
+	}
+
+	if high <= low {
+		v.poc, v.high, v.low = low, high, low
+		return
+	}
+
+	// Spread each bar's volume evenly across the bins its range covers
+	binSize := (high - low) / float64(v.bins)
+	volumes := make([]float64, v.bins)
+	total := 0.0
+	for _, bar := range v.bars[:v.count] {
+		first := min(int((bar.Low-low)/binSize), v.bins-1)
+		last := min(int((bar.High-low)/binSize), v.bins-1)
+		share := bar.Volume / float64(last-first+1)
+		for i := first; i <= last; i++ {
+			volumes[i] += share
+		}
+		total += bar.Volume
+	}
+
+	poc := 0
+	for i, volume := range volumes {
+		if volume > volumes[poc] {
+			poc = i
+		}
+	}
+
+	// Grow the value area from the point of control toward the heavier neighbour
+	lower, upper := poc, poc
+	inArea := volumes[poc]
+	for inArea < total*v.valueArea && (lower > 0 || upper < v.bins-1) {
+		below, above := -1.0, -1.0
+		if lower > 0 {
+			below = volumes[lower-1]
+		}
+		if upper < v.bins-1 {
+			above = volumes[upper+1]
+		}
+		if above >= below {
+			upper++
+			inArea += above
+		} else {
+			lower--
+			inArea += below
+		}
+	}
+
+	v.poc = low + (float64(poc)+0.5)*binSize
+	v.low = low + float64(lower)*binSize
+	v.high = low + float64(upper+1)*binSize
+}
+
+// Ready returns true once period bars have been seen
+func (v *VolumeProfile) Ready() bool {
+	return v.count == v.period
+}
+
+// WarmupPeriod returns the number of bars needed before the profile is ready
+func (v *VolumeProfile) WarmupPeriod() int {
+	return v.period
+}
+
+// Reset clears all state
+func (v *VolumeProfile) Reset() {
+	v.next = 0
+	v.count = 0
+	v.built = false
+	v.poc, v.high, v.low = 0, 0, 0
+}
+
+// Verify that the volume indicators implement the Indicator interface
+var (
+	_ Indicator = (*VWAP)(nil)
+	_ Indicator = (*RollingVWAP)(nil)
+	_ Indicator = (*OBV)(nil)
+	_ Indicator = (*MFI)(nil)
+	_ Indicator = (*AccumulationDistribution)(nil)
+	_ Indicator = (*ChaikinMoneyFlow)(nil)
+	_ Indicator = (*VolumeProfile)(nil)
+)
@@ -0,0 +1,123 @@
+package indicators
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/ridopark/JonBuhTrader/pkg/strategy"
+)
+
+func TestOBVMatchesTALib(t *testing.T) {
+	// TA-Lib starts OBV at the first bar's volume; this OBV starts at zero
+	obv := NewOBV()
+	got := referenceTail(t, obv, obv.Value, 5)
+	assertValues(t, "OBV", got, []float64{-158000, -144000, -120000, -97000, -78000})
+}
+
+func TestMFIMatchesTALib(t *testing.T) {
+	mfi := NewMFI(14)
+	got := referenceTail(t, mfi, mfi.Value, 5)
+	assertValues(t, "MFI(14)", got, []float64{71.143769, 75.901863, 86.623588, 86.868166, 86.953028})
+}
+
+func TestAccumulationDistributionMatchesTALib(t *testing.T) {
+	ad := NewAccumulationDistribution()
+	got := referenceTail(t, ad, ad.Value, 5)
+	assertValues(t, "A/D", got, []float64{-78038.927571, -70367.694694, -63367.694694, -57788.971290, -55809.804623})
+}
+
+func TestChaikinMoneyFlowMatchesTALib(t *testing.T) {
+	// TA-Lib has no CMF; references are 20-bar changes in its A/D line over its 20-bar volume sum
+	cmf := NewChaikinMoneyFlow(20)
+	got := referenceTail(t, cmf, cmf.Value, 5)
+	assertValues(t, "CMF(20)", got, []float64{0.007491, 0.082388, 0.093669, 0.109554, 0.144787})
+}
+
+func TestRollingVWAPMatchesTALib(t *testing.T) {
+	// References are TA-Lib's 10-bar sums of typical price times volume over volume
+	vwap := NewRollingVWAP(10)
+	got := referenceTail(t, vwap, vwap.Value, 5)
+	assertValues(t, "RollingVWAP(10)", got, []float64{45.639636, 45.946992, 46.615750, 47.666685, 48.394574})
+}
+
+func TestVWAPResetsEachSession(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+
+	// Typical prices 10, 12 and 20; the third bar is 20:00 New York on March 1 but March 2 in UTC
+	bars := []strategy.BarData{
+		{Timestamp: time.Date(2024, 3, 1, 14, 30, 0, 0, time.UTC), High: 11, Low: 9, Close: 10, Volume: 100},
+		{Timestamp: time.Date(2024, 3, 1, 20, 0, 0, 0, time.UTC), High: 13, Low: 11, Close: 12, Volume: 300},
+		{Timestamp: time.Date(2024, 3, 2, 1, 0, 0, 0, time.UTC), High: 21, Low: 19, Close: 20, Volume: 100},
+	}
+
+	tests := []struct {
+		name     string
+		location *time.Location
+		want     []float64
+	}{
+		{"UTC", time.UTC, []float64{10, 11.5, 20}},
+		{"New York", newYork, []float64{10, 11.5, (1000 + 3600 + 2000) / 500.0}},
+		{"nil location defaults to UTC", nil, []float64{10, 11.5, 20}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vwap := NewVWAP(tt.location)
+			if vwap.Ready() || vwap.Value() != 0 {
+				t.Fatal("VWAP should not be ready before any volume")
+			}
+			for i, bar := range bars {
+				vwap.Update(bar)
+				if got := vwap.Value(); math.Abs(got-tt.want[i]) > 1e-9 {
+					t.Errorf("VWAP after bar %d = %v, want %v", i, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestVolumeProfile(t *testing.T) {
+	profile := NewVolumeProfile(3, 4, 0.7)
+	bars := []strategy.BarData{
+		{High: 14, Low: 10, Volume: 400}, // 100 in each of the four bins
+		{High: 11, Low: 10, Volume: 300}, // 150 in each of the two lowest bins
+		{High: 14, Low: 13, Volume: 100}, // All in the top bin
+	}
+	for _, bar := range bars[:2] {
+		profile.Update(bar)
+	}
+	if profile.Ready() || profile.Value() != 0 {
+		t.Fatal("profile should not be ready before period bars")
+	}
+	profile.Update(bars[2])
+
+	// Bins of [10,11) [11,12) [12,13) [13,14] hold 250, 250, 100 and 200. Ties resolve to
+	// the lowest bin; the value area grows upward until it holds 600 of the 800 volume.
+	if got := profile.Value(); got != 10.5 {
+		t.Errorf("point of control = %v, want 10.5", got)
+	}
+	if low, high := profile.ValueAreaLow(), profile.ValueAreaHigh(); low != 10 || high != 13 {
+		t.Errorf("value area = [%v, %v], want [10, 13]", low, high)
+	}
+
+	// Evicting the first bar and adding heavy volume at 20-22 moves the profile up;
+	// bins are now 3 wide and the top bin alone holds over 70% of the volume
+	profile.Update(strategy.BarData{High: 22, Low: 20, Volume: 1000})
+	if got := profile.Value(); got != 20.5 {
+		t.Errorf("point of control after eviction = %v, want 20.5", got)
+	}
+	if low, high := profile.ValueAreaLow(), profile.ValueAreaHigh(); low != 19 || high != 22 {
+		t.Errorf("value area after eviction = [%v, %v], want [19, 22]", low, high)
+	}
+
+	// A window without a price range collapses to a single price
+	flat := NewVolumeProfile(2, 4, 0.7)
+	flat.Update(strategy.BarData{High: 10, Low: 10, Volume: 100})
+	flat.Update(strategy.BarData{High: 10, Low: 10, Volume: 100})
+	if flat.Value() != 10 || flat.ValueAreaLow() != 10 || flat.ValueAreaHigh() != 10 {
+		t.Errorf("flat profile = %v [%v, %v], want 10 [10, 10]", flat.Value(), flat.ValueAreaLow(), flat.ValueAreaHigh())
+	}
+}
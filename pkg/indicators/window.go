@@ -0,0 +1,140 @@
+package indicators
+
+// Window is a fixed-size ring buffer holding the most recent values of a series
+type Window struct {
+	values []float64
+	start  int
+	count  int
+}
+
+// NewWindow creates a window holding up to size values
+func NewWindow(size int) *Window {
+	return &Window{values: make([]float64, validPeriod(size))}
+}
+
+// Add appends a value, returning the value it evicted and whether the window was full
+func (w *Window) Add(value float64) (float64, bool) {
+	if w.count < len(w.values) {
+		w.values[(w.start+w.count)%len(w.values)] = value
+		w.count++
+		return 0, false
+	}
+
+	evicted := w.values[w.start]
+	w.values[w.start] = value
+	w.start = (w.start + 1) % len(w.values)
+	return evicted, true
+}
+
+// At returns the i-th value, where 0 is the oldest value in the window
+func (w *Window) At(i int) float64 {
+	return w.values[(w.start+i)%len(w.values)]
+}
+
+// Last returns the most recently added value
+func (w *Window) Last() float64 {
+	if w.count == 0 {
+		return 0
+	}
+	return w.At(w.count - 1)
+}
+
+// Len returns the number of values in the window
+func (w *Window) Len() int {
+	return w.count
+}
+
+// Size returns the maximum number of values the window holds
+func (w *Window) Size() int {
+	return len(w.values)
+}
+
+// Full returns true if the window holds Size values
+func (w *Window) Full() bool {
+	return w.count == len(w.values)
+}
+
+// Values returns a copy of the window contents, oldest first
+func (w *Window) Values() []float64 {
+	values := make([]float64, w.count)
+	for i := range values {
+		values[i] = w.At(i)
+	}
+	return values
+}
+
+// Reset empties the window
+func (w *Window) Reset() {
+	w.start = 0
+	w.count = 0
+}
+
+// rollingExtreme tracks the maximum or minimum of the last period values in
+// amortized constant time using a monotonic deque
+type rollingExtreme struct {
+	period  int
+	maximum bool
+	count   int
+	indexes []int
+	values  []float64
+}
+
+// newRollingMax creates a rolling maximum over period values
+func newRollingMax(period int) *rollingExtreme {
+	return &rollingExtreme{period: validPeriod(period), maximum: true}
+}
+
+// newRollingMin creates a rolling minimum over period values
+func newRollingMin(period int) *rollingExtreme {
+	return &rollingExtreme{period: validPeriod(period)}
+}
+
+// Add feeds the next value
+func (r *rollingExtreme) Add(value float64) {
+	// Drop values that can no longer be the extreme
+	for n := len(r.values); n > 0; n = len(r.values) {
+		last := r.values[n-1]
+		if (r.maximum && last > value) || (!r.maximum && last < value) {
+			break
+		}
+		r.values = r.values[:n-1]
+		r.indexes = r.indexes[:n-1]
+	}
+	r.values = append(r.values, value)
+	r.indexes = append(r.indexes, r.count)
+	r.count++
+
+	// Drop the extreme once it leaves the window
+	if r.indexes[0] <= r.count-1-r.period {
+		r.values = r.values[1:]
+		r.indexes = r.indexes[1:]
+	}
+}
+
+// Value returns the extreme of the values in the window
+func (r *rollingExtreme) Value() float64 {
+	if len(r.values) == 0 {
+		return 0
+	}
+	return r.values[0]
+}
+
+// BarsSince returns how many values ago the extreme occurred (0 = most recent)
+func (r *rollingExtreme) BarsSince() int {
+	if len(r.indexes) == 0 {
+		return 0
+	}
+	return r.count - 1 - r.indexes[0]
+}
+
+// Ready returns true once period values have been added
+func (r *rollingExtreme) Ready() bool {
+	return r.count >= r.period
+}
+
+// Reset clears all state
+func (r *rollingExtreme) Reset() {
+	r.count = 0
+	r.indexes = r.indexes[:0]
+	r.values = r.values[:0]
+}
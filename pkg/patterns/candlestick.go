@@ -0,0 +1,251 @@
+// Package patterns detects candlestick patterns in streams of OHLC bars.
+package patterns
+
+import (
+	"math"
+	"sort"
+
+	"github.com/ridopark/JonBuhTrader/pkg/strategy"
+)
+
+// Candlestick pattern names
+const (
+	Doji               = "doji"
+	Hammer             = "hammer"
+	ShootingStar       = "shooting_star"
+	BullishEngulfing   = "bullish_engulfing"
+	BearishEngulfing   = "bearish_engulfing"
+	MorningStar        = "morning_star"
+	EveningStar        = "evening_star"
+	ThreeWhiteSoldiers = "three_white_soldiers"
+	ThreeBlackCrows    = "three_black_crows"
+	InsideBar          = "inside_bar"
+	OutsideBar         = "outside_bar"
+)
+
+// TrendLookback is the number of bars before a pattern used to judge the prior trend
+const TrendLookback = 5
+
+// MaxBars is the number of trailing bars Detect uses: the longest pattern plus the trend look-back
+const MaxBars = 3 + TrendLookback
+
+// Shape thresholds, as fractions of the bar's range or of another body
+const (
+	dojiBodyRatio      = 0.1 // Doji body is at most 10% of the range
+	shadowBodyRatio    = 2.0 // Hammer/shooting star shadow is at least twice the body
+	smallShadowRatio   = 0.1 // Hammer/shooting star opposite shadow is at most 10% of the range
+	longBodyRatio      = 0.5 // Star first bar body is at least half its range
+	starBodyRatio      = 0.3 // Star middle body is at most 30% of the first body
+	soldierShadowRatio = 0.3 // Soldier/crow closing shadow is at most 30% of the body
+	againstTrendFactor = 0.7 // Strength multiplier for reversal patterns without a prior opposing trend
+)
+
+// candle holds the derived measurements of a bar
+type candle struct {
+	open  float64
+	high  float64
+	low   float64
+	close float64
+	body  float64
+	rng   float64
+	upper float64 // Upper shadow
+	lower float64 // Lower shadow
+}
+
+// newCandle measures a bar
+func newCandle(bar strategy.BarData) candle {
+	return candle{
+		open:  bar.Open,
+		high:  bar.High,
+		low:   bar.Low,
+		close: bar.Close,
+		body:  math.Abs(bar.Close - bar.Open),
+		rng:   bar.High - bar.Low,
+		upper: bar.High - math.Max(bar.Open, bar.Close),
+		lower: math.Min(bar.Open, bar.Close) - bar.Low,
+	}
+}
+
+func (c candle) bullish() bool { return c.close > c.open }
+func (c candle) bearish() bool { return c.close < c.open }
+
+// midpoint returns the middle of the candle's body
+func (c candle) midpoint() float64 {
+	return (c.open + c.close) / 2
+}
+
+// Detect returns the patterns completed by the last bar, strongest first. Bars
+// must be in chronological order; only the last MaxBars are examined.
+func Detect(bars []strategy.BarData) []strategy.PatternSignal {
+	if len(bars) == 0 {
+		return nil
+	}
+	if len(bars) > MaxBars {
+		bars = bars[len(bars)-MaxBars:]
+	}
+
+	var signals []strategy.PatternSignal
+	add := func(pattern string, direction strategy.PatternDirection, strength float64, patternBars int) {
+		signals = append(signals, strategy.PatternSignal{
+			Pattern:   pattern,
+			Direction: direction,
+			Strength:  clamp(strength),
+			Bars:      patternBars,
+			Timestamp: bars[len(bars)-1].Timestamp,
+		})
+	}
+
+	n := len(bars)
+	last := newCandle(bars[n-1])
+	detectSingle(last, priorTrend(bars, 1), add)
+	if n >= 2 {
+		detectDouble(newCandle(bars[n-2]), last, priorTrend(bars, 2), add)
+	}
+	if n >= 3 {
+		detectTriple(newCandle(bars[n-3]), newCandle(bars[n-2]), last, priorTrend(bars, 3), add)
+	}
+
+	sort.SliceStable(signals, func(i, j int) bool {
+		return signals[i].Strength > signals[j].Strength
+	})
+	return signals
+}
+
+// detector is called for each pattern found
+type detector func(pattern string, direction strategy.PatternDirection, strength float64, patternBars int)
+
+// detectSingle finds one-bar patterns
+func detectSingle(c candle, trend int, add detector) {
+	if c.rng <= 0 {
+		return
+	}
+
+	if c.body <= dojiBodyRatio*c.rng {
+		add(Doji, strategy.PatternNeutral, 1-c.body/(dojiBodyRatio*c.rng), 1)
+	}
+
+	// Hammers and shooting stars are reversal shapes, so they need the opposing prior trend
+	if c.body > 0 && c.lower >= shadowBodyRatio*c.body && c.upper <= smallShadowRatio*c.rng && trend < 0 {
+		add(Hammer, strategy.PatternBullish, c.lower/c.rng, 1)
+	}
+	if c.body > 0 && c.upper >= shadowBodyRatio*c.body && c.lower <= smallShadowRatio*c.rng && trend > 0 {
+		add(ShootingStar, strategy.PatternBearish, c.upper/c.rng, 1)
+	}
+}
+
+// detectDouble finds two-bar patterns
+func detectDouble(prev, c candle, trend int, add detector) {
+	if prev.bearish() && c.bullish() && c.open <= prev.close && c.close >= prev.open && c.body > prev.body {
+		strength := 0.5 + 0.5*(c.body/prev.body-1)
+		add(BullishEngulfing, strategy.PatternBullish, strength*trendFactor(trend < 0), 2)
+	}
+	if prev.bullish() && c.bearish() && c.open >= prev.close && c.close <= prev.open && c.body > prev.body {
+		strength := 0.5 + 0.5*(c.body/prev.body-1)
+		add(BearishEngulfing, strategy.PatternBearish, strength*trendFactor(trend > 0), 2)
+	}
+
+	if prev.rng <= 0 {
+		return
+	}
+	if c.high < prev.high && c.low > prev.low {
+		add(InsideBar, strategy.PatternNeutral, 1-c.rng/prev.rng, 2)
+	}
+	if c.high > prev.high && c.low < prev.low {
+		direction := strategy.PatternNeutral
+		if c.bullish() {
+			direction = strategy.PatternBullish
+		} else if c.bearish() {
+			direction = strategy.PatternBearish
+		}
+		add(OutsideBar, direction, 1-prev.rng/c.rng, 2)
+	}
+}
+
+// detectTriple finds three-bar patterns
+func detectTriple(first, second, c candle, trend int, add detector) {
+	// Stars: a long body, a small body beyond its close, then a strong move back into it
+	if first.rng > 0 && first.body >= longBodyRatio*first.rng && second.body <= starBodyRatio*first.body {
+		if first.bearish() && math.Max(second.open, second.close) <= first.close && c.bullish() && c.close > first.midpoint() {
+			penetration := (c.close - first.midpoint()) / (first.open - first.midpoint())
+			add(MorningStar, strategy.PatternBullish, (0.5+0.5*clamp(penetration))*trendFactor(trend < 0), 3)
+		}
+		if first.bullish() && math.Min(second.open, second.close) >= first.close && c.bearish() && c.close < first.midpoint() {
+			penetration := (first.midpoint() - c.close) / (first.midpoint() - first.open)
+			add(EveningStar, strategy.PatternBearish, (0.5+0.5*clamp(penetration))*trendFactor(trend > 0), 3)
+		}
+	}
+
+	// Three soldiers/crows: consecutive strong bodies, each opening inside the previous body
+	candles := []candle{first, second, c}
+	if strength, ok := advancingCandles(candles, true); ok {
+		add(ThreeWhiteSoldiers, strategy.PatternBullish, strength, 3)
+	}
+	if strength, ok := advancingCandles(candles, false); ok {
+		add(ThreeBlackCrows, strategy.PatternBearish, strength, 3)
+	}
+}
+
+// advancingCandles checks for three white soldiers (up) or three black crows (down),
+// returning the average body-to-range ratio as the strength
+func advancingCandles(candles []candle, up bool) (float64, bool) {
+	total := 0.0
+	for i, c := range candles {
+		if c.rng <= 0 || (up && !c.bullish()) || (!up && !c.bearish()) {
+			return 0, false
+		}
+
+		// The shadow beyond the close must be short
+		closingShadow := c.upper
+		if !up {
+			closingShadow = c.lower
+		}
+		if closingShadow > soldierShadowRatio*c.body {
+			return 0, false
+		}
+
+		if i > 0 {
+			prev := candles[i-1]
+			lowBody, highBody := math.Min(prev.open, prev.close), math.Max(prev.open, prev.close)
+			if c.open < lowBody || c.open > highBody {
+				return 0, false
+			}
+			if (up && c.close <= prev.close) || (!up && c.close >= prev.close) {
+				return 0, false
+			}
+		}
+		total += c.body / c.rng
+	}
+	return total / float64(len(candles)), true
+}
+
+// priorTrend returns the direction of the TrendLookback bars before the last patternBars
+// bars: 1 for rising, -1 for falling, 0 if flat or there is not enough history
+func priorTrend(bars []strategy.BarData, patternBars int) int {
+	end := len(bars) - patternBars - 1
+	start := end - TrendLookback + 1
+	if start < 0 {
+		return 0
+	}
+
+	change := bars[end].Close - bars[start].Close
+	switch {
+	case change > 0:
+		return 1
+	case change < 0:
+		return -1
+	}
+	return 0
+}
+
+// trendFactor discounts reversal patterns that do not follow an opposing trend
+func trendFactor(confirmed bool) float64 {
+	if confirmed {
+		return 1
+	}
+	return againstTrendFactor
+}
+
+// clamp limits a strength score to [0, 1]
+func clamp(value float64) float64 {
+	return math.Max(0, math.Min(1, value))
+}
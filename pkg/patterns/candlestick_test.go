@@ -0,0 +1,205 @@
+package patterns
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/ridopark/JonBuhTrader/pkg/strategy"
+)
+
+// ohlc is an open, high, low and close
+type ohlc [4]float64
+
+// hourlyBars builds one bar per hour from OHLC rows
+func hourlyBars(rows ...ohlc) []strategy.BarData {
+	start := time.Date(2024, 3, 1, 14, 0, 0, 0, time.UTC)
+	bars := make([]strategy.BarData, len(rows))
+	for i, row := range rows {
+		bars[i] = strategy.BarData{
+			Symbol:    "TEST",
+			Timestamp: start.Add(time.Duration(i) * time.Hour),
+			Open:      row[0],
+			High:      row[1],
+			Low:       row[2],
+			Close:     row[3],
+		}
+	}
+	return bars
+}
+
+// falling and rising are five-bar trends ending at a close of 102 and 98
+var (
+	falling = []ohlc{{111, 111.2, 109.8, 110}, {109, 109.2, 107.8, 108}, {107, 107.2, 105.8, 106}, {105, 105.2, 103.8, 104}, {103, 103.2, 101.8, 102}}
+	rising  = []ohlc{{89, 90.2, 88.8, 90}, {91, 92.2, 90.8, 92}, {93, 94.2, 92.8, 94}, {95, 96.2, 94.8, 96}, {97, 98.2, 96.8, 98}}
+)
+
+// after appends pattern rows to a trend
+func after(trend []ohlc, rows ...ohlc) []ohlc {
+	return append(append([]ohlc{}, trend...), rows...)
+}
+
+func TestDetect(t *testing.T) {
+	hammer := ohlc{100, 100.55, 98, 100.5}
+	shootingStar := ohlc{100, 102, 99.45, 99.5}
+	bullishEngulfing := []ohlc{{101, 101.2, 99.8, 100}, {99.8, 101.6, 99.7, 101.5}}
+	bearishEngulfing := []ohlc{{100, 101.2, 99.8, 101}, {101.2, 101.3, 99.4, 99.5}}
+	morningStar := []ohlc{{110, 110.5, 99.5, 100}, {99, 99.5, 98, 98.5}, {99, 107.5, 98.8, 107}}
+	eveningStar := []ohlc{{100, 110.5, 99.5, 110}, {111, 112, 110.5, 111.5}, {111, 111.2, 102.5, 103}}
+	// The outside bar in both engulfing patterns covers a 1.4 range with a 1.9 range
+	outside := 1 - 1.4/1.9
+
+	tests := []struct {
+		name string
+		rows []ohlc
+		want []strategy.PatternSignal
+	}{
+		{
+			name: "doji",
+			rows: []ohlc{{10, 11, 9, 10.05}},
+			want: []strategy.PatternSignal{{Pattern: Doji, Direction: strategy.PatternNeutral, Strength: 0.75, Bars: 1}},
+		},
+		{name: "zero range", rows: []ohlc{{10, 10, 10, 10}}},
+		{
+			name: "hammer after a fall",
+			rows: after(falling, hammer),
+			want: []strategy.PatternSignal{{Pattern: Hammer, Direction: strategy.PatternBullish, Strength: 2 / 2.55, Bars: 1}},
+		},
+		{name: "hammer without a prior trend", rows: []ohlc{hammer}},
+		{name: "hammer after a rise", rows: after(rising, hammer)},
+		{
+			name: "shooting star after a rise",
+			rows: after(rising, shootingStar),
+			want: []strategy.PatternSignal{{Pattern: ShootingStar, Direction: strategy.PatternBearish, Strength: 2 / 2.55, Bars: 1}},
+		},
+		{name: "shooting star after a fall", rows: after(falling, shootingStar)},
+		{
+			name: "bullish engulfing after a fall",
+			rows: after(falling, bullishEngulfing...),
+			want: []strategy.PatternSignal{
+				{Pattern: BullishEngulfing, Direction: strategy.PatternBullish, Strength: 0.85, Bars: 2},
+				{Pattern: OutsideBar, Direction: strategy.PatternBullish, Strength: outside, Bars: 2},
+			},
+		},
+		{
+			name: "bullish engulfing without a prior trend",
+			rows: bullishEngulfing,
+			want: []strategy.PatternSignal{
+				{Pattern: BullishEngulfing, Direction: strategy.PatternBullish, Strength: 0.85 * againstTrendFactor, Bars: 2},
+				{Pattern: OutsideBar, Direction: strategy.PatternBullish, Strength: outside, Bars: 2},
+			},
+		},
+		{
+			name: "bearish engulfing after a rise",
+			rows: after(rising, bearishEngulfing...),
+			want: []strategy.PatternSignal{
+				{Pattern: BearishEngulfing, Direction: strategy.PatternBearish, Strength: 0.85, Bars: 2},
+				{Pattern: OutsideBar, Direction: strategy.PatternBearish, Strength: outside, Bars: 2},
+			},
+		},
+		{
+			name: "inside bar",
+			rows: []ohlc{{100, 102, 99, 101}, {100.2, 101, 100, 100.8}},
+			want: []strategy.PatternSignal{{Pattern: InsideBar, Direction: strategy.PatternNeutral, Strength: 1 - 1.0/3, Bars: 2}},
+		},
+		// A bar without a range cannot contain or be engulfed by the next bar
+		{name: "after a zero range bar", rows: []ohlc{{100, 100, 100, 100}, {99.5, 101, 99, 100.5}}},
+		{
+			// The close is 40% of the way from the first body's midpoint back to its open
+			name: "morning star after a fall",
+			rows: after(falling, morningStar...),
+			want: []strategy.PatternSignal{{Pattern: MorningStar, Direction: strategy.PatternBullish, Strength: 0.7, Bars: 3}},
+		},
+		{
+			name: "morning star without a prior trend",
+			rows: morningStar,
+			want: []strategy.PatternSignal{{Pattern: MorningStar, Direction: strategy.PatternBullish, Strength: 0.7 * againstTrendFactor, Bars: 3}},
+		},
+		{
+			name: "evening star after a rise",
+			rows: after(rising, eveningStar...),
+			want: []strategy.PatternSignal{{Pattern: EveningStar, Direction: strategy.PatternBearish, Strength: 0.7, Bars: 3}},
+		},
+		{
+			name: "three white soldiers",
+			rows: []ohlc{{100, 102.2, 99.8, 102}, {101, 103.6, 100.9, 103.5}, {102.5, 105.2, 102.4, 105}},
+			want: []strategy.PatternSignal{{Pattern: ThreeWhiteSoldiers, Direction: strategy.PatternBullish, Strength: (2/2.4 + 2.5/2.7 + 2.5/2.8) / 3, Bars: 3}},
+		},
+		{
+			name: "three black crows",
+			rows: []ohlc{{105, 105.2, 102.8, 103}, {104, 104.1, 101.4, 101.5}, {102.5, 102.6, 99.8, 100}},
+			want: []strategy.PatternSignal{{Pattern: ThreeBlackCrows, Direction: strategy.PatternBearish, Strength: (2/2.4 + 2.5/2.7 + 2.5/2.8) / 3, Bars: 3}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bars := hourlyBars(tt.rows...)
+			got := Detect(bars)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Detect = %+v, want %+v", got, tt.want)
+			}
+			for i, want := range tt.want {
+				signal := got[i]
+				if signal.Pattern != want.Pattern || signal.Direction != want.Direction || signal.Bars != want.Bars || math.Abs(signal.Strength-want.Strength) > 1e-9 {
+					t.Errorf("signal %d = %+v, want %+v", i, signal, want)
+				}
+				if !signal.Timestamp.Equal(bars[len(bars)-1].Timestamp) {
+					t.Errorf("signal %d timestamp = %v, want the last bar's", i, signal.Timestamp)
+				}
+			}
+		})
+	}
+
+	if got := Detect(nil); got != nil {
+		t.Errorf("Detect(nil) = %+v, want nil", got)
+	}
+}
+
+func TestAdvancingCandlesRejects(t *testing.T) {
+	soldiers := []ohlc{{100, 102.2, 99.8, 102}, {101, 103.6, 100.9, 103.5}, {102.5, 105.2, 102.4, 105}}
+	tests := []struct {
+		name  string
+		third ohlc
+	}{
+		{"long closing shadow", ohlc{102.5, 106, 102.4, 105}},
+		{"opening above the previous body", ohlc{104, 105.2, 103.9, 105}},
+		{"closing below the previous close", ohlc{102.5, 103.4, 102.4, 103.3}},
+		{"bearish candle", ohlc{103, 103.2, 101, 102}},
+		{"zero range", ohlc{103, 103, 103, 103}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			candles := make([]candle, 0, 3)
+			for _, bar := range hourlyBars(soldiers[0], soldiers[1], tt.third) {
+				candles = append(candles, newCandle(bar))
+			}
+			if strength, ok := advancingCandles(candles, true); ok {
+				t.Errorf("advancingCandles = %v, want no soldiers", strength)
+			}
+		})
+	}
+}
+
+func TestPriorTrend(t *testing.T) {
+	tests := []struct {
+		name        string
+		rows        []ohlc
+		patternBars int
+		want        int
+	}{
+		{"rising", after(rising, ohlc{}), 1, 1},
+		{"falling", after(falling, ohlc{}, ohlc{}), 2, -1},
+		{"flat", []ohlc{{0, 0, 0, 5}, {0, 0, 0, 7}, {0, 0, 0, 3}, {0, 0, 0, 4}, {0, 0, 0, 5}, {}}, 1, 0},
+		// Only the TrendLookback bars right before the pattern count
+		{"ignores older bars", append([]ohlc{{0, 0, 0, 200}}, after(rising, ohlc{})...), 1, 1},
+		{"short history", after(rising[1:], ohlc{}), 1, 0},
+		{"short history for a longer pattern", after(rising, ohlc{}, ohlc{}, ohlc{}), 4, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := priorTrend(hourlyBars(tt.rows...), tt.patternBars); got != tt.want {
+				t.Errorf("priorTrend = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
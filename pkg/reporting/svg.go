@@ -0,0 +1,386 @@
+package reporting
+
+import (
+	"fmt"
+	"html"
+	"html/template"
+	"math"
+	"strings"
+	"time"
+)
+
+// Chart layout in SVG user units
+const (
+	chartWidth   = 960
+	marginLeft   = 70
+	marginRight  = 20
+	marginTop    = 24
+	marginBottom = 30
+	maxPoints    = 1500 // Series longer than this are downsampled before drawing
+)
+
+// Chart colors
+const (
+	colorStrategy  = "#1f77b4"
+	colorBenchmark = "#7f7f7f"
+	colorDrawdown  = "#d62728"
+	colorBuy       = "#2ca02c"
+	colorSell      = "#d62728"
+	colorGrid      = "#e5e5e5"
+	colorAxis      = "#555555"
+)
+
+// rollingColors are the line colors of successive rolling windows
+var rollingColors = []string{colorStrategy, "#ff7f0e", "#9467bd", "#8c564b"}
+
+// point is a chart point; x is a Unix timestamp in seconds
+type point struct {
+	x, y float64
+}
+
+// series is a line drawn on a chart
+type series struct {
+	name   string
+	color  string
+	points []point
+	fill   bool // Fill the area between the line and zero
+}
+
+// marker is a trade drawn on a chart
+type marker struct {
+	x, y  float64
+	color string
+	up    bool   // Triangle points up (buy) or down (sell)
+	title string // Tooltip
+}
+
+// lineChart draws time series against a time axis
+type lineChart struct {
+	height  int
+	series  []series
+	markers []marker
+	yFormat func(float64) string
+}
+
+// svg renders the chart
+func (c lineChart) svg() template.HTML {
+	xMin, xMax := math.Inf(1), math.Inf(-1)
+	yMin, yMax := math.Inf(1), math.Inf(-1)
+	for _, s := range c.series {
+		for _, p := range s.points {
+			xMin, xMax = math.Min(xMin, p.x), math.Max(xMax, p.x)
+			yMin, yMax = math.Min(yMin, p.y), math.Max(yMax, p.y)
+		}
+		if s.fill {
+			yMin, yMax = math.Min(yMin, 0), math.Max(yMax, 0)
+		}
+	}
+	if math.IsInf(xMin, 1) {
+		return emptyChart(c.height)
+	}
+	for _, m := range c.markers {
+		yMin, yMax = math.Min(yMin, m.y), math.Max(yMax, m.y)
+	}
+
+	ticks := niceTicks(yMin, yMax, 5)
+	yMin, yMax = math.Min(yMin, ticks[0]), math.Max(yMax, ticks[len(ticks)-1])
+	area := newPlotArea(c.height, xMin, xMax, yMin, yMax)
+
+	var b strings.Builder
+	openSVG(&b, chartWidth, c.height)
+	for _, tick := range ticks {
+		y := area.py(tick)
+		fmt.Fprintf(&b, `<line x1="%d" y1="%.1f" x2="%d" y2="%.1f" stroke="%s"/>`, marginLeft, y, chartWidth-marginRight, y, colorGrid)
+		fmt.Fprintf(&b, `<text x="%d" y="%.1f" text-anchor="end" dominant-baseline="middle">%s</text>`, marginLeft-6, y, html.EscapeString(c.yFormat(tick)))
+	}
+	area.timeAxis(&b)
+
+	for _, s := range c.series {
+		points := downsample(s.points, maxPoints)
+		if len(points) == 0 {
+			continue
+		}
+		var path strings.Builder
+		for i, p := range points {
+			command := "L"
+			if i == 0 {
+				command = "M"
+			}
+			fmt.Fprintf(&path, "%s%.1f,%.1f", command, area.px(p.x), area.py(p.y))
+		}
+		if s.fill {
+			zero := area.py(0)
+			fmt.Fprintf(&b, `<path d="%sL%.1f,%.1fL%.1f,%.1fZ" fill="%s" fill-opacity="0.3" stroke="none"/>`,
+				path.String(), area.px(points[len(points)-1].x), zero, area.px(points[0].x), zero, s.color)
+		}
+		fmt.Fprintf(&b, `<path d="%s" fill="none" stroke="%s" stroke-width="1.5"/>`, path.String(), s.color)
+	}
+
+	for _, m := range c.markers {
+		x, y := area.px(m.x), area.py(m.y)
+		tip := 6.0
+		if !m.up {
+			tip = -6
+		}
+		fmt.Fprintf(&b, `<path d="M%.1f,%.1fL%.1f,%.1fL%.1f,%.1fZ" fill="%s"><title>%s</title></path>`,
+			x, y, x-5, y+tip*1.6, x+5, y+tip*1.6, m.color, html.EscapeString(m.title))
+	}
+
+	legendX := marginLeft + 10
+	for _, s := range c.series {
+		if s.name == "" {
+			continue
+		}
+		fmt.Fprintf(&b, `<rect x="%d" y="%d" width="12" height="3" fill="%s"/>`, legendX, marginTop-12, s.color)
+		fmt.Fprintf(&b, `<text x="%d" y="%d" dominant-baseline="middle">%s</text>`, legendX+16, marginTop-10, html.EscapeString(s.name))
+		legendX += 30 + 7*len(s.name)
+	}
+
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String())
+}
+
+// histogram draws the distribution of values in equal-width bins
+func histogram(values []float64, bins, height int, xFormat func(float64) string) template.HTML {
+	if len(values) == 0 {
+		return emptyChart(height)
+	}
+
+	lo, hi := values[0], values[0]
+	for _, v := range values {
+		lo, hi = math.Min(lo, v), math.Max(hi, v)
+	}
+	if hi == lo {
+		lo, hi = lo-0.5, hi+0.5
+	}
+	width := (hi - lo) / float64(bins)
+	counts := make([]int, bins)
+	maxCount := 0
+	for _, v := range values {
+		i := int((v - lo) / width)
+		if i >= bins {
+			i = bins - 1
+		}
+		counts[i]++
+		if counts[i] > maxCount {
+			maxCount = counts[i]
+		}
+	}
+
+	area := newPlotArea(height, lo, hi, 0, float64(maxCount))
+	var b strings.Builder
+	openSVG(&b, chartWidth, height)
+	for _, tick := range niceTicks(0, float64(maxCount), 4) {
+		if tick > float64(maxCount) {
+			continue
+		}
+		y := area.py(tick)
+		fmt.Fprintf(&b, `<line x1="%d" y1="%.1f" x2="%d" y2="%.1f" stroke="%s"/>`, marginLeft, y, chartWidth-marginRight, y, colorGrid)
+		fmt.Fprintf(&b, `<text x="%d" y="%.1f" text-anchor="end" dominant-baseline="middle">%g</text>`, marginLeft-6, y, tick)
+	}
+	for i, count := range counts {
+		left := lo + float64(i)*width
+		color := colorBuy
+		if left+width/2 < 0 {
+			color = colorSell
+		}
+		x0, x1 := area.px(left), area.px(left+width)
+		y := area.py(float64(count))
+		fmt.Fprintf(&b, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s" fill-opacity="0.7"><title>%s to %s: %d</title></rect>`,
+			x0+0.5, y, math.Max(x1-x0-1, 0.5), area.py(0)-y, color, html.EscapeString(xFormat(left)), html.EscapeString(xFormat(left+width)), count)
+	}
+	for _, tick := range niceTicks(lo, hi, 8) {
+		if tick < lo || tick > hi {
+			continue
+		}
+		fmt.Fprintf(&b, `<text x="%.1f" y="%d" text-anchor="middle">%s</text>`, area.px(tick), height-marginBottom+16, html.EscapeString(xFormat(tick)))
+	}
+	if lo < 0 && hi > 0 {
+		fmt.Fprintf(&b, `<line x1="%.1f" y1="%d" x2="%.1f" y2="%d" stroke="%s" stroke-dasharray="4,3"/>`, area.px(0), marginTop, area.px(0), height-marginBottom, colorAxis)
+	}
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String())
+}
+
+// heatmap draws monthly returns as a year-by-month grid with a yearly total column
+func heatmap(years []yearReturns) template.HTML {
+	const (
+		cellWidth  = 62
+		cellHeight = 26
+		labelWidth = 60
+	)
+	if len(years) == 0 {
+		return emptyChart(80)
+	}
+
+	maxAbs := 0.0
+	for _, year := range years {
+		for _, r := range year.months {
+			if r != nil {
+				maxAbs = math.Max(maxAbs, math.Abs(*r))
+			}
+		}
+	}
+
+	height := (len(years) + 1) * cellHeight
+	width := labelWidth + 13*cellWidth
+	var b strings.Builder
+	openSVG(&b, width, height)
+	for i, label := range []string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec", "Year"} {
+		fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="middle" font-weight="bold">%s</text>`, labelWidth+i*cellWidth+cellWidth/2, cellHeight/2+4, label)
+	}
+	for row, year := range years {
+		y := (row + 1) * cellHeight
+		fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="end" font-weight="bold">%d</text>`, labelWidth-8, y+cellHeight/2+4, year.year)
+		cells := append(year.months[:], &year.total)
+		for col, r := range cells {
+			x := labelWidth + col*cellWidth
+			if r == nil {
+				fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%d" height="%d" fill="#fafafa" stroke="#fff"/>`, x, y, cellWidth, cellHeight)
+				continue
+			}
+			fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s" stroke="#fff"/>`, x, y, cellWidth, cellHeight, returnColor(*r, maxAbs))
+			fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="middle">%s</text>`, x+cellWidth/2, y+cellHeight/2+4, formatPercent(*r))
+		}
+	}
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String())
+}
+
+// returnColor shades positive returns green and negative returns red, by magnitude
+func returnColor(r, maxAbs float64) string {
+	intensity := 0.0
+	if maxAbs > 0 {
+		intensity = math.Min(math.Abs(r)/maxAbs, 1)
+	}
+	shade := func(full int) int { return 255 - int(float64(255-full)*intensity) }
+	if r >= 0 {
+		return fmt.Sprintf("rgb(%d,%d,%d)", shade(99), shade(190), shade(123))
+	}
+	return fmt.Sprintf("rgb(%d,%d,%d)", shade(248), shade(105), shade(107))
+}
+
+// plotArea maps data coordinates to SVG coordinates
+type plotArea struct {
+	height     int
+	xMin, xMax float64
+	yMin, yMax float64
+}
+
+// newPlotArea creates a plot area, widening empty ranges so points stay visible
+func newPlotArea(height int, xMin, xMax, yMin, yMax float64) plotArea {
+	if xMax == xMin {
+		xMin, xMax = xMin-1, xMax+1
+	}
+	if yMax == yMin {
+		pad := math.Max(math.Abs(yMin)*0.01, 1)
+		yMin, yMax = yMin-pad, yMax+pad
+	}
+	return plotArea{height: height, xMin: xMin, xMax: xMax, yMin: yMin, yMax: yMax}
+}
+
+func (a plotArea) px(x float64) float64 {
+	return marginLeft + (x-a.xMin)/(a.xMax-a.xMin)*float64(chartWidth-marginLeft-marginRight)
+}
+
+func (a plotArea) py(y float64) float64 {
+	return float64(a.height-marginBottom) - (y-a.yMin)/(a.yMax-a.yMin)*float64(a.height-marginTop-marginBottom)
+}
+
+// timeAxis draws date labels along the bottom of the plot
+func (a plotArea) timeAxis(b *strings.Builder) {
+	const labels = 6
+	span := time.Duration((a.xMax - a.xMin) * float64(time.Second))
+	layout := "2006-01-02"
+	if span < 3*24*time.Hour {
+		layout = "01-02 15:04"
+	} else if span > 2*365*24*time.Hour {
+		layout = "Jan 2006"
+	}
+
+	bottom := a.height - marginBottom
+	fmt.Fprintf(b, `<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="%s"/>`, marginLeft, bottom, chartWidth-marginRight, bottom, colorAxis)
+	for i := 0; i < labels; i++ {
+		x := a.xMin + (a.xMax-a.xMin)*float64(i)/float64(labels-1)
+		anchor := "middle"
+		if i == 0 {
+			anchor = "start"
+		} else if i == labels-1 {
+			anchor = "end"
+		}
+		label := time.Unix(int64(x), 0).UTC().Format(layout)
+		fmt.Fprintf(b, `<text x="%.1f" y="%d" text-anchor="%s">%s</text>`, a.px(x), bottom+16, anchor, label)
+	}
+}
+
+// openSVG writes the opening tag of a chart
+func openSVG(b *strings.Builder, width, height int) {
+	fmt.Fprintf(b, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" width="100%%" font-family="sans-serif" font-size="11" fill="#333">`, width, height)
+}
+
+// emptyChart is drawn in place of a chart with no data
+func emptyChart(height int) template.HTML {
+	var b strings.Builder
+	openSVG(&b, chartWidth, height)
+	fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="middle">No data</text></svg>`, chartWidth/2, height/2)
+	return template.HTML(b.String())
+}
+
+// niceTicks returns about n evenly spaced round values covering [lo, hi]
+func niceTicks(lo, hi float64, n int) []float64 {
+	if hi <= lo {
+		return []float64{lo}
+	}
+	raw := (hi - lo) / float64(n)
+	magnitude := math.Pow(10, math.Floor(math.Log10(raw)))
+	step := magnitude
+	for _, multiple := range []float64{1, 2, 2.5, 5, 10} {
+		step = multiple * magnitude
+		if step >= raw {
+			break
+		}
+	}
+
+	var ticks []float64
+	for tick := math.Floor(lo/step) * step; tick <= hi+step*0.5; tick += step {
+		ticks = append(ticks, math.Round(tick/step)*step)
+	}
+	return ticks
+}
+
+// downsample reduces points to about max by keeping the lowest and highest point of
+// each bucket, so peaks and troughs survive
+func downsample(points []point, max int) []point {
+	if len(points) <= max {
+		return points
+	}
+
+	bucket := int(math.Ceil(float64(len(points)) / float64(max/2)))
+	result := make([]point, 0, max+2)
+	for start := 0; start < len(points); start += bucket {
+		end := start + bucket
+		if end > len(points) {
+			end = len(points)
+		}
+		lowest, highest := start, start
+		for i := start; i < end; i++ {
+			if points[i].y < points[lowest].y {
+				lowest = i
+			}
+			if points[i].y > points[highest].y {
+				highest = i
+			}
+		}
+		if lowest > highest {
+			lowest, highest = highest, lowest
+		}
+		result = append(result, points[lowest])
+		if highest != lowest {
+			result = append(result, points[highest])
+		}
+	}
+	if last := points[len(points)-1]; result[len(result)-1] != last {
+		result = append(result, last)
+	}
+	return result
+}
@@ -0,0 +1,486 @@
+// Package reporting renders backtest results as human-readable reports.
+package reporting
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/ridopark/JonBuhTrader/pkg/backtester"
+	"github.com/ridopark/JonBuhTrader/pkg/strategy"
+)
+
+// Chart heights in SVG user units
+const (
+	equityChartHeight   = 320
+	drawdownChartHeight = 200
+	histogramHeight     = 240
+	priceChartHeight    = 260
+	returnHistogramBins = 40
+	maxMarkersPerSymbol = 2000 // Trades beyond this are not drawn on a symbol's price chart
+)
+
+// WriteHTML writes a tear sheet for the results to path
+func WriteHTML(path string, results *backtester.Results) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create report: %w", err)
+	}
+	defer file.Close()
+
+	if err := RenderHTML(file, results); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// RenderHTML renders a self-contained tear sheet: equity against the benchmark (or a
+// buy-and-hold of the traded symbols without one), underwater drawdown, monthly returns,
+// the daily return distribution, trades on each symbol's price chart and the performance
+// metrics. Charts are inline SVG, so the page needs no network access.
+func RenderHTML(w io.Writer, results *backtester.Results) error {
+	if results.Metrics == nil {
+		results.CalculateMetrics()
+	}
+
+	equity := equitySeries(results.EquityCurve)
+	benchmarkName, benchmarkCurve := results.BenchmarkSymbol, results.BenchmarkCurve
+	if len(benchmarkCurve) == 0 {
+		benchmarkName = "Buy & hold"
+		benchmarkCurve = backtester.BuyAndHoldCurve(results.Bars, results.EquityCurve, results.InitialCapital)
+	}
+	daily := results.DailyReturns()
+
+	page := tearSheet{
+		Title:   results.StrategyName,
+		Period:  fmt.Sprintf("%s to %s", results.StartDate.Format("2006-01-02"), results.EndDate.Format("2006-01-02")),
+		Created: time.Now().Format("2006-01-02 15:04"),
+	}
+	rows := metricRows(results)
+	half := (len(rows) + 1) / 2
+	page.Metrics = [2][]metricRow{rows[:half], rows[half:]}
+
+	equityLines := []series{{name: "Strategy", color: colorStrategy, points: equity}}
+	if len(benchmarkCurve) > 0 {
+		equityLines = append(equityLines, series{name: benchmarkName, color: colorBenchmark, points: equitySeries(benchmarkCurve)})
+	}
+	page.Equity = lineChart{height: equityChartHeight, series: equityLines, yFormat: formatMoney}.svg()
+	page.Drawdown = lineChart{
+		height:  drawdownChartHeight,
+		series:  []series{{color: colorDrawdown, points: underwater(results.EquityCurve, results.InitialCapital), fill: true}},
+		yFormat: formatPercent,
+	}.svg()
+	for _, period := range results.DrawdownPeriods {
+		recovery := "ongoing"
+		if !period.Ongoing {
+			recovery = period.Recovery.Format("2006-01-02")
+		}
+		page.Drawdowns = append(page.Drawdowns, drawdownRow{
+			Peak:     period.Peak.Format("2006-01-02"),
+			Trough:   period.Trough.Format("2006-01-02"),
+			Recovery: recovery,
+			Depth:    formatPercent(-period.Depth),
+			Duration: fmt.Sprintf("%.0f days", period.DurationDays),
+			Recover:  fmt.Sprintf("%.0f days", period.RecoveryDays),
+		})
+	}
+	if len(results.Rolling) > 0 {
+		var lines []series
+		for i, rolling := range results.Rolling {
+			points := make([]point, len(rolling.Points))
+			for j, p := range rolling.Points {
+				points[j] = point{x: unix(p.Timestamp), y: p.Sharpe}
+			}
+			lines = append(lines, series{
+				name:   fmt.Sprintf("%d-day", rolling.Window),
+				color:  rollingColors[i%len(rollingColors)],
+				points: points,
+			})
+		}
+		page.Rolling = lineChart{height: drawdownChartHeight, series: lines, yFormat: formatRatio}.svg()
+	}
+	reasons := make([]string, 0, len(results.Metrics.RoundTripsByReason))
+	for reason := range results.Metrics.RoundTripsByReason {
+		reasons = append(reasons, reason)
+	}
+	sort.Strings(reasons)
+	for _, reason := range reasons {
+		stats := results.Metrics.RoundTripsByReason[reason]
+		page.Reasons = append(page.Reasons, reasonRow{
+			Reason:     reason,
+			Count:      stats.Count,
+			WinRate:    formatPercent(stats.WinRate / 100),
+			Expectancy: formatMoney(stats.Expectancy),
+			NetPL:      formatMoney(stats.NetPL),
+			EdgeRatio:  formatRatio(stats.EdgeRatio),
+			MAE:        formatPercent(stats.AvgMAE / 100),
+			MFE:        formatPercent(stats.AvgMFE / 100),
+			BarsHeld:   fmt.Sprintf("%.1f", stats.AvgBarsHeld),
+		})
+	}
+	for _, dimension := range []struct {
+		name         string
+		attributions map[string]backtester.Attribution
+	}{
+		{"Symbol", results.Metrics.BySymbol},
+		{"Entry Reason", results.Metrics.ByReason},
+		{"Strategy", results.Metrics.ByStrategy},
+	} {
+		if len(dimension.attributions) == 0 {
+			continue
+		}
+		table := attributionTable{Dimension: dimension.name}
+		keys := make([]string, 0, len(dimension.attributions))
+		for key := range dimension.attributions {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			a := dimension.attributions[key]
+			table.Rows = append(table.Rows, attributionRow{
+				Key:          key,
+				RoundTrips:   a.RoundTrips,
+				NetPL:        formatMoney(a.NetPL),
+				Fees:         formatMoney(a.Fees),
+				WinRate:      formatPercent(a.WinRate / 100),
+				Exposure:     formatPercent(a.Exposure / 100),
+				Contribution: formatPercent(a.Contribution / 100),
+			})
+		}
+		page.Attribution = append(page.Attribution, table)
+	}
+	page.Monthly = heatmap(monthlyReturns(results.EquityCurve, results.InitialCapital))
+	page.Distribution = histogram(daily, returnHistogramBins, histogramHeight, formatPercent)
+
+	symbols := make([]string, 0, len(results.Bars))
+	for symbol := range results.Bars {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+	for _, symbol := range symbols {
+		page.Symbols = append(page.Symbols, symbolChart{
+			Symbol: symbol,
+			Chart:  priceChart(results.Bars[symbol], results.Trades, symbol),
+		})
+	}
+
+	return tearSheetTemplate.Execute(w, page)
+}
+
+// tearSheet is the data rendered by the report template
+type tearSheet struct {
+	Title        string
+	Period       string
+	Created      string
+	Metrics      [2][]metricRow // Metrics table, split into two columns
+	Equity       template.HTML
+	Drawdown     template.HTML
+	Drawdowns    []drawdownRow
+	Rolling      template.HTML
+	Reasons      []reasonRow
+	Attribution  []attributionTable
+	Monthly      template.HTML
+	Distribution template.HTML
+	Symbols      []symbolChart
+}
+
+// metricRow is a line of the metrics table
+type metricRow struct {
+	Label string
+	Value string
+}
+
+// drawdownRow is a line of the worst drawdowns table
+type drawdownRow struct {
+	Peak     string
+	Trough   string
+	Recovery string
+	Depth    string
+	Duration string
+	Recover  string
+}
+
+// reasonRow is a line of the round trips by entry reason table
+type reasonRow struct {
+	Reason     string
+	Count      int
+	WinRate    string
+	Expectancy string
+	NetPL      string
+	EdgeRatio  string
+	MAE        string
+	MFE        string
+	BarsHeld   string
+}
+
+// attributionTable breaks performance down by one dimension
+type attributionTable struct {
+	Dimension string
+	Rows      []attributionRow
+}
+
+// attributionRow is a line of an attribution table
+type attributionRow struct {
+	Key          string
+	RoundTrips   int
+	NetPL        string
+	Fees         string
+	WinRate      string
+	Exposure     string
+	Contribution string
+}
+
+// symbolChart is a symbol's price chart with its trades
+type symbolChart struct {
+	Symbol string
+	Chart  template.HTML
+}
+
+// metricRows formats the headline results and performance metrics
+func metricRows(results *backtester.Results) []metricRow {
+	m := results.Metrics
+	rows := []metricRow{
+		{"Initial Capital", formatMoney(results.InitialCapital)},
+		{"Final Capital", formatMoney(results.FinalCapital)},
+		{"Total Return", formatPercent(results.TotalReturn / 100)},
+		{"Total P&L", formatMoney(results.TotalPL)},
+		{"Asset P&L", formatMoney(results.AssetPL)},
+		{"Currency P&L", formatMoney(results.CurrencyPL)},
+		{"Annualized Return", formatPercent(m.AnnualizedReturn / 100)},
+		{"Volatility (annualized)", formatPercent(m.Volatility / 100)},
+		{"Max Drawdown", formatPercent(results.MaxDrawdown)},
+		{"Ulcer Index", formatRatio(m.UlcerIndex)},
+		{"Pain Index", formatRatio(m.PainIndex)},
+		{"Sharpe Ratio", formatRatio(m.SharpeRatio)},
+		{"Sortino Ratio", formatRatio(m.SortinoRatio)},
+		{"Calmar Ratio", formatRatio(m.CalmarRatio)},
+		{"Probabilistic Sharpe", formatPercent(m.ProbabilisticSharpe / 100)},
+		{fmt.Sprintf("Deflated Sharpe (%d trials)", m.SharpeTrials), formatPercent(m.DeflatedSharpe / 100)},
+		{"Skewness", formatRatio(m.Skewness)},
+		{"Excess Kurtosis", formatRatio(m.Kurtosis)},
+		{"VaR (95%)", formatMoney(m.VaR95)},
+		{"Expected Shortfall", formatMoney(m.ExpectedShortfall)},
+		{"Round-trip Trades", fmt.Sprintf("%d", m.TotalTrades)},
+		{"Win Rate", formatPercent(m.WinRate / 100)},
+		{"Profit Factor", formatRatio(m.ProfitFactor)},
+		{"Trade Sharpe Ratio", formatRatio(m.TradeSharpeRatio)},
+		{"Expectancy", formatMoney(m.RoundTrips.Expectancy)},
+		{"Edge Ratio", formatRatio(m.RoundTrips.EdgeRatio)},
+		{"Average Win", formatMoney(m.AvgWin)},
+		{"Average Loss", formatMoney(m.AvgLoss)},
+		{"Largest Win", formatMoney(m.LargestWin)},
+		{"Largest Loss", formatMoney(m.LargestLoss)},
+		{"Executions", fmt.Sprintf("%d", len(results.Trades))},
+		{"Commission", formatMoney(m.Costs.Commission)},
+		{"SEC Fee", formatMoney(m.Costs.SecFee)},
+		{"FINRA TAF", formatMoney(m.Costs.FinraTaf)},
+		{"Slippage", formatMoney(m.Costs.Slippage)},
+		{"Cost Drag", formatPercent(m.Costs.Drag / 100)},
+		{"Rejected Orders", fmt.Sprintf("%d", len(results.RejectedOrders))},
+		{"Circuit Breakers Tripped", fmt.Sprintf("%d", len(results.CircuitBreakerEvents))},
+	}
+
+	if b := m.Benchmark; b != nil {
+		rows = append(rows,
+			metricRow{"Benchmark", b.Benchmark},
+			metricRow{"Benchmark Return", formatPercent(b.BenchmarkReturn / 100)},
+			metricRow{"Excess Return", formatPercent(b.ExcessReturn / 100)},
+			metricRow{"Alpha (annualized)", formatPercent(b.Alpha)},
+			metricRow{"Beta", formatRatio(b.Beta)},
+			metricRow{"Correlation", formatRatio(b.Correlation)},
+			metricRow{"Tracking Error", formatPercent(b.TrackingError)},
+			metricRow{"Information Ratio", formatRatio(b.InformationRatio)},
+			metricRow{"Up Capture", formatPercent(b.UpCapture)},
+			metricRow{"Down Capture", formatPercent(b.DownCapture)},
+		)
+	}
+	return rows
+}
+
+// priceChart draws a symbol's closes with a marker for each of its trades
+func priceChart(bars []strategy.BarData, trades []strategy.TradeEvent, symbol string) template.HTML {
+	closes := make([]point, len(bars))
+	for i, bar := range bars {
+		closes[i] = point{x: unix(bar.Timestamp), y: bar.Close}
+	}
+
+	var markers []marker
+	for _, trade := range trades {
+		if trade.Symbol != symbol || len(markers) >= maxMarkersPerSymbol {
+			continue
+		}
+		m := marker{
+			x:     unix(trade.Timestamp),
+			y:     trade.Price,
+			color: colorBuy,
+			up:    true,
+			title: fmt.Sprintf("%s %s %g @ %.4g (%s)", trade.Timestamp.Format("2006-01-02 15:04"), trade.Side, trade.Quantity, trade.Price, trade.Reason),
+		}
+		if trade.Side == strategy.OrderSideSell {
+			m.color, m.up = colorSell, false
+		}
+		markers = append(markers, m)
+	}
+
+	return lineChart{
+		height:  priceChartHeight,
+		series:  []series{{name: symbol, color: colorStrategy, points: closes}},
+		markers: markers,
+		yFormat: func(v float64) string { return fmt.Sprintf("%.4g", v) },
+	}.svg()
+}
+
+// equitySeries converts the equity curve to chart points
+func equitySeries(curve []backtester.EquityPoint) []point {
+	points := make([]point, len(curve))
+	for i, p := range curve {
+		points[i] = point{x: unix(p.Timestamp), y: p.Value}
+	}
+	return points
+}
+
+// underwater returns the drawdown from the running peak at each equity point, as a
+// negative fraction
+func underwater(curve []backtester.EquityPoint, initialCapital float64) []point {
+	points := make([]point, len(curve))
+	peak := initialCapital
+	for i, p := range curve {
+		peak = math.Max(peak, p.Value)
+		drawdown := 0.0
+		if peak > 0 {
+			drawdown = p.Value/peak - 1
+		}
+		points[i] = point{x: unix(p.Timestamp), y: drawdown}
+	}
+	return points
+}
+
+// yearReturns holds a year's monthly returns; months without data are nil
+type yearReturns struct {
+	year   int
+	months [12]*float64
+	total  float64
+}
+
+// monthlyReturns compounds the equity curve into returns per calendar month and year
+func monthlyReturns(curve []backtester.EquityPoint, initialCapital float64) []yearReturns {
+	var years []yearReturns
+	previous := initialCapital
+	for i, p := range curve {
+		if i+1 < len(curve) && sameMonth(p.Timestamp, curve[i+1].Timestamp) {
+			continue
+		}
+		if previous <= 0 {
+			previous = p.Value
+			continue
+		}
+
+		r := p.Value/previous - 1
+		previous = p.Value
+		year := p.Timestamp.Year()
+		if len(years) == 0 || years[len(years)-1].year != year {
+			years = append(years, yearReturns{year: year, total: 1})
+		}
+		current := &years[len(years)-1]
+		current.months[p.Timestamp.Month()-1] = &r
+		current.total *= 1 + r
+	}
+	for i := range years {
+		years[i].total--
+	}
+	return years
+}
+
+func sameMonth(a, b time.Time) bool {
+	return a.Year() == b.Year() && a.Month() == b.Month()
+}
+
+func unix(t time.Time) float64 {
+	return float64(t.Unix())
+}
+
+func formatMoney(v float64) string {
+	if math.Abs(v) >= 1e6 {
+		return fmt.Sprintf("$%.2fM", v/1e6)
+	}
+	return fmt.Sprintf("$%.2f", v)
+}
+
+func formatPercent(v float64) string {
+	return fmt.Sprintf("%.1f%%", v*100)
+}
+
+func formatRatio(v float64) string {
+	return fmt.Sprintf("%.2f", v)
+}
+
+var tearSheetTemplate = template.Must(template.New("tearsheet").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}} – Backtest Report</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; color: #222; margin: 0 auto; max-width: 1000px; padding: 24px; }
+h1 { margin-bottom: 4px; }
+h2 { border-bottom: 1px solid #ddd; padding-bottom: 4px; margin-top: 32px; font-size: 18px; }
+.meta { color: #666; }
+table.metrics { border-collapse: collapse; width: 100%; }
+table.metrics td { padding: 4px 8px; border-bottom: 1px solid #eee; }
+table.metrics td.value { text-align: right; font-variant-numeric: tabular-nums; }
+table.periods { border-collapse: collapse; width: 100%; margin-top: 8px; }
+table.periods th, table.periods td { padding: 4px 8px; border-bottom: 1px solid #eee; text-align: right; }
+.grid { display: grid; grid-template-columns: 1fr 1fr; column-gap: 32px; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<div class="meta">{{.Period}} &middot; generated {{.Created}}</div>
+
+<h2>Performance Metrics</h2>
+<div class="grid">
+{{range .Metrics}}<table class="metrics">
+{{range .}}<tr><td>{{.Label}}</td><td class="value">{{.Value}}</td></tr>
+{{end}}</table>
+{{end}}</div>
+
+{{if .Reasons}}<h2>Round Trips by Entry Reason</h2>
+<table class="periods">
+<tr><th>Reason</th><th>Count</th><th>Win Rate</th><th>Expectancy</th><th>Net P&amp;L</th><th>Edge Ratio</th><th>Avg MAE</th><th>Avg MFE</th><th>Avg Bars Held</th></tr>
+{{range .Reasons}}<tr><td>{{.Reason}}</td><td>{{.Count}}</td><td>{{.WinRate}}</td><td>{{.Expectancy}}</td><td>{{.NetPL}}</td><td>{{.EdgeRatio}}</td><td>{{.MAE}}</td><td>{{.MFE}}</td><td>{{.BarsHeld}}</td></tr>
+{{end}}</table>{{end}}
+
+{{if .Attribution}}<h2>Attribution</h2>
+{{range .Attribution}}<table class="periods">
+<tr><th>{{.Dimension}}</th><th>Round Trips</th><th>Net P&amp;L</th><th>Fees</th><th>Win Rate</th><th>Exposure</th><th>Contribution</th></tr>
+{{range .Rows}}<tr><td>{{.Key}}</td><td>{{.RoundTrips}}</td><td>{{.NetPL}}</td><td>{{.Fees}}</td><td>{{.WinRate}}</td><td>{{.Exposure}}</td><td>{{.Contribution}}</td></tr>
+{{end}}</table>
+{{end}}{{end}}
+
+<h2>Equity Curve</h2>
+{{.Equity}}
+
+<h2>Underwater (Drawdown)</h2>
+{{.Drawdown}}
+{{if .Drawdowns}}<table class="periods">
+<tr><th>Peak</th><th>Trough</th><th>Recovery</th><th>Depth</th><th>Duration</th><th>Time to Recover</th></tr>
+{{range .Drawdowns}}<tr><td>{{.Peak}}</td><td>{{.Trough}}</td><td>{{.Recovery}}</td><td>{{.Depth}}</td><td>{{.Duration}}</td><td>{{.Recover}}</td></tr>
+{{end}}</table>{{end}}
+
+{{if .Rolling}}<h2>Rolling Sharpe Ratio</h2>
+{{.Rolling}}
+
+{{end}}<h2>Monthly Returns</h2>
+{{.Monthly}}
+
+<h2>Daily Return Distribution</h2>
+{{.Distribution}}
+
+{{range .Symbols}}<h2>{{.Symbol}} Trades</h2>
+{{.Chart}}
+{{end}}</body>
+</html>
+`))
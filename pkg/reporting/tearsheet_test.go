@@ -0,0 +1,131 @@
+package reporting
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ridopark/JonBuhTrader/pkg/backtester"
+	"github.com/ridopark/JonBuhTrader/pkg/strategy"
+)
+
+// testResults holds 10 AAPL shares bought at 100 through a rise to 129 and part of
+// the decline that follows, selling at 113 on day 45 of 60
+func testResults() *backtester.Results {
+	start := time.Date(2024, 1, 2, 21, 0, 0, 0, time.UTC)
+	var bars []strategy.BarData
+	var curve []backtester.EquityPoint
+	for i := 0; i < 60; i++ {
+		close := 100 + float64(i)
+		if i >= 30 {
+			close = 129 - float64(i-29)
+		}
+		timestamp := start.AddDate(0, 0, i)
+		bars = append(bars, strategy.BarData{Symbol: "AAPL", Timestamp: timestamp, Open: close, High: close, Low: close, Close: close, Volume: 1000})
+		value := 10000 + 10*(close-100)
+		if i > 45 {
+			value = 10130
+		}
+		curve = append(curve, backtester.EquityPoint{Timestamp: timestamp, Value: value})
+	}
+	trades := []strategy.TradeEvent{
+		{ID: "1", Symbol: "AAPL", Side: strategy.OrderSideBuy, Quantity: 10, Price: 100, Timestamp: bars[0].Timestamp, Strategy: "crossover", Reason: "breakout"},
+		{ID: "2", Symbol: "AAPL", Side: strategy.OrderSideSell, Quantity: 10, Price: 113, Timestamp: bars[45].Timestamp, Strategy: "crossover", Reason: "exit"},
+	}
+	barsBySymbol := map[string][]strategy.BarData{"AAPL": bars}
+	return &backtester.Results{
+		StrategyName:   "MA <fast> & slow",
+		StartDate:      bars[0].Timestamp,
+		EndDate:        bars[len(bars)-1].Timestamp,
+		InitialCapital: 10000,
+		FinalCapital:   curve[len(curve)-1].Value,
+		Trades:         trades,
+		RoundTrips:     backtester.RoundTrips(trades, barsBySymbol, nil),
+		EquityCurve:    curve,
+		Bars:           barsBySymbol,
+	}
+}
+
+func TestRenderHTML(t *testing.T) {
+	var buf bytes.Buffer
+	if err := RenderHTML(&buf, testResults()); err != nil {
+		t.Fatalf("RenderHTML: %v", err)
+	}
+	page := buf.String()
+
+	if strings.Contains(page, "MA <fast>") || !strings.Contains(page, "<title>MA &lt;fast&gt; &amp; slow – Backtest Report</title>") {
+		t.Error("strategy name is not escaped in the title")
+	}
+	for _, want := range []string{
+		"2024-01-02 to 2024-03-01",
+		"<h2>Performance Metrics</h2>",
+		"<h2>Round Trips by Entry Reason</h2>",
+		"<tr><td>breakout</td><td>1</td><td>100.0%</td>",
+		"<h2>Attribution</h2>",
+		"<th>Symbol</th>",
+		"<h2>Equity Curve</h2>",
+		"Buy &amp; hold", // Benchmark line without a benchmark symbol
+		"<h2>Underwater (Drawdown)</h2>",
+		"<td>ongoing</td>",
+		"<h2>Monthly Returns</h2>",
+		">2024</text>",
+		"<h2>Daily Return Distribution</h2>",
+		"<h2>AAPL Trades</h2>",
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("page does not contain %q", want)
+		}
+	}
+	if strings.Contains(page, "No data") {
+		t.Error("page has an empty chart")
+	}
+
+	// One buy and one sell marker on the price chart
+	if got := strings.Count(page, `fill="`+colorBuy+`"><title>`); got != 1 {
+		t.Errorf("got %d buy markers, want 1", got)
+	}
+	if got := strings.Count(page, `fill="`+colorSell+`"><title>`); got != 1 {
+		t.Errorf("got %d sell markers, want 1", got)
+	}
+	// Equity, underwater, monthly returns, return distribution and the AAPL price chart
+	if got := strings.Count(page, "<svg "); got != 5 {
+		t.Errorf("got %d charts, want 5", got)
+	}
+}
+
+func TestRenderHTMLUsesBenchmark(t *testing.T) {
+	results := testResults()
+	results.BenchmarkSymbol = "SPY"
+	for _, point := range results.EquityCurve {
+		results.BenchmarkCurve = append(results.BenchmarkCurve, backtester.EquityPoint{Timestamp: point.Timestamp, Value: 10000})
+	}
+
+	var buf bytes.Buffer
+	if err := RenderHTML(&buf, results); err != nil {
+		t.Fatalf("RenderHTML: %v", err)
+	}
+	page := buf.String()
+	if !strings.Contains(page, ">SPY</text>") || strings.Contains(page, "Buy &amp; hold") {
+		t.Error("equity chart does not plot the SPY benchmark in place of buy and hold")
+	}
+}
+
+func TestRenderHTMLWithoutData(t *testing.T) {
+	results := &backtester.Results{StrategyName: "idle", InitialCapital: 10000}
+
+	var buf bytes.Buffer
+	if err := RenderHTML(&buf, results); err != nil {
+		t.Fatalf("RenderHTML: %v", err)
+	}
+	page := buf.String()
+	// Equity, underwater, monthly returns and return distribution
+	if got := strings.Count(page, ">No data</text>"); got != 4 {
+		t.Errorf("got %d empty charts, want 4", got)
+	}
+	for _, section := range []string{"Round Trips by Entry Reason", "Attribution", "Rolling Sharpe Ratio", " Trades</h2>"} {
+		if strings.Contains(page, section) {
+			t.Errorf("page without data has a %q section", section)
+		}
+	}
+}
@@ -39,7 +39,7 @@ type AllocationConfig struct {
 	PositionSize       float64                     // Base position size as percentage of cash (0.0-1.0)
 	MinCashBuffer      float64                     // Minimum cash to keep available (e.g., 100.0 for $100)
 	SlippageBuffer     float64                     // Buffer for slippage/fees as percentage (e.g., 0.02 for 2%)
-	AllowFractional    bool                        // Whether to allow fractional shares
+	AllowFractional    bool                        // Whether to allow fractional shares for instruments without a quantity step
 	VolatilityAdjust   bool                        // Whether to adjust position size based on volatility
 	VolatilityCallback func(symbol string) float64 // Function to get volatility for a symbol
 }
@@ -177,7 +177,7 @@ func (ca *CapitalAllocator) allocateEqually(ctx Context, signals []TradingSignal
 	allocationPerSignal := (tradableCash * ca.config.PositionSize) / float64(len(signals))
 
 	for _, signal := range signals {
-		quantity := ca.calculatePositionSize(signal, allocationPerSignal)
+		quantity := ca.calculatePositionSize(ctx, signal, allocationPerSignal)
 		if quantity > 0 {
 			cost := quantity * signal.GetPrice()
 
@@ -235,7 +235,7 @@ func (ca *CapitalAllocator) allocateByConfidence(ctx Context, signals []TradingS
 			allocation = math.Min(allocation, remainingCash)
 		}
 
-		quantity := ca.calculatePositionSize(signal, allocation)
+		quantity := ca.calculatePositionSize(ctx, signal, allocation)
 		if quantity > 0 {
 			cost := quantity * signal.GetPrice()
 			if cost <= remainingCash {
@@ -296,7 +296,7 @@ func (ca *CapitalAllocator) allocateByPriority(ctx Context, signals []TradingSig
 			allocation = math.Min(allocation, remainingCash)
 		}
 
-		quantity := ca.calculatePositionSize(signal, allocation)
+		quantity := ca.calculatePositionSize(ctx, signal, allocation)
 		if quantity > 0 {
 			cost := quantity * signal.GetPrice()
 			if cost <= remainingCash {
@@ -343,7 +343,7 @@ func (ca *CapitalAllocator) allocateSequential(ctx Context, signals []TradingSig
 
 		// Calculate position size based on remaining cash
 		allocation := math.Min(ca.config.PositionSize, remainingCash/tradableCash)
-		quantity := ca.calculatePositionSize(signal, remainingCash*allocation)
+		quantity := ca.calculatePositionSize(ctx, signal, remainingCash*allocation)
 
 		if quantity > 0 {
 			cost := quantity * signal.GetPrice()
@@ -382,7 +382,7 @@ func (ca *CapitalAllocator) allocateSequential(ctx Context, signals []TradingSig
 }
 
 // calculatePositionSize calculates the position size for a signal
-func (ca *CapitalAllocator) calculatePositionSize(signal TradingSignal, allocation float64) float64 {
+func (ca *CapitalAllocator) calculatePositionSize(ctx Context, signal TradingSignal, allocation float64) float64 {
 	if allocation <= 0 || signal.GetPrice() <= 0 {
 		return 0
 	}
@@ -396,11 +396,19 @@ func (ca *CapitalAllocator) calculatePositionSize(signal TradingSignal, allocati
 		quantity *= volatilityAdjustment
 	}
 
-	// Handle fractional shares
-	if !ca.config.AllowFractional {
+	// An instrument's quantity step takes precedence over AllowFractional,
+	// which only applies to instruments without lot size rules
+	instrument := ctx.GetInstrument(signal.GetSymbol())
+	if instrument != nil && instrument.QuantityStep > 0 {
+		quantity = instrument.RoundQuantity(quantity)
+	} else if !ca.config.AllowFractional {
 		quantity = math.Floor(quantity)
 	}
 
+	if instrument != nil && quantity < instrument.MinQuantity {
+		return 0
+	}
+
 	return math.Max(0, quantity)
 }
 
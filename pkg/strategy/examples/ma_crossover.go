@@ -1,6 +1,7 @@
 package examples
 
 import (
+	"github.com/ridopark/JonBuhTrader/pkg/indicators"
 	"github.com/ridopark/JonBuhTrader/pkg/strategy"
 )
 
@@ -14,18 +15,24 @@ type MACrossoverSignal struct {
 	LongMA     float64
 }
 
-// MovingAverageCrossoverStrategy implements a simple moving average crossover strategy
-type MovingAverageCrossoverStrategy struct {
-	*strategy.BaseStrategy
-	shortPeriod    int
-	longPeriod     int
-	prices         []float64
+// crossoverState holds the moving averages and position flag of one symbol
+type crossoverState struct {
+	shortSMA       *indicators.SMA
+	longSMA        *indicators.SMA
 	position       bool // true if long, false if flat
 	lastShortMA    float64
 	lastLongMA     float64
 	currentShortMA float64
 	currentLongMA  float64
-	allocator      *strategy.CapitalAllocator // Capital allocation system
+}
+
+// MovingAverageCrossoverStrategy implements a simple moving average crossover strategy
+type MovingAverageCrossoverStrategy struct {
+	*strategy.BaseStrategy
+	shortPeriod int
+	longPeriod  int
+	states      map[string]*crossoverState // Per-symbol moving averages
+	allocator   *strategy.CapitalAllocator // Capital allocation system
 }
 
 // NewMovingAverageCrossoverStrategy creates a new moving average crossover strategy
@@ -49,12 +56,24 @@ func NewMovingAverageCrossoverStrategy(shortPeriod, longPeriod int) *MovingAvera
 		BaseStrategy: base,
 		shortPeriod:  shortPeriod,
 		longPeriod:   longPeriod,
-		prices:       make([]float64, 0, longPeriod+1),
-		position:     false,
+		states:       make(map[string]*crossoverState),
 		allocator:    strategy.NewCapitalAllocator(allocConfig),
 	}
 }
 
+// getState returns the crossover state for a symbol, creating it on first use
+func (s *MovingAverageCrossoverStrategy) getState(symbol string) *crossoverState {
+	state, exists := s.states[symbol]
+	if !exists {
+		state = &crossoverState{
+			shortSMA: indicators.NewSMA(s.shortPeriod),
+			longSMA:  indicators.NewSMA(s.longPeriod),
+		}
+		s.states[symbol] = state
+	}
+	return state
+}
+
 // SetSymbols sets the symbols for this strategy (called by the engine)
 func (s *MovingAverageCrossoverStrategy) SetSymbols(symbols []string) {
 	s.BaseStrategy.SetSymbols(symbols)
@@ -77,35 +96,27 @@ func (s *MovingAverageCrossoverStrategy) OnDataPoint(ctx strategy.Context, dataP
 
 	// Phase 1: Analyze all symbols and collect potential buy signals
 	for _, symbol := range s.GetSymbols() {
-		// Add current price to our price history
-		s.prices = append(s.prices, dataPoint.Bars[symbol].Close)
-
-		// Keep only the data we need (longPeriod + 1 for crossover detection)
-		if len(s.prices) > s.longPeriod+1 {
-			s.prices = s.prices[1:]
+		if _, exists := dataPoint.Bars[symbol]; !exists {
+			continue
 		}
+		state := s.getState(symbol)
 
-		ctx.Log("debug", "Price history updated", map[string]interface{}{
-			"symbol":        symbol,
-			"price":         dataPoint.Bars[symbol].Close,
-			"history_count": len(s.prices),
-			"need_count":    s.longPeriod,
-		})
+		state.shortSMA.Add(dataPoint.Bars[symbol].Close)
+		state.longSMA.Add(dataPoint.Bars[symbol].Close)
 
 		// Need at least longPeriod prices to calculate moving averages
-		if len(s.prices) < s.longPeriod {
+		if !state.longSMA.Ready() {
 			// Test the context SMA function even with limited data
-			if len(s.prices) >= s.shortPeriod {
+			if state.shortSMA.Ready() {
 				contextShortSMA, err := ctx.SMA(symbol, s.shortPeriod)
 				if err == nil {
-					internalSMA := s.calculateSMA(s.shortPeriod)
+					internalSMA := state.shortSMA.Value()
 					ctx.Log("debug", "SMA comparison (early)", map[string]interface{}{
 						"symbol":       symbol,
 						"internal_sma": internalSMA,
 						"context_sma":  contextShortSMA,
 						"price":        dataPoint.Bars[symbol].Close,
 						"period":       s.shortPeriod,
-						"data_points":  len(s.prices),
 					})
 				} else {
 					ctx.Log("debug", "Context SMA error", map[string]interface{}{
@@ -119,18 +130,18 @@ func (s *MovingAverageCrossoverStrategy) OnDataPoint(ctx strategy.Context, dataP
 		}
 
 		// Calculate moving averages
-		s.lastShortMA = s.currentShortMA
-		s.lastLongMA = s.currentLongMA
+		state.lastShortMA = state.currentShortMA
+		state.lastLongMA = state.currentLongMA
 
-		s.currentShortMA = s.calculateSMA(s.shortPeriod)
-		s.currentLongMA = s.calculateSMA(s.longPeriod)
+		state.currentShortMA = state.shortSMA.Value()
+		state.currentLongMA = state.longSMA.Value()
 
 		// Test the context SMA function (for comparison)
 		contextShortSMA, err := ctx.SMA(symbol, s.shortPeriod)
 		if err == nil {
 			ctx.Log("debug", "SMA comparison", map[string]interface{}{
 				"symbol":       symbol,
-				"internal_sma": s.currentShortMA,
+				"internal_sma": state.currentShortMA,
 				"context_sma":  contextShortSMA,
 				"price":        dataPoint.Bars[symbol].Close,
 				"period":       s.shortPeriod,
@@ -138,20 +149,20 @@ func (s *MovingAverageCrossoverStrategy) OnDataPoint(ctx strategy.Context, dataP
 		}
 
 		// Need at least one previous calculation for crossover detection
-		if s.lastShortMA == 0 || s.lastLongMA == 0 {
+		if state.lastShortMA == 0 || state.lastLongMA == 0 {
 			continue
 		}
 
 		// Check for crossover signals
-		prevCross := s.lastShortMA > s.lastLongMA
-		currentCross := s.currentShortMA > s.currentLongMA
+		prevCross := state.lastShortMA > state.lastLongMA
+		currentCross := state.currentShortMA > state.currentLongMA
 
 		position := ctx.GetPosition(symbol)
 
 		// Bullish crossover: short MA crosses above long MA
-		if !prevCross && currentCross && !s.position {
+		if !prevCross && currentCross && !state.position {
 			// Calculate confidence based on the strength of the crossover
-			confidence := s.calculateCrossoverConfidence(s.currentShortMA, s.currentLongMA)
+			confidence := s.calculateCrossoverConfidence(state.currentShortMA, state.currentLongMA)
 
 			// Collect potential buy signal
 			potentialSignals = append(potentialSignals, MACrossoverSignalImpl{
@@ -159,8 +170,8 @@ func (s *MovingAverageCrossoverStrategy) OnDataPoint(ctx strategy.Context, dataP
 				Bar:        dataPoint.Bars[symbol],
 				SignalType: "bullish_crossover",
 				Price:      dataPoint.Bars[symbol].Close,
-				ShortMA:    s.currentShortMA,
-				LongMA:     s.currentLongMA,
+				ShortMA:    state.currentShortMA,
+				LongMA:     state.currentLongMA,
 				Confidence: confidence,
 				Priority:   confidence, // Use confidence as priority
 			})
@@ -168,14 +179,14 @@ func (s *MovingAverageCrossoverStrategy) OnDataPoint(ctx strategy.Context, dataP
 			ctx.Log("debug", "MA Crossover potential BUY signal", map[string]interface{}{
 				"symbol":     symbol,
 				"price":      dataPoint.Bars[symbol].Close,
-				"shortMA":    s.currentShortMA,
-				"longMA":     s.currentLongMA,
+				"shortMA":    state.currentShortMA,
+				"longMA":     state.currentLongMA,
 				"confidence": confidence,
 			})
 		}
 
 		// Bearish crossover: short MA crosses below long MA
-		if prevCross && !currentCross && s.position && position != nil && position.Quantity > 0 {
+		if prevCross && !currentCross && state.position && position != nil && position.Quantity > 0 {
 			// Sell signal (immediate execution)
 			order := strategy.Order{
 				Symbol:   symbol,
@@ -186,14 +197,14 @@ func (s *MovingAverageCrossoverStrategy) OnDataPoint(ctx strategy.Context, dataP
 				Reason:   "bearish_crossover",
 			}
 			orders = append(orders, order)
-			s.position = false
+			state.position = false
 
 			ctx.Log("info", "Bearish crossover detected - selling", map[string]interface{}{
 				"symbol":   symbol,
 				"price":    dataPoint.Bars[symbol].Close,
 				"quantity": position.Quantity,
-				"shortMA":  s.currentShortMA,
-				"longMA":   s.currentLongMA,
+				"shortMA":  state.currentShortMA,
+				"longMA":   state.currentLongMA,
 				"reason":   "bearish_crossover",
 			})
 		}
@@ -237,20 +248,6 @@ func (s *MovingAverageCrossoverStrategy) GetParameters() map[string]interface{}
 	}
 }
 
-// calculateSMA calculates simple moving average for the given period
-func (s *MovingAverageCrossoverStrategy) calculateSMA(period int) float64 {
-	if len(s.prices) < period {
-		return 0
-	}
-
-	sum := 0.0
-	start := len(s.prices) - period
-	for i := start; i < len(s.prices); i++ {
-		sum += s.prices[i]
-	}
-	return sum / float64(period)
-}
-
 // calculateCrossoverConfidence calculates confidence based on the strength of the crossover
 func (s *MovingAverageCrossoverStrategy) calculateCrossoverConfidence(shortMA, longMA float64) float64 {
 	// Simple confidence calculation based on the gap between moving averages
@@ -6,6 +6,7 @@ import (
 	"sort"
 	"strconv"
 
+	"github.com/ridopark/JonBuhTrader/pkg/indicators"
 	"github.com/ridopark/JonBuhTrader/pkg/strategy"
 )
 
@@ -43,19 +44,25 @@ type SupportResistanceStrategy struct {
 	multiTimeframe      bool    // Use multiple timeframes
 	volatilityPeriod    int     // Period for volatility calculation
 	confidenceThreshold float64 // Minimum confidence for trading
+	patternConfirmation bool    // Require a bullish candlestick pattern at the level
+	minPatternStrength  float64 // Minimum strength of the confirming pattern
 
 	// Capital allocation
 	allocator *strategy.CapitalAllocator
 
 	// Internal state
-	levels          map[string][]SupportResistanceLevel // Support/resistance levels per symbol
-	priceHistory    map[string][]float64                // Price history per symbol
-	volumeHistory   map[string][]float64                // Volume history per symbol
-	volatility      map[string]float64                  // Current volatility per symbol
-	trend           map[string]string                   // Current trend per symbol ("up", "down", "sideways")
-	barCount        map[string]int                      // Bar count per symbol
-	breakoutBars    map[string]int                      // Bars since breakout per symbol
-	failedBreakouts map[string]map[float64]int          // Failed breakout attempts per level
+	levels            map[string][]SupportResistanceLevel // Support/resistance levels per symbol
+	priceHistory      map[string][]float64                // Price history per symbol
+	volumeHistory     map[string][]float64                // Volume history per symbol
+	volatility        map[string]float64                  // Current volatility per symbol
+	trend             map[string]string                   // Current trend per symbol ("up", "down", "sideways")
+	shortTrendSMA     map[string]*indicators.SMA          // Short moving average for trend detection per symbol
+	longTrendSMA      map[string]*indicators.SMA          // Long moving average for trend detection per symbol
+	barReturn         map[string]*indicators.ROC          // Bar-to-bar return per symbol
+	volatilityReturns map[string]*indicators.Window       // Recent absolute returns per symbol
+	barCount          map[string]int                      // Bar count per symbol
+	breakoutBars      map[string]int                      // Bars since breakout per symbol
+	failedBreakouts   map[string]map[float64]int          // Failed breakout attempts per level
 }
 
 // NewSupportResistanceStrategy creates a new support and resistance strategy
@@ -79,6 +86,8 @@ func NewSupportResistanceStrategy() *SupportResistanceStrategy {
 	multiTimeframe := getEnvBool("SR_MULTI_TIMEFRAME", true)
 	volatilityPeriod := getEnvInt("SR_VOLATILITY_PERIOD", 14)
 	confidenceThreshold := getEnvFloat("SR_CONFIDENCE_THRESHOLD", 0.6)
+	patternConfirmation := getEnvBool("SR_PATTERN_CONFIRMATION", false)
+	minPatternStrength := getEnvFloat("SR_MIN_PATTERN_STRENGTH", 0.5)
 
 	base := strategy.NewBaseStrategy("SupportResistance", map[string]interface{}{
 		"lookbackPeriod":       lookbackPeriod,
@@ -97,6 +106,8 @@ func NewSupportResistanceStrategy() *SupportResistanceStrategy {
 		"multiTimeframe":       multiTimeframe,
 		"volatilityPeriod":     volatilityPeriod,
 		"confidenceThreshold":  confidenceThreshold,
+		"patternConfirmation":  patternConfirmation,
+		"minPatternStrength":   minPatternStrength,
 	})
 
 	return &SupportResistanceStrategy{
@@ -117,6 +128,8 @@ func NewSupportResistanceStrategy() *SupportResistanceStrategy {
 		multiTimeframe:       multiTimeframe,
 		volatilityPeriod:     volatilityPeriod,
 		confidenceThreshold:  confidenceThreshold,
+		patternConfirmation:  patternConfirmation,
+		minPatternStrength:   minPatternStrength,
 		allocator: strategy.NewCapitalAllocator(strategy.AllocationConfig{
 			Method:           strategy.AllocateByConfidence,
 			MaxPositions:     3,
@@ -129,14 +142,18 @@ func NewSupportResistanceStrategy() *SupportResistanceStrategy {
 				return 0.02 // 2% default volatility
 			},
 		}),
-		levels:          make(map[string][]SupportResistanceLevel),
-		priceHistory:    make(map[string][]float64),
-		volumeHistory:   make(map[string][]float64),
-		volatility:      make(map[string]float64),
-		trend:           make(map[string]string),
-		barCount:        make(map[string]int),
-		breakoutBars:    make(map[string]int),
-		failedBreakouts: make(map[string]map[float64]int),
+		levels:            make(map[string][]SupportResistanceLevel),
+		priceHistory:      make(map[string][]float64),
+		volumeHistory:     make(map[string][]float64),
+		volatility:        make(map[string]float64),
+		trend:             make(map[string]string),
+		shortTrendSMA:     make(map[string]*indicators.SMA),
+		longTrendSMA:      make(map[string]*indicators.SMA),
+		barReturn:         make(map[string]*indicators.ROC),
+		volatilityReturns: make(map[string]*indicators.Window),
+		barCount:          make(map[string]int),
+		breakoutBars:      make(map[string]int),
+		failedBreakouts:   make(map[string]map[float64]int),
 	}
 }
 
@@ -201,6 +218,7 @@ func (s *SupportResistanceStrategy) OnDataPoint(ctx strategy.Context, dataPoint
 		s.priceHistory[symbol] = append(s.priceHistory[symbol], bar.Close)
 		s.volumeHistory[symbol] = append(s.volumeHistory[symbol], bar.Volume)
 		s.barCount[symbol]++
+		s.updateIndicators(symbol, bar)
 
 		// Keep only lookback period worth of data
 		if len(s.priceHistory[symbol]) > s.lookbackPeriod*2 {
@@ -217,7 +235,7 @@ func (s *SupportResistanceStrategy) OnDataPoint(ctx strategy.Context, dataPoint
 		s.updateLevels(symbol, bar)
 
 		// Update volatility and trend analysis
-		s.updateVolatility(symbol, bar)
+		s.updateVolatility(symbol)
 		s.updateTrend(symbol)
 		s.ageLevels(symbol)
 
@@ -241,6 +259,9 @@ func (s *SupportResistanceStrategy) OnDataPoint(ctx strategy.Context, dataPoint
 		// Collect potential entry signals if no position
 		if positionQuantity == 0 {
 			signal := s.evaluateEntrySignal(symbol, bar)
+			if signal != nil && s.patternConfirmation && !s.hasPatternConfirmation(ctx, symbol) {
+				signal = nil
+			}
 			if signal != nil {
 				signals = append(signals, SupportResistanceSignalImpl{
 					Symbol:     signal.Symbol,
@@ -501,53 +522,54 @@ func (s *SupportResistanceStrategy) OnFinish(ctx strategy.Context) error {
 	return nil
 }
 
-// updateVolatility calculates current volatility for adaptive tolerance
-func (s *SupportResistanceStrategy) updateVolatility(symbol string, bar strategy.BarData) {
-	prices := s.priceHistory[symbol]
-	if len(prices) < s.volatilityPeriod {
+// updateVolatility sets the current volatility for adaptive tolerance: the average
+// absolute bar-to-bar return over the volatility period. Like the price-history
+// calculation it replaced, it is ready once volatilityPeriod closes have been seen,
+// averaging the volatilityPeriod-1 returns available on that first bar
+func (s *SupportResistanceStrategy) updateVolatility(symbol string) {
+	returns := s.volatilityReturns[symbol]
+	if returns == nil || returns.Len() == 0 || returns.Len() < s.volatilityPeriod-1 {
 		s.volatility[symbol] = 0.02 // Default 2% volatility
 		return
 	}
 
-	// Calculate ATR-based volatility
-	var returns []float64
-	for i := 1; i < len(prices); i++ {
-		ret := math.Abs((prices[i] - prices[i-1]) / prices[i-1])
-		returns = append(returns, ret)
+	var sum float64
+	for i := 0; i < returns.Len(); i++ {
+		sum += returns.At(i)
 	}
+	s.volatility[symbol] = sum / float64(returns.Len())
+}
 
-	// Calculate average of last volatilityPeriod returns
-	start := len(returns) - s.volatilityPeriod
-	if start < 0 {
-		start = 0
+// updateIndicators feeds a bar into the moving averages used for trend detection
+// and the returns used for volatility
+func (s *SupportResistanceStrategy) updateIndicators(symbol string, bar strategy.BarData) {
+	if s.shortTrendSMA[symbol] == nil {
+		s.shortTrendSMA[symbol] = indicators.NewSMA(10)
+		s.longTrendSMA[symbol] = indicators.NewSMA(20)
+		s.barReturn[symbol] = indicators.NewROC(1)
+		s.volatilityReturns[symbol] = indicators.NewWindow(s.volatilityPeriod)
 	}
+	s.shortTrendSMA[symbol].Update(bar)
+	s.longTrendSMA[symbol].Update(bar)
 
-	var sum float64
-	count := 0
-	for i := start; i < len(returns); i++ {
-		sum += returns[i]
-		count++
-	}
-
-	if count > 0 {
-		s.volatility[symbol] = sum / float64(count)
+	barReturn := s.barReturn[symbol]
+	barReturn.Update(bar)
+	if barReturn.Ready() {
+		s.volatilityReturns[symbol].Add(math.Abs(barReturn.Value()) / 100)
 	}
 }
 
 // updateTrend determines the current trend direction
 func (s *SupportResistanceStrategy) updateTrend(symbol string) {
-	prices := s.priceHistory[symbol]
-	if len(prices) < 20 {
+	longAverage := s.longTrendSMA[symbol]
+	if longAverage == nil || !longAverage.Ready() {
 		s.trend[symbol] = "sideways"
 		return
 	}
 
 	// Simple trend detection using short vs long SMA
-	shortPeriod := 10
-	longPeriod := 20
-
-	shortSMA := s.calculateSMA(prices, shortPeriod)
-	longSMA := s.calculateSMA(prices, longPeriod)
+	shortSMA := s.shortTrendSMA[symbol].Value()
+	longSMA := longAverage.Value()
 
 	if shortSMA > longSMA*1.005 { // 0.5% threshold
 		s.trend[symbol] = "up"
@@ -558,20 +580,6 @@ func (s *SupportResistanceStrategy) updateTrend(symbol string) {
 	}
 }
 
-// calculateSMA calculates simple moving average
-func (s *SupportResistanceStrategy) calculateSMA(prices []float64, period int) float64 {
-	if len(prices) < period {
-		return 0
-	}
-
-	start := len(prices) - period
-	var sum float64
-	for i := start; i < len(prices); i++ {
-		sum += prices[i]
-	}
-	return sum / float64(period)
-}
-
 // ageLevels increases age of levels and removes old ones
 func (s *SupportResistanceStrategy) ageLevels(symbol string) {
 	var activeLevels []SupportResistanceLevel
@@ -779,6 +787,21 @@ func (s *SupportResistanceStrategy) isPriceBreakingEnhanced(currentPrice, levelP
 	}
 }
 
+// hasPatternConfirmation checks for a bullish candlestick pattern on the latest bar
+func (s *SupportResistanceStrategy) hasPatternConfirmation(ctx strategy.Context, symbol string) bool {
+	signals, err := ctx.CandlestickPatterns(symbol)
+	if err != nil {
+		return false
+	}
+
+	for _, signal := range signals {
+		if signal.Direction == strategy.PatternBullish && signal.Strength >= s.minPatternStrength {
+			return true
+		}
+	}
+	return false
+}
+
 // hasVolumeConfirmation checks if there's volume confirmation for the signal
 func (s *SupportResistanceStrategy) hasVolumeConfirmation(symbol string) bool {
 	volumes := s.volumeHistory[symbol]
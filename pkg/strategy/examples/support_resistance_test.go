@@ -0,0 +1,26 @@
+package examples
+
+import (
+	"math"
+	"testing"
+
+	"github.com/ridopark/JonBuhTrader/pkg/strategy"
+)
+
+func TestSupportResistanceVolatilityWarmup(t *testing.T) {
+	s := NewSupportResistanceStrategy()
+	s.volatilityPeriod = 3
+	s.SetSymbols([]string{"TEST"})
+
+	// Absolute returns of 10%, 20% and 5%
+	closes := []float64{100, 110, 88, 92.4}
+	want := []float64{0.02, 0.02, (0.1 + 0.2) / 2, (0.1 + 0.2 + 0.05) / 3}
+	for i, close := range closes {
+		s.updateIndicators("TEST", strategy.BarData{Symbol: "TEST", Close: close})
+		s.updateVolatility("TEST")
+		// The default holds until volatilityPeriod closes have been seen
+		if got := s.volatility["TEST"]; math.Abs(got-want[i]) > 1e-9 {
+			t.Errorf("bar %d volatility = %v, want %v", i+1, got, want[i])
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package strategy
+
+import "strconv"
+
+// IndicatorSpec identifies a technical indicator, its parameters and which of its outputs to read
+type IndicatorSpec struct {
+	Name   string    // Indicator name (e.g., "sma", "macd", "bollinger")
+	Params []float64 // Parameters in the same order as the matching Context method (e.g., {12, 26, 9} for MACD)
+	Output string    // Output of a multi-value indicator (e.g., "signal"); empty reads the main value
+}
+
+// NewIndicatorSpec creates a spec for the main value of an indicator
+func NewIndicatorSpec(name string, params ...float64) IndicatorSpec {
+	return IndicatorSpec{Name: name, Params: params}
+}
+
+// WithOutput returns a copy of the spec that reads the named output
+func (s IndicatorSpec) WithOutput(output string) IndicatorSpec {
+	s.Output = output
+	return s
+}
+
+// String returns a compact representation such as "macd(12,26,9).signal"
+func (s IndicatorSpec) String() string {
+	b := make([]byte, 0, 32)
+	b = append(b, s.Name...)
+	b = append(b, '(')
+	for i, param := range s.Params {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		b = strconv.AppendFloat(b, param, 'g', -1, 64)
+	}
+	b = append(b, ')')
+	if s.Output != "" {
+		b = append(b, '.')
+		b = append(b, s.Output...)
+	}
+	return string(b)
+}
@@ -0,0 +1,117 @@
+package strategy
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// AssetTypeFuture is the asset type used for futures contracts
+const AssetTypeFuture = "future"
+
+// Instrument holds static reference data for a tradable symbol
+type Instrument struct {
+	Symbol    string
+	Name      string
+	Exchange  string
+	AssetType string // "stock", "etf", "crypto", "forex", "future", etc.
+	Currency  string // ISO currency code the instrument is quoted and settled in
+
+	// Trading constraints (zero means unconstrained)
+	TickSize     float64 // Minimum price increment
+	MinQuantity  float64 // Minimum order quantity
+	QuantityStep float64 // Order quantity increment (1 for whole shares, 0.0001 for fractional crypto, etc.)
+	MinNotional  float64 // Minimum order value (quantity * price * multiplier)
+
+	// Futures contract specification
+	Root              string    // Futures root symbol (e.g., "ES" for "ESZ4")
+	Multiplier        float64   // Currency value of one point per contract
+	TickValue         float64   // Currency value of one tick per contract
+	InitialMargin     float64   // Exchange initial margin per contract
+	MaintenanceMargin float64   // Exchange maintenance margin per contract
+	Expiration        time.Time // Last trading day (zero if the instrument does not expire)
+}
+
+// IsFuture returns true if the instrument is a futures contract
+func (i *Instrument) IsFuture() bool {
+	return i.AssetType == AssetTypeFuture
+}
+
+// GetMultiplier returns the contract multiplier, deriving it from the tick
+// specification when not set and defaulting to 1 for non-futures
+func (i *Instrument) GetMultiplier() float64 {
+	if i.Multiplier > 0 {
+		return i.Multiplier
+	}
+	if i.TickSize > 0 && i.TickValue > 0 {
+		return i.TickValue / i.TickSize
+	}
+	return 1.0
+}
+
+// IsExpired returns true if the instrument has expired as of the given time
+func (i *Instrument) IsExpired(at time.Time) bool {
+	if i.Expiration.IsZero() {
+		return false
+	}
+	// Contracts trade through the end of their expiration day
+	return !at.Before(i.Expiration.Truncate(24 * time.Hour).Add(24 * time.Hour))
+}
+
+// quantityEpsilon absorbs floating point noise when checking quantity steps
+const quantityEpsilon = 1e-9
+
+// RoundQuantity rounds a quantity down to the nearest valid quantity step
+func (i *Instrument) RoundQuantity(quantity float64) float64 {
+	if i.QuantityStep <= 0 {
+		return quantity
+	}
+	steps := math.Floor(quantity/i.QuantityStep + quantityEpsilon)
+	return steps * i.QuantityStep
+}
+
+// RoundPrice rounds a limit or stop price to the tick size. Prices are rounded
+// in the direction that never makes the order more aggressive than requested:
+// buy limits and sell stops round down, sell limits and buy stops round up.
+func (i *Instrument) RoundPrice(price float64, side OrderSide, orderType OrderType) float64 {
+	if i.TickSize <= 0 || price <= 0 {
+		return price
+	}
+
+	ticks := price / i.TickSize
+	roundDown := (side == OrderSideBuy) == (orderType != OrderTypeStop)
+	if roundDown {
+		ticks = math.Floor(ticks + quantityEpsilon)
+	} else {
+		ticks = math.Ceil(ticks - quantityEpsilon)
+	}
+	return ticks * i.TickSize
+}
+
+// ValidateOrder checks an order against the instrument's quantity and notional
+// constraints, using price to evaluate the order value
+func (i *Instrument) ValidateOrder(order Order, price float64) error {
+	if order.Quantity <= 0 {
+		return fmt.Errorf("quantity %g must be positive", order.Quantity)
+	}
+
+	if i.MinQuantity > 0 && order.Quantity < i.MinQuantity-quantityEpsilon {
+		return fmt.Errorf("quantity %g is below the minimum of %g for %s", order.Quantity, i.MinQuantity, i.Symbol)
+	}
+
+	if i.QuantityStep > 0 {
+		steps := order.Quantity / i.QuantityStep
+		if math.Abs(steps-math.Round(steps)) > quantityEpsilon*math.Max(1, steps) {
+			return fmt.Errorf("quantity %g is not a multiple of the quantity step %g for %s", order.Quantity, i.QuantityStep, i.Symbol)
+		}
+	}
+
+	if i.MinNotional > 0 {
+		notional := order.Quantity * price * i.GetMultiplier()
+		if notional < i.MinNotional {
+			return fmt.Errorf("order value %.2f is below the minimum notional of %.2f for %s", notional, i.MinNotional, i.Symbol)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,158 @@
+package strategy
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestInstrumentRoundQuantity(t *testing.T) {
+	tests := []struct {
+		name     string
+		step     float64
+		quantity float64
+		want     float64
+	}{
+		{"unconstrained", 0, 12.345, 12.345},
+		{"whole shares", 1, 12.9, 12},
+		{"exact lot", 100, 300, 300},
+		{"round lot", 100, 299.99, 200},
+		{"fractional step", 0.0001, 0.123456, 0.1234},
+		// 0.3/0.1 is 2.9999999999999996 in floating point
+		{"floating point noise", 0.1, 0.3, 0.3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			instrument := Instrument{Symbol: "TEST", QuantityStep: tt.step}
+			if got := instrument.RoundQuantity(tt.quantity); math.Abs(got-tt.want) > 1e-12 {
+				t.Errorf("RoundQuantity(%v) = %v, want %v", tt.quantity, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInstrumentRoundPrice(t *testing.T) {
+	instrument := Instrument{Symbol: "ES", TickSize: 0.25}
+	tests := []struct {
+		name      string
+		price     float64
+		side      OrderSide
+		orderType OrderType
+		want      float64
+	}{
+		{"buy limit rounds down", 100.30, OrderSideBuy, OrderTypeLimit, 100.25},
+		{"sell limit rounds up", 100.30, OrderSideSell, OrderTypeLimit, 100.50},
+		{"buy stop rounds up", 100.30, OrderSideBuy, OrderTypeStop, 100.50},
+		{"sell stop rounds down", 100.30, OrderSideSell, OrderTypeStop, 100.25},
+		{"on tick", 100.75, OrderSideSell, OrderTypeLimit, 100.75},
+		{"non-positive price", 0, OrderSideBuy, OrderTypeLimit, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := instrument.RoundPrice(tt.price, tt.side, tt.orderType); math.Abs(got-tt.want) > 1e-12 {
+				t.Errorf("RoundPrice(%v) = %v, want %v", tt.price, got, tt.want)
+			}
+		})
+	}
+
+	unconstrained := Instrument{Symbol: "AAPL"}
+	if got := unconstrained.RoundPrice(100.123, OrderSideBuy, OrderTypeLimit); got != 100.123 {
+		t.Errorf("RoundPrice without a tick size = %v, want 100.123", got)
+	}
+}
+
+func TestInstrumentValidateOrder(t *testing.T) {
+	stock := Instrument{Symbol: "AAPL", MinQuantity: 1, QuantityStep: 1, MinNotional: 100}
+	future := Instrument{Symbol: "ESM4", AssetType: AssetTypeFuture, QuantityStep: 1, Multiplier: 50, MinNotional: 10000}
+
+	tests := []struct {
+		name       string
+		instrument Instrument
+		quantity   float64
+		price      float64
+		err        string
+	}{
+		{"valid", stock, 5, 50, ""},
+		{"zero quantity", stock, 0, 50, "must be positive"},
+		{"below minimum quantity", stock, 0.5, 500, "below the minimum"},
+		{"off step", stock, 2.5, 50, "not a multiple of the quantity step"},
+		{"below minimum notional", stock, 1, 50, "below the minimum notional"},
+		{"multiplier counts toward notional", future, 1, 250, ""},
+		{"below notional with multiplier", future, 1, 150, "below the minimum notional"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := Order{Symbol: tt.instrument.Symbol, Side: OrderSideBuy, Type: OrderTypeMarket, Quantity: tt.quantity}
+			err := tt.instrument.ValidateOrder(order, tt.price)
+			if tt.err == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("error = %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
+
+// allocationContext serves instruments to the allocator; other Context methods are unused
+type allocationContext struct {
+	Context
+	cash        float64
+	instruments map[string]*Instrument
+}
+
+func (c *allocationContext) GetCash() float64 { return c.cash }
+
+func (c *allocationContext) GetInstrument(symbol string) *Instrument { return c.instruments[symbol] }
+
+func (c *allocationContext) Log(level, message string, data map[string]interface{}) {}
+
+type allocationSignal struct {
+	symbol string
+	price  float64
+}
+
+func (s allocationSignal) GetSymbol() string      { return s.symbol }
+func (s allocationSignal) GetPrice() float64      { return s.price }
+func (s allocationSignal) GetConfidence() float64 { return 1 }
+func (s allocationSignal) GetSignalType() string  { return "test" }
+func (s allocationSignal) GetBarData() BarData    { return BarData{Symbol: s.symbol, Close: s.price} }
+func (s allocationSignal) GetPriority() float64   { return 1 }
+
+func TestAllocatorQuantityStepOverridesAllowFractional(t *testing.T) {
+	ctx := &allocationContext{
+		cash: 10000,
+		instruments: map[string]*Instrument{
+			"BTC":    {Symbol: "BTC", QuantityStep: 0.001},
+			"7203.T": {Symbol: "7203.T", QuantityStep: 100, MinQuantity: 100},
+		},
+	}
+	config := AllocationConfig{Method: AllocateEqually, PositionSize: 1}
+
+	tests := []struct {
+		name       string
+		fractional bool
+		signal     allocationSignal
+		want       float64
+	}{
+		// The instrument's step allows fractions even though the config does not
+		{"fractional step", false, allocationSignal{"BTC", 60000}, 0.166},
+		// 10000 / 30 is 333.3 shares, rounded down to a 100-share lot
+		{"round lot", true, allocationSignal{"7203.T", 30}, 300},
+		{"below minimum lot", false, allocationSignal{"7203.T", 150}, 0},
+		{"unknown instrument floored", false, allocationSignal{"AAPL", 300}, 33},
+		{"unknown instrument fractional", true, allocationSignal{"AAPL", 300}, 10000.0 / 300},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config.AllowFractional = tt.fractional
+			got := NewCapitalAllocator(config).calculatePositionSize(ctx, tt.signal, 10000)
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("quantity = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
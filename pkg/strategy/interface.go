@@ -50,7 +50,7 @@ type Order struct {
 	StopPrice float64 // For stop orders
 	Timestamp time.Time
 	Strategy  string
-	Reason    string   // Trading reason/signal type
+	Reason    string // Trading reason/signal type
 }
 
 // TradeEvent represents a completed trade
@@ -66,8 +66,9 @@ type TradeEvent struct {
 	SecFee     float64 // SEC Transaction Fee
 	FinraTaf   float64 // FINRA Trading Activity Fee
 	Slippage   float64 // Slippage cost
+	FXRate     float64 // Rate converting the symbol's currency into the base currency at the fill (0 = not recorded, treated as 1)
 	Strategy   string
-	Reason     string  // Trading reason/signal type
+	Reason     string // Trading reason/signal type
 }
 
 // Position represents a current position in a symbol
@@ -78,16 +79,55 @@ type Position struct {
 	MarketValue  float64
 	UnrealizedPL float64
 	RealizedPL   float64
+	Currency     string  // Currency the position is denominated in
+	AvgFXRate    float64 // Average base-currency rate paid for the position's currency
 }
 
 // Portfolio represents the current portfolio state
 type Portfolio struct {
-	Cash       float64
-	TotalValue float64
-	Positions  map[string]*Position
-	TotalPL    float64
-	DayPL      float64
-	Trades     []TradeEvent
+	Cash         float64 // Total cash converted to the base currency
+	CashBalances map[string]float64
+	BaseCurrency string
+	TotalValue   float64
+	Positions    map[string]*Position
+	TotalPL      float64
+	DayPL        float64
+	Trades       []TradeEvent
+}
+
+// DailySnapshot captures the portfolio state at the end of a trading session.
+// Monetary values are in the portfolio's base currency.
+type DailySnapshot struct {
+	Date          time.Time
+	Cash          float64
+	CashBalances  map[string]float64
+	Positions     map[string]Position
+	MarketValue   float64 // Market value of positions (notional for futures)
+	TotalValue    float64
+	RealizedPL    float64 // Cumulative realized P&L net of fees, including FX effects on cash
+	UnrealizedPL  float64
+	DayPL         float64
+	DailyReturn   float64 // Return for the session as a decimal (0.01 = 1%)
+	GrossExposure float64 // Sum of absolute position market values
+	NetExposure   float64 // Long minus short position market value
+}
+
+// PatternDirection indicates the price move a candlestick pattern points to
+type PatternDirection string
+
+const (
+	PatternBullish PatternDirection = "BULLISH"
+	PatternBearish PatternDirection = "BEARISH"
+	PatternNeutral PatternDirection = "NEUTRAL"
+)
+
+// PatternSignal is a candlestick pattern completed by the latest bar
+type PatternSignal struct {
+	Pattern   string // Pattern name (e.g., "bullish_engulfing")
+	Direction PatternDirection
+	Strength  float64   // Strength score from 0.0 (weak) to 1.0 (strong)
+	Bars      int       // Number of bars forming the pattern
+	Timestamp time.Time // Timestamp of the bar completing the pattern
 }
 
 // Context provides strategy access to market data and portfolio state
@@ -96,6 +136,10 @@ type Context interface {
 	GetPortfolio() *Portfolio
 	GetPosition(symbol string) *Position
 	GetCash() float64
+	GetDailySnapshots() []DailySnapshot
+
+	// Reference data (nil if the symbol has no registered instrument)
+	GetInstrument(symbol string) *Instrument
 
 	// Technical indicators (to be implemented)
 	SMA(symbol string, period int) (float64, error)
@@ -106,6 +150,51 @@ type Context interface {
 	SuperTrend(symbol string, period int, multiplier float64) (float64, error)
 	ParbolicSAR(symbol string, step, max float64) (float64, error)
 
+	// Volatility and band indicators
+	ATR(symbol string, period int) (float64, error)
+	StdDev(symbol string, period int) (float64, error)
+	BollingerBands(symbol string, period int, multiplier float64) (upper, middle, lower, percentB, bandwidth float64, err error)
+	KeltnerChannels(symbol string, emaPeriod, atrPeriod int, multiplier float64) (upper, middle, lower float64, err error)
+	DonchianChannels(symbol string, period int) (upper, middle, lower float64, err error)
+
+	// Volume indicators
+	VWAP(symbol string) (float64, error)
+	RollingVWAP(symbol string, period int) (float64, error)
+	OBV(symbol string) (float64, error)
+	MFI(symbol string, period int) (float64, error)
+	AccumulationDistribution(symbol string) (float64, error)
+	ChaikinMoneyFlow(symbol string, period int) (float64, error)
+	VolumeProfile(symbol string, period, bins int, valueArea float64) (poc, valueAreaHigh, valueAreaLow float64, err error)
+
+	// Oscillators
+	Stochastic(symbol string, kPeriod, kSmoothing, dPeriod int) (k, d float64, err error)
+	StochasticRSI(symbol string, rsiPeriod, stochPeriod, kSmoothing, dPeriod int) (k, d float64, err error)
+	WilliamsR(symbol string, period int) (float64, error)
+	CCI(symbol string, period int) (float64, error)
+	ROC(symbol string, period int) (float64, error)
+	Aroon(symbol string, period int) (up, down, oscillator float64, err error)
+	Ichimoku(symbol string, tenkanPeriod, kijunPeriod, senkouBPeriod, displacement int) (tenkan, kijun, senkouA, senkouB, chikou float64, err error)
+
+	// Cross-asset indicators. Correlation and beta use close-to-close returns; the hedge
+	// ratio regresses the symbol's closes on the other symbol's closes.
+	Correlation(symbol, other string, period int) (float64, error)
+	Beta(symbol, benchmark string, period int) (float64, error)
+	HedgeRatio(symbol, other string, period int) (hedgeRatio, intercept float64, err error)
+	SpreadZScore(symbol, other string, period int) (float64, error)
+	Cointegration(symbol, other string, period int) (statistic float64, err error)
+
+	// Cross-sectional rank of an indicator across the symbols in the current data point
+	// (rank 1 is the highest value; percentile is 0-100)
+	CrossSectionalRank(symbol string, spec IndicatorSpec) (rank int, percentile float64, err error)
+
+	// Candlestick patterns completed by the latest bar
+	CandlestickPatterns(symbol string) ([]PatternSignal, error)
+
+	// History access, limited to the run's maximum lookback. Series are returned oldest first.
+	GetBars(symbol string, count int) ([]BarData, error)
+	Indicator(symbol string, spec IndicatorSpec) (float64, error)
+	IndicatorHistory(symbol string, spec IndicatorSpec, count int) ([]float64, error)
+
 	// Logging
 	Log(level string, message string, fields map[string]interface{})
 }
@@ -132,6 +221,22 @@ type Strategy interface {
 	GetParameters() map[string]interface{}
 }
 
+// CircuitBreakerEvent describes a portfolio risk limit that halted trading
+type CircuitBreakerEvent struct {
+	Timestamp time.Time
+	Rule      string  // Risk limit that was breached (e.g., "max_daily_loss")
+	Value     float64 // Observed value that breached the limit
+	Limit     float64 // Configured limit
+	Action    string  // "freeze" or "flatten"
+	Scope     string  // "session" or "run"
+}
+
+// CircuitBreakerHandler is implemented by strategies that want to be notified
+// when the engine halts trading because a portfolio risk limit was breached
+type CircuitBreakerHandler interface {
+	OnCircuitBreaker(ctx Context, event CircuitBreakerEvent) error
+}
+
 // StrategyConfig holds configuration for a strategy
 type StrategyConfig struct {
 	Name       string                 `yaml:"name"`